@@ -1,13 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"AutoParkWeb/internal/attachments"
 	"AutoParkWeb/internal/config"
+	"AutoParkWeb/internal/database/memory"
+	"AutoParkWeb/internal/database/migrate"
 	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/logging"
+	"AutoParkWeb/internal/metrics"
 	"AutoParkWeb/internal/services"
 	"AutoParkWeb/internal/transport"
+	"AutoParkWeb/migrations"
 )
 
 func main() {
@@ -16,18 +30,154 @@ func main() {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
-	db, err := database.NewPostgresDB(cfg)
+	// Сообщения стандартного пакета log тоже идут через этот логгер
+	logger := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	slog.SetDefault(logger)
+	for _, warning := range cfg.Warnings {
+		logger.Warn(warning)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = migrateCommand(cfg, logger, os.Args[2:])
+	} else {
+		err = serve(cfg, logger)
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func migrateCommand(cfg *config.Config, logger *slog.Logger, args []string) error {
+	pg, migrator, err := connectPostgres(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+
+	if err := runMigrateCommand(context.Background(), migrator, logger, args); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
+// Как долго выдается закешированное число автомобилей в рейсе
+const carsOnTripTTL = 30 * time.Second
+
+// Запуск HTTP-сервера до сигнала SIGINT или SIGTERM. При остановке сервер
+// перестает принимать соединения, дожидается завершения текущих запросов
+// и только после этого закрывается пул соединений с базой.
+func serve(cfg *config.Config, logger *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	registry, err := metrics.NewRegistry(logger)
+	if err != nil {
+		return fmt.Errorf("error registering metrics: %w", err)
+	}
+
+	var db database.DBHandler
+	switch cfg.Storage {
+	case config.StorageMemory:
+		demo, err := memory.NewDemo(ctx)
+		if err != nil {
+			return fmt.Errorf("error creating demo storage: %w", err)
+		}
+		logger.Info("Demo mode: in-memory storage", "login", memory.DemoAdminUsername, "password", memory.DemoAdminPassword)
+		db = demo
+	default:
+		pg, migrator, err := connectPostgres(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			pg.Close()
+			logger.Info("Database connection pool closed")
+		}()
+		if err := applyMigrations(ctx, migrator, logger); err != nil {
+			return fmt.Errorf("error applying migrations: %w", err)
+		}
+		if err := metrics.RegisterPoolStats(registry, pg.Pool); err != nil {
+			return fmt.Errorf("error registering metrics: %w", err)
+		}
+		db = pg
+	}
+
+	service := services.NewAutoParkService(db, attachments.NewStorage(cfg.UploadDir), logger)
+	service.SetTimetableHorizon(cfg.TimetableHorizonDays)
+	business, err := metrics.NewBusiness(registry)
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		return fmt.Errorf("error registering metrics: %w", err)
 	}
-	defer db.Close()
+	service.SetEventRecorder(business)
+	// Запрос к базе выполняется не чаще раза в carsOnTripTTL, сколько бы раз ни опрашивали /metrics
+	if err := metrics.RegisterCachedGauge(registry, logger, "autopark_cars_on_trip",
+		"Автомобили в рейсе: записи журнала без времени прибытия", carsOnTripTTL, service.CountCarsOnTrip); err != nil {
+		return fmt.Errorf("error registering metrics: %w", err)
+	}
+	router := transport.SetupRoutes(service, cfg, registry, logger)
 
-	service := services.NewAutoParkService(db)
-	router := transport.SetupRoutes(service)
+	// Планы рейсов по расписаниям маршрутов формируются в фоне каждый час
+	generatorDone := make(chan struct{})
+	go func() {
+		defer close(generatorDone)
+		service.RunTimetableGenerator(ctx, time.Hour)
+	}()
 
-	log.Println("Server started on 127.0.0.1:8080")
-	err = http.ListenAndServe(":8080", router)
+	server := &http.Server{
+		Addr:         cfg.ListenAddr,
+		Handler:      router,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		if cfg.TLSEnabled() {
+			logger.Info("Server started", "addr", "https://"+cfg.ListenAddr)
+			serverErr <- server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			logger.Info("Server started", "addr", "http://"+cfg.ListenAddr)
+			serverErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		stop()
+		<-generatorDone
+		return fmt.Errorf("error starting server: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down, waiting for active requests", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	err = server.Shutdown(shutdownCtx)
+	<-generatorDone
 	if err != nil {
-		log.Fatalf("Error starting server: %v", err)
+		return fmt.Errorf("error shutting down server: %w", err)
+	}
+	if err := <-serverErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server error: %w", err)
 	}
+	logger.Info("Server stopped")
+	return nil
+}
+
+// Подключение к PostgreSQL и загрузка встроенных миграций
+func connectPostgres(cfg *config.Config, logger *slog.Logger) (*database.PostgresDB, *migrate.Migrator, error) {
+	db, err := database.NewPostgresDB(cfg, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to database: %w", err)
+	}
+
+	migrator, err := migrate.New(db.Pool, migrations.FS)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("error loading migrations: %w", err)
+	}
+
+	return db, migrator, nil
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"AutoParkWeb/internal/database/migrate"
+)
+
+// Подкоманда migrate up|down|status
+func runMigrateCommand(ctx context.Context, migrator *migrate.Migrator, logger *slog.Logger, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: app migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			logger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			logger.Info("Database schema is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			logger.Info("No migrations to revert")
+			return nil
+		}
+		logger.Info("Reverted migration", "version", reverted.Version, "name", reverted.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+	return nil
+}
+
+// Применение ожидающих миграций при старте сервера
+func applyMigrations(ctx context.Context, migrator *migrate.Migrator, logger *slog.Logger) error {
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		logger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+// Пакет attachments хранит загруженные файлы (сканы документов) в каталоге на диске.
+// Файлы сохраняются под случайными именами, исходные имена хранятся в базе.
+package attachments
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidName = errors.New("invalid attachment name")
+
+type Storage struct {
+	dir string
+}
+
+func NewStorage(dir string) *Storage {
+	return &Storage{dir: dir}
+}
+
+// Сохранение файла; возвращает имя, под которым он записан в каталог
+func (s *Storage) Save(data []byte, ext string) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate attachment name: %w", err)
+	}
+	name := hex.EncodeToString(random) + strings.ToLower(ext)
+
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o640); err != nil {
+		return "", fmt.Errorf("failed to write attachment: %w", err)
+	}
+	return name, nil
+}
+
+func (s *Storage) Open(name string) (*os.File, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Удаление файла; отсутствующий файл ошибкой не считается
+func (s *Storage) Remove(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove attachment: %w", err)
+	}
+	return nil
+}
+
+// Имя файла не должно выводить за пределы каталога вложений
+func (s *Storage) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", ErrInvalidName
+	}
+	return filepath.Join(s.dir, name), nil
+}
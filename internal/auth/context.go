@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+
+	"AutoParkWeb/internal/models"
+)
+
+type contextKey struct{}
+
+// Сохранение аутентифицированного пользователя в контексте запроса
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// Получение аутентифицированного пользователя из контекста запроса
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*models.User)
+	return user, ok && user != nil
+}
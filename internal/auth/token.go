@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// Префиксы позволяют отличить токен автопарка в логах и конфигурации
+const (
+	TokenPrefix        = "apt_"
+	SessionTokenPrefix = "aps_"
+)
+
+// Генерация нового токена доступа и его хэша для хранения в базе
+func GenerateToken() (token, hash string, err error) {
+	return generateToken(TokenPrefix)
+}
+
+// Генерация токена серверной сессии веб-интерфейса
+func GenerateSessionToken() (token, hash string, err error) {
+	return generateToken(SessionTokenPrefix)
+}
+
+func generateToken(prefix string) (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = prefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// Токены случайны и длинны, поэтому для них достаточно SHA-256 вместо bcrypt
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,46 +1,276 @@
 package config
 
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
-	"log"
+	"net"
+	"net/url"
 	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Режимы развертывания
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Хранилища сессий веб-интерфейса
+const (
+	SessionStoreCookie = "cookie"
+	SessionStoreServer = "server"
+)
+
+// Минимальная длина ключа подписи cookie сессий
+const minSessionKeyLength = 32
+
 type Config struct {
+	// Хранилище данных: postgres (по умолчанию) или memory для демо-режима
+	Storage string
+
 	PostgresHost     string
 	PostgresPort     string
 	PostgresUser     string
 	PostgresPassword string
 	PostgresDB       string
+	// Режим SSL подключения к PostgreSQL, по умолчанию disable
+	PostgresSSLMode string
+	// Размер пула соединений pgxpool; 0 — значение pgx по умолчанию
+	PostgresMaxConns int
+	PostgresMinConns int
+
+	// Адрес HTTP-сервера, по умолчанию :8080
+	ListenAddr string
+	// Сертификат и ключ TLS; если заданы, сервер принимает только HTTPS
+	TLSCertFile string
+	TLSKeyFile  string
+	// Таймауты HTTP-сервера
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// Сколько ждать завершения текущих запросов при остановке сервера
+	ShutdownTimeout time.Duration
+
+	// Шрифт TrueType с кириллицей для выгрузки журнала в PDF.
+	// Если не задан, ищется в стандартных системных каталогах.
+	PDFFontPath string
+
+	// Каталог для загруженных сканов документов, по умолчанию ./uploads
+	UploadDir string
+
+	// На сколько дней вперед формируются планы рейсов по расписаниям, по умолчанию 7
+	TimetableHorizonDays int
+
+	// Режим развертывания: development (по умолчанию) или production.
+	// В production cookie сессий передаются только по HTTPS.
+	Env string
+
+	// Ключи подписи cookie сессий из SESSION_KEYS через запятую. Новые cookie
+	// подписываются первым ключом, остальные принимаются на время ротации.
+	SessionKeys [][]byte
+
+	// Хранилище сессий: cookie (по умолчанию) или server — сессии хранятся
+	// в базе данных, их можно просмотреть и отозвать
+	SessionStore string
+
+	// Уровень логирования: debug, info (по умолчанию), warn или error
+	LogLevel string
+	// Формат логов: text (по умолчанию) или json
+	LogFormat string
+
+	// Предупреждения о настройках: конфигурация читается до создания логгера,
+	// поэтому они пишутся в лог уже после его настройки
+	Warnings []string
 }
 
-func NewConfig() (*Config, error) {
-	envPath := filepath.Join(".env")
+// Режимы SSL, которые понимает libpq и pgx
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
 
-	if _, err := os.Stat(envPath); os.IsNotExist(err) {
-		log.Fatalf(".env file is missing in the project root")
+// Конфигурация из переменных окружения. Файл .env необязателен: если он есть,
+// его значения дополняют окружение, но не переопределяют уже заданные переменные.
+// Ошибки всех переменных возвращаются вместе.
+func NewConfig() (*Config, error) {
+	envPath := ".env"
+	if _, err := os.Stat(envPath); err == nil {
+		if err := godotenv.Load(envPath); err != nil {
+			return nil, fmt.Errorf("error loading .env file: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading .env file: %w", err)
 	}
 
-	err := godotenv.Load(envPath)
-	if err != nil {
-		return nil, fmt.Errorf("Error loading .env file: %v", err)
+	env := &envReader{}
+	cfg := &Config{
+		Storage:              env.oneOf("STORAGE", StoragePostgres, StoragePostgres, StorageMemory),
+		PostgresHost:         env.string("POSTGRES_HOST", ""),
+		PostgresPort:         env.string("POSTGRES_PORT", "5432"),
+		PostgresUser:         env.string("POSTGRES_USER", ""),
+		PostgresPassword:     env.string("POSTGRES_PASSWORD", ""),
+		PostgresDB:           env.string("POSTGRES_DB", ""),
+		PostgresSSLMode:      env.oneOf("POSTGRES_SSLMODE", "disable", postgresSSLModes...),
+		PostgresMaxConns:     env.int("POSTGRES_MAX_CONNS", 0, 0),
+		PostgresMinConns:     env.int("POSTGRES_MIN_CONNS", 0, 0),
+		ListenAddr:           env.string("LISTEN_ADDR", ":8080"),
+		TLSCertFile:          env.string("TLS_CERT_FILE", ""),
+		TLSKeyFile:           env.string("TLS_KEY_FILE", ""),
+		ReadTimeout:          env.duration("HTTP_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:         env.duration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:          env.duration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		ShutdownTimeout:      env.duration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		PDFFontPath:          env.string("PDF_FONT_PATH", ""),
+		UploadDir:            env.string("UPLOAD_DIR", "./uploads"),
+		TimetableHorizonDays: env.int("TIMETABLE_HORIZON_DAYS", 7, 0),
+		Env:                  env.oneOf("APP_ENV", EnvDevelopment, EnvDevelopment, EnvProduction),
+		SessionStore:         env.oneOf("SESSION_STORE", SessionStoreCookie, SessionStoreCookie, SessionStoreServer),
+		LogLevel:             env.oneOf("LOG_LEVEL", "info", "debug", "info", "warn", "error"),
+		LogFormat:            env.oneOf("LOG_FORMAT", "text", "text", "json"),
 	}
 
-	cfg := &Config{
-		PostgresUser:     os.Getenv("POSTGRES_USER"),
-		PostgresPassword: os.Getenv("POSTGRES_PASSWORD"),
-		PostgresDB:       os.Getenv("POSTGRES_DB"),
-		PostgresHost:     os.Getenv("POSTGRES_HOST"),
-		PostgresPort:     os.Getenv("POSTGRES_PORT"),
+	if cfg.Storage == StoragePostgres {
+		required := []struct{ name, value string }{
+			{"POSTGRES_HOST", cfg.PostgresHost}, {"POSTGRES_USER", cfg.PostgresUser}, {"POSTGRES_DB", cfg.PostgresDB},
+		}
+		for _, variable := range required {
+			if variable.value == "" {
+				env.fail("%s is required when STORAGE=%s", variable.name, StoragePostgres)
+			}
+		}
+	}
+	if cfg.PostgresMaxConns > 0 && cfg.PostgresMinConns > cfg.PostgresMaxConns {
+		env.fail("POSTGRES_MIN_CONNS must not exceed POSTGRES_MAX_CONNS")
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		env.fail("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	for _, path := range []string{cfg.TLSCertFile, cfg.TLSKeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			env.fail("TLS: %v", err)
+		}
+	}
+	if err := cfg.loadSessionKeys(os.Getenv("SESSION_KEYS")); err != nil {
+		env.errs = append(env.errs, err)
 	}
 
+	if err := errors.Join(env.errs...); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+// Чтение переменных окружения с накоплением ошибок
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) fail(format string, args ...interface{}) {
+	e.errs = append(e.errs, fmt.Errorf(format, args...))
+}
+
+func (e *envReader) string(name, def string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return def
+}
+
+func (e *envReader) oneOf(name, def string, allowed ...string) string {
+	value := e.string(name, def)
+	for _, candidate := range allowed {
+		if value == candidate {
+			return value
+		}
+	}
+	e.fail("unknown %s %q, expected one of: %s", name, value, strings.Join(allowed, ", "))
+	return value
+}
+
+func (e *envReader) int(name string, def, min int) int {
+	value := e.string(name, "")
+	if value == "" {
+		return def
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min {
+		e.fail("invalid %s %q, expected an integer not less than %d", name, value, min)
+		return def
+	}
+	return number
+}
+
+// Длительность в формате Go (30s, 2m) или целое число секунд
+func (e *envReader) duration(name string, def time.Duration) time.Duration {
+	value := e.string(name, "")
+	if value == "" {
+		return def
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		e.fail("invalid %s %q, expected a duration such as 30s or 2m", name, value)
+		return def
+	}
+	return duration
+}
+
+func (c *Config) loadSessionKeys(value string) error {
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if len(key) < minSessionKeyLength {
+			return fmt.Errorf("SESSION_KEYS: each key must be at least %d bytes long", minSessionKeyLength)
+		}
+		c.SessionKeys = append(c.SessionKeys, []byte(key))
+	}
+	if len(c.SessionKeys) > 0 {
+		return nil
+	}
+	if c.Production() {
+		return fmt.Errorf("SESSION_KEYS is required when APP_ENV=%s", EnvProduction)
+	}
+
+	// Для разработки ключ генерируется при запуске, сессии не переживают перезапуск
+	key := make([]byte, minSessionKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate session key: %w", err)
+	}
+	c.Warnings = append(c.Warnings, "SESSION_KEYS is not set, using a random session key; sessions will not survive a restart")
+	c.SessionKeys = [][]byte{key}
+	return nil
+}
+
+func (c *Config) Production() bool {
+	return c.Env == EnvProduction
+}
+
+// Строка подключения в виде URL, чтобы спецсимволы в пароле экранировались
 func (c *Config) GetPostgresConnectionString() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		c.PostgresHost, c.PostgresPort, c.PostgresUser, c.PostgresPassword, c.PostgresDB)
+	connURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.PostgresUser, c.PostgresPassword),
+		Host:     net.JoinHostPort(c.PostgresHost, c.PostgresPort),
+		Path:     "/" + c.PostgresDB,
+		RawQuery: url.Values{"sslmode": {c.PostgresSSLMode}}.Encode(),
+	}
+	return connURL.String()
 }
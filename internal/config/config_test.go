@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var configVariables = []string{
+	"STORAGE", "POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB",
+	"POSTGRES_SSLMODE", "POSTGRES_MAX_CONNS", "POSTGRES_MIN_CONNS", "LISTEN_ADDR", "TLS_CERT_FILE",
+	"TLS_KEY_FILE", "HTTP_READ_TIMEOUT", "HTTP_WRITE_TIMEOUT", "HTTP_IDLE_TIMEOUT", "SHUTDOWN_TIMEOUT",
+	"PDF_FONT_PATH", "UPLOAD_DIR", "TIMETABLE_HORIZON_DAYS", "APP_ENV", "SESSION_STORE", "SESSION_KEYS",
+	"LOG_LEVEL", "LOG_FORMAT",
+}
+
+// Окружение только из заданных переменных; пустое значение равносильно отсутствию
+func setEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+	for _, name := range configVariables {
+		t.Setenv(name, values[name])
+	}
+}
+
+func TestNewConfigDefaults(t *testing.T) {
+	setEnv(t, map[string]string{"STORAGE": StorageMemory, "HTTP_READ_TIMEOUT": "20"})
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if cfg.ListenAddr != ":8080" || cfg.PostgresSSLMode != "disable" || cfg.Env != EnvDevelopment ||
+		cfg.SessionStore != SessionStoreCookie || cfg.TimetableHorizonDays != 7 {
+		t.Errorf("defaults = %+v", cfg)
+	}
+	if cfg.ReadTimeout != 20*time.Second || cfg.WriteTimeout != time.Minute {
+		t.Errorf("timeouts = %v, %v, want 20s, 1m", cfg.ReadTimeout, cfg.WriteTimeout)
+	}
+	// Без SESSION_KEYS в разработке ключ генерируется с предупреждением
+	if len(cfg.SessionKeys) != 1 || len(cfg.SessionKeys[0]) != minSessionKeyLength || len(cfg.Warnings) != 1 {
+		t.Errorf("session keys = %d, warnings = %q, want a random key with a warning", len(cfg.SessionKeys), cfg.Warnings)
+	}
+}
+
+// Ошибки всех переменных возвращаются вместе
+func TestNewConfigValidation(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, []byte("cert"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	longKey := strings.Repeat("k", minSessionKeyLength)
+
+	for _, tc := range []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{
+			"postgres without connection settings",
+			map[string]string{"POSTGRES_USER": "app"},
+			[]string{"POSTGRES_HOST is required", "POSTGRES_DB is required"},
+		},
+		{
+			"invalid values",
+			map[string]string{
+				"STORAGE": "mysql", "POSTGRES_SSLMODE": "on", "TIMETABLE_HORIZON_DAYS": "-1",
+				"HTTP_WRITE_TIMEOUT": "soon", "LOG_LEVEL": "trace",
+			},
+			[]string{`unknown STORAGE "mysql"`, `unknown POSTGRES_SSLMODE "on"`, `invalid TIMETABLE_HORIZON_DAYS "-1"`,
+				`invalid HTTP_WRITE_TIMEOUT "soon"`, `unknown LOG_LEVEL "trace"`},
+		},
+		{
+			"pool size",
+			map[string]string{"STORAGE": StorageMemory, "POSTGRES_MAX_CONNS": "4", "POSTGRES_MIN_CONNS": "8"},
+			[]string{"POSTGRES_MIN_CONNS must not exceed POSTGRES_MAX_CONNS"},
+		},
+		{
+			"tls certificate without key",
+			map[string]string{"STORAGE": StorageMemory, "TLS_CERT_FILE": certFile},
+			[]string{"TLS_CERT_FILE and TLS_KEY_FILE must be set together"},
+		},
+		{
+			"missing tls key file",
+			map[string]string{"STORAGE": StorageMemory, "TLS_CERT_FILE": certFile, "TLS_KEY_FILE": certFile + ".missing"},
+			[]string{"TLS:", "cert.pem.missing"},
+		},
+		{
+			"short session key",
+			map[string]string{"STORAGE": StorageMemory, "SESSION_KEYS": longKey + ",short"},
+			[]string{"each key must be at least 32 bytes"},
+		},
+		{
+			"production without session keys",
+			map[string]string{"STORAGE": StorageMemory, "APP_ENV": EnvProduction},
+			[]string{"SESSION_KEYS is required when APP_ENV=production"},
+		},
+	} {
+		setEnv(t, tc.env)
+		cfg, err := NewConfig()
+		if err == nil {
+			t.Errorf("%s: NewConfig = %+v, want error", tc.name, cfg)
+			continue
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q does not mention %q", tc.name, err, want)
+			}
+		}
+	}
+}
+
+func TestSessionKeysRotation(t *testing.T) {
+	current, previous := strings.Repeat("a", minSessionKeyLength), strings.Repeat("b", minSessionKeyLength)
+	setEnv(t, map[string]string{"STORAGE": StorageMemory, "APP_ENV": EnvProduction, "SESSION_KEYS": current + " , " + previous + ","})
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if len(cfg.SessionKeys) != 2 || string(cfg.SessionKeys[0]) != current || string(cfg.SessionKeys[1]) != previous {
+		t.Errorf("session keys = %q, want current key first", cfg.SessionKeys)
+	}
+	if len(cfg.Warnings) != 0 {
+		t.Errorf("warnings = %q, want none", cfg.Warnings)
+	}
+}
@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Текущее время с точностью TIMESTAMP: восстановление водителя сравнивает
+// время архивирования, чтобы найти автомобили, архивированные вместе с ним
+func nowTimestamp() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func archiveTime() *time.Time {
+	now := nowTimestamp()
+	return &now
+}
+
+func (db *MemoryDB) hasTripInProgress(match func(row journalRow) bool) bool {
+	for _, row := range db.journal {
+		if row.TimeIn == nil && match(row) {
+			return true
+		}
+	}
+	return false
+}
+
+// Как и PostgresDB.ArchiveDriver: водитель уходит в архив вместе со своими автомобилями
+func (db *MemoryDB) ArchiveDriver(ctx context.Context, driverID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	driver, ok := db.drivers[driverID]
+	if !ok || driver.ArchivedAt != nil {
+		return fmt.Errorf("driver %w", database.ErrNotFound)
+	}
+	if db.hasTripInProgress(func(row journalRow) bool {
+		return row.DriverID == driverID || db.autos[row.AutoID].PersonalID == driverID
+	}) {
+		return conflict("водитель или его автомобиль находится в рейсе")
+	}
+
+	archivedAt := archiveTime()
+	before := driverSnapshot(driver)
+	driver.ArchivedAt = archivedAt
+	db.drivers[driverID] = driver
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityDriver, driverID, before, driverSnapshot(driver))
+
+	for _, id := range db.sortedAutoIDs() {
+		auto := db.autos[id]
+		if auto.PersonalID != driverID || auto.ArchivedAt != nil {
+			continue
+		}
+		before := autoSnapshot(auto)
+		auto.ArchivedAt = archivedAt
+		db.autos[id] = auto
+		db.audit(ctx, models.AuditActionUpdate, models.AuditEntityAuto, id, before, autoSnapshot(auto))
+	}
+	return nil
+}
+
+func (db *MemoryDB) RestoreDriver(ctx context.Context, driverID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	driver, ok := db.drivers[driverID]
+	if !ok || driver.ArchivedAt == nil {
+		return fmt.Errorf("archived driver %w", database.ErrNotFound)
+	}
+
+	archivedAt := *driver.ArchivedAt
+	before := driverSnapshot(driver)
+	driver.ArchivedAt = nil
+	db.drivers[driverID] = driver
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityDriver, driverID, before, driverSnapshot(driver))
+
+	for _, id := range db.sortedAutoIDs() {
+		auto := db.autos[id]
+		if auto.PersonalID != driverID || auto.ArchivedAt == nil || !auto.ArchivedAt.Equal(archivedAt) {
+			continue
+		}
+		before := autoSnapshot(auto)
+		auto.ArchivedAt = nil
+		db.autos[id] = auto
+		db.audit(ctx, models.AuditActionUpdate, models.AuditEntityAuto, id, before, autoSnapshot(auto))
+	}
+	return nil
+}
+
+func (db *MemoryDB) ArchiveCar(ctx context.Context, carID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	auto, ok := db.autos[carID]
+	if !ok || auto.ArchivedAt != nil {
+		return fmt.Errorf("car %w", database.ErrNotFound)
+	}
+	if db.hasTripInProgress(func(row journalRow) bool { return row.AutoID == carID }) {
+		return conflict("автомобиль находится в рейсе")
+	}
+
+	before := autoSnapshot(auto)
+	auto.ArchivedAt = archiveTime()
+	db.autos[carID] = auto
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityAuto, carID, before, autoSnapshot(auto))
+	return nil
+}
+
+func (db *MemoryDB) RestoreCar(ctx context.Context, carID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	auto, ok := db.autos[carID]
+	if !ok || auto.ArchivedAt == nil {
+		return fmt.Errorf("archived car %w", database.ErrNotFound)
+	}
+	if err := db.checkAuto(carID, auto.Num, auto.PersonalID, nil); err != nil {
+		return fmt.Errorf("не удалось восстановить автомобиль с ID %d: %w", carID, err)
+	}
+
+	before := autoSnapshot(auto)
+	auto.ArchivedAt = nil
+	db.autos[carID] = auto
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityAuto, carID, before, autoSnapshot(auto))
+	return nil
+}
+
+func (db *MemoryDB) ArchiveRoute(ctx context.Context, routeID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	route, ok := db.routes[routeID]
+	if !ok || route.ArchivedAt != nil {
+		return fmt.Errorf("route %w", database.ErrNotFound)
+	}
+	if db.hasTripInProgress(func(row journalRow) bool { return row.RouteID == routeID }) {
+		return conflict("маршрут находится в рейсе")
+	}
+
+	before := routeSnapshot(route)
+	route.ArchivedAt = archiveTime()
+	db.routes[routeID] = route
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityRoute, routeID, before, routeSnapshot(route))
+	return nil
+}
+
+func (db *MemoryDB) RestoreRoute(ctx context.Context, routeID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	route, ok := db.routes[routeID]
+	if !ok || route.ArchivedAt == nil {
+		return fmt.Errorf("archived route %w", database.ErrNotFound)
+	}
+
+	before := routeSnapshot(route)
+	route.ArchivedAt = nil
+	db.routes[routeID] = route
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityRoute, routeID, before, routeSnapshot(route))
+	return nil
+}
+
+func (db *MemoryDB) GetArchive(ctx context.Context) (*models.Archive, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	archive := &models.Archive{
+		Drivers: []models.AutoPersonal{},
+		Autos:   []models.Auto{},
+		Routes:  []models.Route{},
+	}
+	for _, driver := range db.drivers {
+		if driver.ArchivedAt != nil {
+			archive.Drivers = append(archive.Drivers, driver)
+		}
+	}
+	for _, auto := range db.autos {
+		if auto.ArchivedAt != nil {
+			auto.DriverFullName = db.driverFullName(auto.PersonalID)
+			archive.Autos = append(archive.Autos, auto)
+		}
+	}
+	for _, route := range db.routes {
+		if route.ArchivedAt != nil {
+			archive.Routes = append(archive.Routes, route)
+		}
+	}
+
+	sort.Slice(archive.Drivers, func(i, j int) bool {
+		return archivedBefore(archive.Drivers[j].ArchivedAt, archive.Drivers[j].ID, archive.Drivers[i].ArchivedAt, archive.Drivers[i].ID)
+	})
+	sort.Slice(archive.Autos, func(i, j int) bool {
+		return archivedBefore(archive.Autos[j].ArchivedAt, archive.Autos[j].ID, archive.Autos[i].ArchivedAt, archive.Autos[i].ID)
+	})
+	sort.Slice(archive.Routes, func(i, j int) bool {
+		return archivedBefore(archive.Routes[j].ArchivedAt, archive.Routes[j].ID, archive.Routes[i].ArchivedAt, archive.Routes[i].ID)
+	})
+	return archive, nil
+}
+
+// Порядок ORDER BY archived_at, id для сортировки архива
+func archivedBefore(a *time.Time, aID int, b *time.Time, bID int) bool {
+	if !a.Equal(*b) {
+		return a.Before(*b)
+	}
+	return aID < bID
+}
+
+func (db *MemoryDB) sortedAutoIDs() []int {
+	ids := make([]int, 0, len(db.autos))
+	for id := range db.autos {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Аналог CURRENT_DATE
+func currentDate() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Методы для работы с закреплением водителей за автомобилями
+func (db *MemoryDB) GetAssignments(ctx context.Context, filter models.AssignmentFilter) ([]models.Assignment, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	assignments := []models.Assignment{}
+	for _, assignment := range db.assignments {
+		if filter.AutoID > 0 && assignment.AutoID != filter.AutoID {
+			continue
+		}
+		if filter.DriverID > 0 && assignment.DriverID != filter.DriverID {
+			continue
+		}
+		if filter.ActiveOn != nil && !assignment.ActiveOn(*filter.ActiveOn) {
+			continue
+		}
+		assignments = append(assignments, db.assignmentView(assignment))
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		if assignments[i].AutoNum != assignments[j].AutoNum {
+			return assignments[i].AutoNum < assignments[j].AutoNum
+		}
+		if !assignments[i].ValidFrom.Equal(assignments[j].ValidFrom) {
+			return assignments[i].ValidFrom.After(assignments[j].ValidFrom)
+		}
+		return assignments[i].ID > assignments[j].ID
+	})
+	return assignments, nil
+}
+
+func (db *MemoryDB) GetAssignmentByID(ctx context.Context, assignmentID int) (*models.Assignment, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	assignment, ok := db.assignments[assignmentID]
+	if !ok {
+		return nil, fmt.Errorf("assignment %w", database.ErrNotFound)
+	}
+	assignment = db.assignmentView(assignment)
+	return &assignment, nil
+}
+
+func (db *MemoryDB) AddAssignment(ctx context.Context, assignment models.Assignment) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.checkAssignment(assignment, true); err != nil {
+		return 0, fmt.Errorf("failed to add assignment: %w", err)
+	}
+	return db.insertAssignment(ctx, assignment), nil
+}
+
+func (db *MemoryDB) UpdateAssignmentPeriod(ctx context.Context, assignmentID int, validFrom time.Time, validTo *time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	before, ok := db.assignments[assignmentID]
+	if !ok {
+		return fmt.Errorf("assignment %w", database.ErrNotFound)
+	}
+	assignment := before
+	assignment.ValidFrom = validFrom
+	assignment.ValidTo = copyDate(validTo)
+	if err := db.checkAssignment(assignment, false); err != nil {
+		return fmt.Errorf("failed to update assignment: %w", err)
+	}
+
+	db.assignments[assignmentID] = assignment
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityAssignment, assignmentID,
+		assignmentSnapshot(before), assignmentSnapshot(assignment))
+	return nil
+}
+
+func (db *MemoryDB) DeleteAssignment(ctx context.Context, assignmentID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	assignment, ok := db.assignments[assignmentID]
+	if !ok {
+		return fmt.Errorf("assignment %w", database.ErrNotFound)
+	}
+	delete(db.assignments, assignmentID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityAssignment, assignmentID, assignmentSnapshot(assignment), nil)
+	return nil
+}
+
+// Ограничения таблицы auto_assignments: внешние ключи, auto_assignments_period
+// и триггер PREVENT_ASSIGNMENT_OVERLAP
+func (db *MemoryDB) checkAssignment(assignment models.Assignment, insert bool) error {
+	auto, ok := db.autos[assignment.AutoID]
+	if !ok {
+		return conflict("автомобиль с ID %d не существует", assignment.AutoID)
+	}
+	driver, ok := db.drivers[assignment.DriverID]
+	if !ok {
+		return conflict("водитель с ID %d не существует", assignment.DriverID)
+	}
+	if assignment.ValidTo != nil && assignment.ValidTo.Before(assignment.ValidFrom) {
+		return conflict("new row for relation \"auto_assignments\" violates check constraint \"auto_assignments_period\"")
+	}
+	for id, other := range db.assignments {
+		if id == assignment.ID || other.AutoID != assignment.AutoID || other.DriverID != assignment.DriverID {
+			continue
+		}
+		if periodsOverlap(other, assignment) {
+			return conflict("Водитель с ID %d уже закреплен за автомобилем %d в этот период", assignment.DriverID, assignment.AutoID)
+		}
+	}
+	if insert && driver.ArchivedAt != nil {
+		return conflict("Водитель с ID %d находится в архиве", assignment.DriverID)
+	}
+	if insert && auto.ArchivedAt != nil {
+		return conflict("Автомобиль %d находится в архиве", assignment.AutoID)
+	}
+	return nil
+}
+
+func periodsOverlap(a, b models.Assignment) bool {
+	return (a.ValidTo == nil || !b.ValidFrom.After(*a.ValidTo)) && (b.ValidTo == nil || !a.ValidFrom.After(*b.ValidTo))
+}
+
+func (db *MemoryDB) insertAssignment(ctx context.Context, assignment models.Assignment) int {
+	id := db.newID("auto_assignments")
+	db.assignments[id] = models.Assignment{ID: id, AutoID: assignment.AutoID, DriverID: assignment.DriverID,
+		ValidFrom: assignment.ValidFrom, ValidTo: copyDate(assignment.ValidTo), CreatedAt: nowTimestamp()}
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityAssignment, id, nil, assignmentSnapshot(db.assignments[id]))
+	return id
+}
+
+// Аналог триггера ASSIGN_AUTO_PRIMARY_DRIVER: основной водитель действующего
+// автомобиля закрепляется за ним с текущей даты, если еще не закреплен
+func (db *MemoryDB) assignPrimaryDriver(ctx context.Context, auto models.Auto) {
+	if auto.ArchivedAt != nil {
+		return
+	}
+	today := currentDate()
+	for _, assignment := range db.assignments {
+		if assignment.AutoID == auto.ID && assignment.DriverID == auto.PersonalID &&
+			(assignment.ValidTo == nil || !assignment.ValidTo.Before(today)) {
+			return
+		}
+	}
+	db.insertAssignment(ctx, models.Assignment{AutoID: auto.ID, DriverID: auto.PersonalID, ValidFrom: today})
+}
+
+// Как и ASSIGN_PRIMARY_DRIVER при смене основного водителя: действующее
+// закрепление прежнего водителя завершается текущей датой
+func (db *MemoryDB) endPrimaryAssignment(ctx context.Context, autoID, driverID int) {
+	today := currentDate()
+	for id, assignment := range db.assignments {
+		if assignment.AutoID != autoID || assignment.DriverID != driverID || assignment.ValidFrom.After(today) ||
+			(assignment.ValidTo != nil && !assignment.ValidTo.After(today)) {
+			continue
+		}
+		before := assignment
+		assignment.ValidTo = &today
+		db.assignments[id] = assignment
+		db.audit(ctx, models.AuditActionUpdate, models.AuditEntityAssignment, id,
+			assignmentSnapshot(before), assignmentSnapshot(assignment))
+	}
+}
+
+// Водитель закреплен за автомобилем на дату at
+func (db *MemoryDB) isAssigned(autoID, driverID int, at time.Time) bool {
+	for _, assignment := range db.assignments {
+		if assignment.AutoID == autoID && assignment.DriverID == driverID && assignment.ActiveOn(at) {
+			return true
+		}
+	}
+	return false
+}
+
+func (db *MemoryDB) assignmentView(assignment models.Assignment) models.Assignment {
+	assignment.AutoNum = db.autos[assignment.AutoID].Num
+	assignment.DriverName = db.driverFullName(assignment.DriverID)
+	assignment.ValidTo = copyDate(assignment.ValidTo)
+	return assignment
+}
+
+func copyDate(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := *t
+	return &value
+}
@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/models"
+)
+
+// Формат времени, в котором to_jsonb выводит TIMESTAMP WITHOUT TIME ZONE
+const snapshotTimeFormat = "2006-01-02T15:04:05"
+
+// Аналог триггера AUDIT_CHANGES: снимки строк до и после изменения
+// и пользователь из контекста запроса. Вызывается под db.mu.
+func (db *MemoryDB) audit(ctx context.Context, action, entityType string, entityID int, before, after map[string]interface{}) {
+	entry := models.AuditEntry{
+		ID:         int64(db.newID("audit_log")),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		CreatedAt:  time.Now(),
+	}
+	if user, ok := auth.UserFromContext(ctx); ok {
+		userID := user.ID
+		entry.UserID = &userID
+		entry.Username = user.Username
+	}
+	if before != nil {
+		entry.Before, _ = json.Marshal(before)
+	}
+	if after != nil {
+		entry.After, _ = json.Marshal(after)
+	}
+	db.auditLog = append(db.auditLog, entry)
+}
+
+// Снимки строк с теми же ключами, что и столбцы таблиц в PostgreSQL
+func driverSnapshot(driver models.AutoPersonal) map[string]interface{} {
+	return map[string]interface{}{
+		"id": driver.ID, "first_name": driver.FirstName, "last_name": driver.LastName, "father_name": driver.FatherName,
+		"archived_at": snapshotTime(driver.ArchivedAt), "phone": snapshotString(driver.Phone),
+		"license_number": snapshotString(driver.LicenseNumber), "license_categories": driver.LicenseCategories,
+		"license_issued_at": snapshotDate(driver.LicenseIssuedAt), "license_expires_at": snapshotDate(driver.LicenseExpiresAt),
+		"medical_expires_at": snapshotDate(driver.MedicalExpiresAt),
+	}
+}
+
+func autoSnapshot(auto models.Auto) map[string]interface{} {
+	return map[string]interface{}{
+		"id": auto.ID, "num": auto.Num, "color": auto.Color, "mark": auto.Mark, "category": auto.Category,
+		"personal_id": auto.PersonalID,
+		"archived_at": snapshotTime(auto.ArchivedAt),
+	}
+}
+
+func routeSnapshot(route models.Route) map[string]interface{} {
+	return map[string]interface{}{
+		"id": route.ID, "start_point": route.StartPoint, "end_point": route.EndPoint,
+		"distance_km": route.DistanceKm, "planned_minutes": route.PlannedMinutes,
+		"start_lat": geoLat(route.StartCoords), "start_lon": geoLon(route.StartCoords),
+		"end_lat": geoLat(route.EndCoords), "end_lon": geoLon(route.EndCoords),
+		"archived_at": snapshotTime(route.ArchivedAt),
+	}
+}
+
+func geoLat(point *models.GeoPoint) *float64 {
+	if point == nil {
+		return nil
+	}
+	return &point.Lat
+}
+
+func geoLon(point *models.GeoPoint) *float64 {
+	if point == nil {
+		return nil
+	}
+	return &point.Lon
+}
+
+func journalSnapshot(row journalRow) map[string]interface{} {
+	return map[string]interface{}{
+		"id": row.ID, "time_out": row.TimeOut.Format(snapshotTimeFormat), "time_in": snapshotTime(row.TimeIn),
+		"route_id": row.RouteID, "auto_id": row.AutoID, "personal_id": row.DriverID,
+		"odometer_out": row.OdometerOut, "odometer_in": row.OdometerIn,
+		"fuel_out": row.FuelOut, "fuel_in": row.FuelIn, "fuel_added": row.FuelAdded,
+	}
+}
+
+func assignmentSnapshot(assignment models.Assignment) map[string]interface{} {
+	return map[string]interface{}{
+		"id": assignment.ID, "auto_id": assignment.AutoID, "personal_id": assignment.DriverID,
+		"valid_from": assignment.ValidFrom.Format("2006-01-02"), "valid_to": snapshotDate(assignment.ValidTo),
+		"created_at": assignment.CreatedAt.Format(snapshotTimeFormat),
+	}
+}
+
+func routeStopSnapshot(stopID, routeID int, stop models.RouteStop) map[string]interface{} {
+	return map[string]interface{}{
+		"id": stopID, "route_id": routeID, "position": stop.Position, "name": stop.Name,
+		"lat": geoLat(stop.Coords), "lon": geoLon(stop.Coords),
+	}
+}
+
+func maintenanceSnapshot(record models.MaintenanceRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"id": record.ID, "auto_id": record.AutoID, "type": record.Type,
+		"performed_at": record.PerformedAt.Format("2006-01-02"), "odometer": record.Odometer,
+		"cost": record.Cost, "notes": record.Notes,
+	}
+}
+
+func scheduleSnapshot(schedule models.MaintenanceSchedule) map[string]interface{} {
+	return map[string]interface{}{
+		"id": schedule.ID, "auto_id": schedule.AutoID, "type": schedule.Type,
+		"interval_days": schedule.IntervalDays, "interval_km": schedule.IntervalKm, "mandatory": schedule.Mandatory,
+		"start_odometer": schedule.StartOdometer, "created_at": schedule.CreatedAt.Format(snapshotTimeFormat),
+	}
+}
+
+func autoDocumentSnapshot(document models.AutoDocument) map[string]interface{} {
+	return map[string]interface{}{
+		"id": document.ID, "auto_id": document.AutoID, "type": document.Type, "number": document.Number,
+		"issued_at": snapshotDate(document.IssuedAt), "expires_at": snapshotDate(document.ExpiresAt),
+		"notes": document.Notes, "created_at": document.CreatedAt.Format(snapshotTimeFormat),
+	}
+}
+
+func documentFileSnapshot(file models.AutoDocumentFile) map[string]interface{} {
+	return map[string]interface{}{
+		"id": file.ID, "document_id": file.DocumentID, "file_name": file.FileName, "content_type": file.ContentType,
+		"size": file.Size, "stored_name": file.StoredName, "uploaded_at": file.UploadedAt.Format(snapshotTimeFormat),
+	}
+}
+
+func tripPlanSnapshot(plan models.TripPlan) map[string]interface{} {
+	return map[string]interface{}{
+		"id": plan.ID, "auto_id": plan.AutoID, "personal_id": plan.DriverID, "route_id": plan.RouteID,
+		"planned_out": plan.PlannedOut.Format(snapshotTimeFormat), "planned_in": snapshotTime(plan.PlannedIn),
+		"notes": plan.Notes, "journal_id": plan.JournalID, "timetable_id": plan.TimetableID,
+		"created_at": plan.CreatedAt.Format(snapshotTimeFormat),
+	}
+}
+
+func timetableSnapshot(timetable models.Timetable) map[string]interface{} {
+	return map[string]interface{}{
+		"id": timetable.ID, "route_id": timetable.RouteID, "auto_id": timetable.AutoID, "personal_id": timetable.DriverID,
+		"departure_time": timetable.DepartureTime + ":00", "duration_minutes": timetable.DurationMinutes,
+		"weekdays": timetable.Weekdays, "valid_from": timetable.ValidFrom.Format("2006-01-02"),
+		"valid_to": snapshotDate(timetable.ValidTo), "generated_until": snapshotDate(timetable.GeneratedUntil),
+		"created_at": timetable.CreatedAt.Format(snapshotTimeFormat),
+	}
+}
+
+func timetableExceptionSnapshot(exception models.TimetableException) map[string]interface{} {
+	return map[string]interface{}{
+		"id": exception.ID, "timetable_id": exception.TimetableID,
+		"exception_date": exception.Date.Format("2006-01-02"), "reason": exception.Reason,
+	}
+}
+
+// Необязательное время в снимке: NULL в базе превращается в null в JSON
+func snapshotTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format(snapshotTimeFormat)
+}
+
+func snapshotDate(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+// Пустая строка хранится в базе как NULL
+func snapshotString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// Журнал аудита изменений
+func (db *MemoryDB) GetAuditLog(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	entries := make([]models.AuditEntry, 0)
+	for _, entry := range db.auditLog {
+		if matchesAuditFilter(entry, filter) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+
+	page := &models.AuditPage{Total: len(entries), Page: filter.Page, PageSize: filter.PageSize}
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		if offset > len(entries) {
+			offset = len(entries)
+		}
+		end := offset + filter.PageSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		entries = entries[offset:end]
+	}
+	page.Entries = entries
+	return page, nil
+}
+
+func matchesAuditFilter(entry models.AuditEntry, filter models.AuditFilter) bool {
+	if filter.From != nil && entry.CreatedAt.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !entry.CreatedAt.Before(*filter.To) {
+		return false
+	}
+	if filter.UserID > 0 && (entry.UserID == nil || *entry.UserID != filter.UserID) {
+		return false
+	}
+	if filter.Action != "" && entry.Action != filter.Action {
+		return false
+	}
+	if filter.EntityType != "" && entry.EntityType != filter.EntityType {
+		return false
+	}
+	if filter.EntityID > 0 && entry.EntityID != filter.EntityID {
+		return false
+	}
+	return true
+}
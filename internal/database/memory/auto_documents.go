@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Методы для работы с документами автомобилей
+func (db *MemoryDB) GetAutoDocuments(ctx context.Context, autoID int) ([]models.AutoDocument, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	documents := []models.AutoDocument{}
+	for _, document := range db.autoDocuments {
+		if autoID == 0 || document.AutoID == autoID {
+			documents = append(documents, db.autoDocument(document))
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		a, b := documents[i], documents[j]
+		switch {
+		case a.AutoNum != b.AutoNum:
+			return a.AutoNum < b.AutoNum
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		case (a.ExpiresAt == nil) != (b.ExpiresAt == nil):
+			return a.ExpiresAt == nil
+		case a.ExpiresAt != nil && !a.ExpiresAt.Equal(*b.ExpiresAt):
+			return a.ExpiresAt.After(*b.ExpiresAt)
+		default:
+			return a.ID > b.ID
+		}
+	})
+	return documents, nil
+}
+
+func (db *MemoryDB) GetAutoDocumentByID(ctx context.Context, documentID int) (*models.AutoDocument, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	document, ok := db.autoDocuments[documentID]
+	if !ok {
+		return nil, fmt.Errorf("auto document %w", database.ErrNotFound)
+	}
+	document = db.autoDocument(document)
+	return &document, nil
+}
+
+// Документ с номером автомобиля и сканами, как в выборке с JOIN
+func (db *MemoryDB) autoDocument(document models.AutoDocument) models.AutoDocument {
+	document.AutoNum = db.autos[document.AutoID].Num
+	document.Files = []models.AutoDocumentFile{}
+	for _, file := range db.documentFiles {
+		if file.DocumentID == document.ID {
+			document.Files = append(document.Files, file)
+		}
+	}
+	sort.Slice(document.Files, func(i, j int) bool { return document.Files[i].ID < document.Files[j].ID })
+	return document
+}
+
+// Ограничения таблицы auto_documents: внешний ключ на автомобиль,
+// допустимый вид документа и дата выдачи раньше даты окончания
+func (db *MemoryDB) AddAutoDocument(ctx context.Context, document models.AutoDocument) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.autos[document.AutoID]; !ok {
+		return 0, fmt.Errorf("failed to add auto document: %w", conflict("автомобиль с ID %d не существует", document.AutoID))
+	}
+	switch document.Type {
+	case models.AutoDocumentInsurance, models.AutoDocumentRegistration, models.AutoDocumentInspection:
+	default:
+		return 0, fmt.Errorf("failed to add auto document: %w", conflict("недопустимый вид документа: %s", document.Type))
+	}
+	if document.IssuedAt != nil && document.ExpiresAt != nil && !document.IssuedAt.Before(*document.ExpiresAt) {
+		return 0, fmt.Errorf("failed to add auto document: %w", conflict("дата выдачи документа должна быть раньше даты окончания действия"))
+	}
+
+	document.ID = db.newID("auto_documents")
+	document.AutoNum = ""
+	document.Files = nil
+	document.CreatedAt = nowTimestamp()
+	db.autoDocuments[document.ID] = document
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityAutoDocument, document.ID, nil, autoDocumentSnapshot(document))
+	return document.ID, nil
+}
+
+// Аналог ON DELETE CASCADE для сканов документа
+func (db *MemoryDB) DeleteAutoDocument(ctx context.Context, documentID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	document, ok := db.autoDocuments[documentID]
+	if !ok {
+		return fmt.Errorf("auto document %w", database.ErrNotFound)
+	}
+	for id, file := range db.documentFiles {
+		if file.DocumentID == documentID {
+			delete(db.documentFiles, id)
+			db.audit(ctx, models.AuditActionDelete, models.AuditEntityAutoDocumentFile, id, documentFileSnapshot(file), nil)
+		}
+	}
+	delete(db.autoDocuments, documentID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityAutoDocument, documentID, autoDocumentSnapshot(document), nil)
+	return nil
+}
+
+func (db *MemoryDB) AddAutoDocumentFile(ctx context.Context, file models.AutoDocumentFile) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.autoDocuments[file.DocumentID]; !ok {
+		return 0, fmt.Errorf("failed to add auto document file: %w", conflict("документ с ID %d не существует", file.DocumentID))
+	}
+
+	file.ID = db.newID("auto_document_files")
+	file.UploadedAt = nowTimestamp()
+	db.documentFiles[file.ID] = file
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityAutoDocumentFile, file.ID, nil, documentFileSnapshot(file))
+	return file.ID, nil
+}
+
+func (db *MemoryDB) GetAutoDocumentFile(ctx context.Context, fileID int) (*models.AutoDocumentFile, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	file, ok := db.documentFiles[fileID]
+	if !ok {
+		return nil, fmt.Errorf("auto document file %w", database.ErrNotFound)
+	}
+	return &file, nil
+}
+
+func (db *MemoryDB) DeleteAutoDocumentFile(ctx context.Context, fileID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	file, ok := db.documentFiles[fileID]
+	if !ok {
+		return fmt.Errorf("auto document file %w", database.ErrNotFound)
+	}
+	delete(db.documentFiles, fileID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityAutoDocumentFile, fileID, documentFileSnapshot(file), nil)
+	return nil
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/models"
+)
+
+// Учетная запись администратора демо-режима
+const (
+	DemoAdminUsername = "admin"
+	DemoAdminPassword = "admin123"
+)
+
+// Хранилище с тестовыми данными для демонстрации приложения без PostgreSQL
+func NewDemo(ctx context.Context) (*MemoryDB, error) {
+	db := New()
+
+	passwordHash, err := auth.HashPassword(DemoAdminPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash demo password: %w", err)
+	}
+	if err := db.AddUser(ctx, DemoAdminUsername, passwordHash, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	date := func(years, months, days int) *time.Time {
+		t := today.AddDate(years, months, days)
+		return &t
+	}
+
+	ivanovID, _ := db.AddDriver(ctx, "Иван", "Иванов", "Иванович", models.DriverDocuments{
+		Phone: "+7 900 123-45-67", LicenseNumber: "7701 123456", LicenseCategories: []string{"B", "C"},
+		LicenseIssuedAt: date(-10, 0, 20), LicenseExpiresAt: date(0, 0, 20), MedicalExpiresAt: date(1, 0, 0),
+	})
+	petrovID, _ := db.AddDriver(ctx, "Петр", "Петров", "Петрович", models.DriverDocuments{
+		Phone: "+7 900 765-43-21", LicenseNumber: "7702 654321", LicenseCategories: []string{"B", "D"},
+		LicenseIssuedAt: date(-3, 0, 0), LicenseExpiresAt: date(7, 0, 0), MedicalExpiresAt: date(0, 0, 10),
+	})
+
+	gazelID, err := db.AddCar(ctx, "А123ВС77", "Белый", "ГАЗель", "B", ivanovID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.AddCar(ctx, "В456ОР77", "Синий", "ПАЗ", "D", petrovID); err != nil {
+		return nil, err
+	}
+
+	// Закрепления действуют с начала прошлого месяца; Петров подменяет Иванова на ГАЗели
+	assignments, err := db.GetAssignments(ctx, models.AssignmentFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		if err := db.UpdateAssignmentPeriod(ctx, assignment.ID, *date(0, -1, 0), nil); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := db.AddAssignment(ctx, models.Assignment{AutoID: gazelID, DriverID: petrovID, ValidFrom: *date(0, -1, 0)}); err != nil {
+		return nil, err
+	}
+
+	depotDistance, depotMinutes := 12.5, 40
+	depotID, err := db.AddRoute(ctx, models.Route{
+		StartPoint: "Депо", EndPoint: "Вокзал", DistanceKm: &depotDistance, PlannedMinutes: &depotMinutes,
+		StartCoords: &models.GeoPoint{Lat: 55.7299, Lon: 37.6104}, EndCoords: &models.GeoPoint{Lat: 55.7765, Lon: 37.6550},
+		Stops: []models.RouteStop{{Name: "Центральный рынок"}, {Name: "Площадь Ленина"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.AddRoute(ctx, models.Route{StartPoint: "Вокзал", EndPoint: "Аэропорт"}); err != nil {
+		return nil, err
+	}
+
+	// Рейсы по будням в 08:00 и 17:30; планы по ним формирует фоновый генератор
+	duration := 60
+	for _, departure := range []string{"08:00", "17:30"} {
+		if _, err := db.AddTimetable(ctx, models.Timetable{
+			RouteID: depotID, AutoID: gazelID, DriverID: ivanovID, DepartureTime: departure,
+			DurationMinutes: &duration, Weekdays: []int{1, 2, 3, 4, 5}, ValidFrom: today,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	timeOut := time.Now().Add(-3 * time.Hour).Truncate(time.Minute)
+	odometerOut, fuelOut := 52310, 45.0
+	entryID, err := db.AddJournalEntry(ctx, gazelID, ivanovID, depotID, timeOut, models.TripDeparture{Odometer: &odometerOut, Fuel: &fuelOut})
+	if err != nil {
+		return nil, err
+	}
+	odometerIn, fuelIn := 52374, 37.5
+	arrival := models.TripReturn{Odometer: &odometerIn, Fuel: &fuelIn}
+	if err := db.CompleteJournalEntry(ctx, entryID, timeOut.Add(90*time.Minute), arrival); err != nil {
+		return nil, err
+	}
+
+	odometer := 48200
+	if _, err := db.AddMaintenanceRecord(ctx, models.MaintenanceRecord{
+		AutoID: gazelID, Type: "Замена масла", PerformedAt: today.AddDate(0, -5, 0), Odometer: &odometer, Cost: 4500,
+	}); err != nil {
+		return nil, err
+	}
+	oilDays, oilKm := 180, 10000
+	if _, err := db.AddMaintenanceSchedule(ctx, models.MaintenanceSchedule{
+		AutoID: gazelID, Type: "Замена масла", IntervalDays: &oilDays, IntervalKm: &oilKm, Mandatory: true,
+	}); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
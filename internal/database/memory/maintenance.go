@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Методы для работы с техническим обслуживанием
+func (db *MemoryDB) GetMaintenanceRecords(ctx context.Context, autoID int) ([]models.MaintenanceRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	records := []models.MaintenanceRecord{}
+	for _, record := range db.maintenance {
+		if autoID == 0 || record.AutoID == autoID {
+			record.AutoNum = db.autos[record.AutoID].Num
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].PerformedAt.Equal(records[j].PerformedAt) {
+			return records[i].PerformedAt.After(records[j].PerformedAt)
+		}
+		return records[i].ID > records[j].ID
+	})
+	return records, nil
+}
+
+func (db *MemoryDB) AddMaintenanceRecord(ctx context.Context, record models.MaintenanceRecord) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.autos[record.AutoID]; !ok {
+		return 0, fmt.Errorf("failed to add maintenance record: %w", conflict("автомобиль с ID %d не существует", record.AutoID))
+	}
+
+	record.ID = db.newID("maintenance")
+	record.AutoNum = ""
+	db.maintenance[record.ID] = record
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityMaintenance, record.ID, nil, maintenanceSnapshot(record))
+	return record.ID, nil
+}
+
+func (db *MemoryDB) DeleteMaintenanceRecord(ctx context.Context, recordID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	record, ok := db.maintenance[recordID]
+	if !ok {
+		return fmt.Errorf("maintenance record %w", database.ErrNotFound)
+	}
+	delete(db.maintenance, recordID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityMaintenance, recordID, maintenanceSnapshot(record), nil)
+	return nil
+}
+
+func (db *MemoryDB) GetMaintenanceSchedules(ctx context.Context, autoID int) ([]models.MaintenanceSchedule, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	schedules := []models.MaintenanceSchedule{}
+	for _, schedule := range db.schedules {
+		if autoID == 0 || schedule.AutoID == autoID {
+			schedule.AutoNum = db.autos[schedule.AutoID].Num
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].AutoNum != schedules[j].AutoNum {
+			return schedules[i].AutoNum < schedules[j].AutoNum
+		}
+		return schedules[i].Type < schedules[j].Type
+	})
+	return schedules, nil
+}
+
+// Ограничения таблицы maintenance_schedules: внешний ключ на автомобиль
+// и один регламент каждого вида работ для автомобиля
+func (db *MemoryDB) AddMaintenanceSchedule(ctx context.Context, schedule models.MaintenanceSchedule) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.autos[schedule.AutoID]; !ok {
+		return 0, fmt.Errorf("failed to add maintenance schedule: %w", conflict("автомобиль с ID %d не существует", schedule.AutoID))
+	}
+	for _, existing := range db.schedules {
+		if existing.AutoID == schedule.AutoID && existing.Type == schedule.Type {
+			return 0, fmt.Errorf("failed to add maintenance schedule: %w",
+				conflict("регламент «%s» для автомобиля с ID %d уже существует", schedule.Type, schedule.AutoID))
+		}
+	}
+
+	schedule.ID = db.newID("maintenance_schedules")
+	schedule.AutoNum = ""
+	schedule.CreatedAt = nowTimestamp()
+	db.schedules[schedule.ID] = schedule
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntitySchedule, schedule.ID, nil, scheduleSnapshot(schedule))
+	return schedule.ID, nil
+}
+
+func (db *MemoryDB) DeleteMaintenanceSchedule(ctx context.Context, scheduleID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	schedule, ok := db.schedules[scheduleID]
+	if !ok {
+		return fmt.Errorf("maintenance schedule %w", database.ErrNotFound)
+	}
+	delete(db.schedules, scheduleID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntitySchedule, scheduleID, scheduleSnapshot(schedule), nil)
+	return nil
+}
@@ -0,0 +1,943 @@
+// Пакет memory содержит хранилище автопарка в памяти процесса.
+// Оно повторяет бизнес-правила, которые в PostgreSQL обеспечивают
+// триггеры и ограничения, и используется в демо-режиме и в тестах.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+var _ database.DBHandler = (*MemoryDB)(nil)
+
+type journalRow struct {
+	ID       int
+	TimeOut  time.Time
+	TimeIn   *time.Time
+	RouteID  int
+	AutoID   int
+	DriverID int
+
+	OdometerOut *int
+	OdometerIn  *int
+	FuelOut     *float64
+	FuelIn      *float64
+	FuelAdded   *float64
+}
+
+type apiTokenRow struct {
+	models.APIToken
+	TokenHash string
+}
+
+type MemoryDB struct {
+	mu sync.RWMutex
+
+	// Аналог advisory-блокировок; fn выполняется без db.mu
+	locksMu       sync.Mutex
+	advisoryLocks map[int64]bool
+
+	drivers map[int]models.AutoPersonal
+	autos   map[int]models.Auto
+	routes  map[int]models.Route
+	// Идентификаторы строк route_stops по маршрутам в порядке остановок
+	routeStopIDs map[int][]int
+	journal      map[int]journalRow
+	users        map[int]models.User
+	apiTokens    map[int]apiTokenRow
+	auditLog     []models.AuditEntry
+
+	userSessions map[int]userSessionRow
+
+	maintenance map[int]models.MaintenanceRecord
+	schedules   map[int]models.MaintenanceSchedule
+
+	autoDocuments map[int]models.AutoDocument
+	documentFiles map[int]models.AutoDocumentFile
+
+	assignments map[int]models.Assignment
+	tripPlans   map[int]models.TripPlan
+
+	timetables          map[int]models.Timetable
+	timetableExceptions map[int]models.TimetableException
+
+	nextID map[string]int
+}
+
+func New() *MemoryDB {
+	return &MemoryDB{
+		drivers:      make(map[int]models.AutoPersonal),
+		autos:        make(map[int]models.Auto),
+		routes:       make(map[int]models.Route),
+		routeStopIDs: make(map[int][]int),
+		journal:      make(map[int]journalRow),
+		users:        make(map[int]models.User),
+		apiTokens:    make(map[int]apiTokenRow),
+		nextID:       make(map[string]int),
+
+		advisoryLocks: make(map[int64]bool),
+
+		userSessions: make(map[int]userSessionRow),
+
+		maintenance: make(map[int]models.MaintenanceRecord),
+		schedules:   make(map[int]models.MaintenanceSchedule),
+
+		autoDocuments: make(map[int]models.AutoDocument),
+		documentFiles: make(map[int]models.AutoDocumentFile),
+
+		assignments: make(map[int]models.Assignment),
+		tripPlans:   make(map[int]models.TripPlan),
+
+		timetables:          make(map[int]models.Timetable),
+		timetableExceptions: make(map[int]models.TimetableException),
+	}
+}
+
+// Хранилище в памяти всегда доступно
+func (db *MemoryDB) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (db *MemoryDB) TryAdvisoryLock(ctx context.Context, key int64, fn func() error) (bool, error) {
+	db.locksMu.Lock()
+	if db.advisoryLocks[key] {
+		db.locksMu.Unlock()
+		return false, nil
+	}
+	db.advisoryLocks[key] = true
+	db.locksMu.Unlock()
+
+	defer func() {
+		db.locksMu.Lock()
+		delete(db.advisoryLocks, key)
+		db.locksMu.Unlock()
+	}()
+	return true, fn()
+}
+
+// Аналог SERIAL: последовательность идентификаторов для каждой таблицы
+func (db *MemoryDB) newID(table string) int {
+	db.nextID[table]++
+	return db.nextID[table]
+}
+
+func conflict(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", database.ErrConflict, fmt.Sprintf(format, args...))
+}
+
+// Методы для работы с водителями
+func (db *MemoryDB) GetDrivers(ctx context.Context) ([]models.AutoPersonal, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	drivers := make([]models.AutoPersonal, 0, len(db.drivers))
+	for _, driver := range db.drivers {
+		if driver.ArchivedAt == nil {
+			drivers = append(drivers, driver)
+		}
+	}
+	sort.SliceStable(drivers, func(i, j int) bool {
+		if drivers[i].FirstName != drivers[j].FirstName {
+			return drivers[i].FirstName < drivers[j].FirstName
+		}
+		return drivers[i].ID < drivers[j].ID
+	})
+	return drivers, nil
+}
+
+func (db *MemoryDB) GetDriverByID(ctx context.Context, driverID int) (*models.AutoPersonal, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	driver, ok := db.drivers[driverID]
+	if !ok {
+		return nil, fmt.Errorf("driver %w", database.ErrNotFound)
+	}
+	return &driver, nil
+}
+
+func (db *MemoryDB) AddDriver(ctx context.Context, firstName, lastName, fatherName string, docs models.DriverDocuments) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.checkDriverDocuments(0, docs); err != nil {
+		return 0, fmt.Errorf("failed to add driver: %w", err)
+	}
+
+	id := db.newID("auto_personal")
+	db.drivers[id] = models.AutoPersonal{ID: id, FirstName: firstName, LastName: lastName, FatherName: fatherName,
+		DriverDocuments: copyDocuments(docs)}
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityDriver, id, nil, driverSnapshot(db.drivers[id]))
+	return id, nil
+}
+
+func (db *MemoryDB) UpdateDriver(ctx context.Context, driverID int, firstName, lastName, fatherName string, docs models.DriverDocuments) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	before, ok := db.drivers[driverID]
+	if !ok {
+		return fmt.Errorf("driver %w", database.ErrNotFound)
+	}
+	if err := db.checkDriverDocuments(driverID, docs); err != nil {
+		return fmt.Errorf("failed to update driver: %w", err)
+	}
+	db.drivers[driverID] = models.AutoPersonal{ID: driverID, FirstName: firstName, LastName: lastName, FatherName: fatherName,
+		ArchivedAt: before.ArchivedAt, DriverDocuments: copyDocuments(docs)}
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityDriver, driverID, driverSnapshot(before), driverSnapshot(db.drivers[driverID]))
+	return nil
+}
+
+// Ограничения таблицы auto_personal: уникальный номер удостоверения
+// и дата выдачи раньше даты окончания действия
+func (db *MemoryDB) checkDriverDocuments(driverID int, docs models.DriverDocuments) error {
+	if docs.LicenseNumber != "" {
+		for id, driver := range db.drivers {
+			if id != driverID && driver.LicenseNumber == docs.LicenseNumber {
+				return conflict("водительское удостоверение %s уже указано у другого водителя", docs.LicenseNumber)
+			}
+		}
+	}
+	if docs.LicenseIssuedAt != nil && docs.LicenseExpiresAt != nil && !docs.LicenseIssuedAt.Before(*docs.LicenseExpiresAt) {
+		return conflict("дата выдачи удостоверения должна быть раньше даты окончания действия")
+	}
+	return nil
+}
+
+// Копия документов, чтобы хранилище не разделяло срез категорий с вызывающим кодом
+func copyDocuments(docs models.DriverDocuments) models.DriverDocuments {
+	docs.LicenseCategories = append([]string{}, docs.LicenseCategories...)
+	return docs
+}
+
+// Методы для работы с автомобилями
+func (db *MemoryDB) GetCars(ctx context.Context) ([]models.Auto, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	cars := make([]models.Auto, 0, len(db.autos))
+	for _, auto := range db.autos {
+		if auto.ArchivedAt != nil {
+			continue
+		}
+		auto.DriverFullName = db.driverFullName(auto.PersonalID)
+		cars = append(cars, auto)
+	}
+	sort.Slice(cars, func(i, j int) bool { return cars[i].Num < cars[j].Num })
+	return cars, nil
+}
+
+func (db *MemoryDB) GetCarByID(ctx context.Context, carID int) (*models.Auto, string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	auto, ok := db.autos[carID]
+	if !ok {
+		return nil, "", fmt.Errorf("car %w", database.ErrNotFound)
+	}
+	return &auto, db.driverFullName(auto.PersonalID), nil
+}
+
+func (db *MemoryDB) AddCar(ctx context.Context, num, color, mark, category string, personalID int) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.checkAuto(0, num, personalID, nil); err != nil {
+		return 0, fmt.Errorf("failed to add car: %w", err)
+	}
+
+	id := db.newID("auto")
+	db.autos[id] = models.Auto{ID: id, Num: num, Color: color, Mark: mark, Category: category, PersonalID: personalID}
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityAuto, id, nil, autoSnapshot(db.autos[id]))
+	db.assignPrimaryDriver(ctx, db.autos[id])
+	return id, nil
+}
+
+func (db *MemoryDB) UpdateCar(ctx context.Context, carID int, num, color, mark, category string, personalID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	before, ok := db.autos[carID]
+	if !ok {
+		return fmt.Errorf("car %w", database.ErrNotFound)
+	}
+	if err := db.checkAuto(carID, num, personalID, before.ArchivedAt); err != nil {
+		return fmt.Errorf("не удалось обновить автомобиль с ID %d: %w", carID, err)
+	}
+
+	db.autos[carID] = models.Auto{ID: carID, Num: num, Color: color, Mark: mark, Category: category, PersonalID: personalID,
+		ArchivedAt: before.ArchivedAt}
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityAuto, carID, autoSnapshot(before), autoSnapshot(db.autos[carID]))
+	if before.PersonalID != personalID {
+		db.endPrimaryAssignment(ctx, carID, before.PersonalID)
+	}
+	db.assignPrimaryDriver(ctx, db.autos[carID])
+	return nil
+}
+
+// Ограничения таблицы auto: уникальный госномер, внешний ключ на водителя
+// и триггер PREVENT_ARCHIVED_DRIVER для действующего автомобиля
+func (db *MemoryDB) checkAuto(carID int, num string, personalID int, archivedAt *time.Time) error {
+	for id, auto := range db.autos {
+		if id != carID && auto.Num == num {
+			return conflict("автомобиль с номером %s уже существует", num)
+		}
+	}
+	driver, ok := db.drivers[personalID]
+	if !ok {
+		return conflict("водитель с ID %d не существует", personalID)
+	}
+	if archivedAt == nil && driver.ArchivedAt != nil {
+		return conflict("Водитель с ID %d находится в архиве", personalID)
+	}
+	return nil
+}
+
+func (db *MemoryDB) deleteJournalRow(ctx context.Context, entryID int) {
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityJournal, entryID, journalSnapshot(db.journal[entryID]), nil)
+	delete(db.journal, entryID)
+	db.unlinkTripPlans(ctx, entryID)
+}
+
+// Методы для работы с маршрутами
+func (db *MemoryDB) GetRoutes(ctx context.Context) ([]models.Route, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	routes := make([]models.Route, 0, len(db.routes))
+	for _, route := range db.routes {
+		if route.ArchivedAt == nil {
+			routes = append(routes, routeView(route))
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].ID < routes[j].ID })
+	return routes, nil
+}
+
+func (db *MemoryDB) GetRouteByID(ctx context.Context, routeID int) (*models.Route, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	route, ok := db.routes[routeID]
+	if !ok {
+		return nil, fmt.Errorf("route %w", database.ErrNotFound)
+	}
+	route = routeView(route)
+	return &route, nil
+}
+
+func (db *MemoryDB) AddRoute(ctx context.Context, route models.Route) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := checkRoute(route); err != nil {
+		return 0, fmt.Errorf("failed to add route: %w", err)
+	}
+	id := db.newID("routes")
+	route.ID, route.ArchivedAt = id, nil
+	db.routes[id] = routeView(route)
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityRoute, id, nil, routeSnapshot(db.routes[id]))
+	db.replaceRouteStops(ctx, id, nil, db.routes[id].Stops)
+	return id, nil
+}
+
+func (db *MemoryDB) UpdateRoute(ctx context.Context, route *models.Route) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	before, ok := db.routes[route.ID]
+	if !ok {
+		return fmt.Errorf("route %w", database.ErrNotFound)
+	}
+	if err := checkRoute(*route); err != nil {
+		return fmt.Errorf("failed to update route: %w", err)
+	}
+	updated := routeView(*route)
+	updated.ArchivedAt = before.ArchivedAt
+	db.routes[route.ID] = updated
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityRoute, route.ID, routeSnapshot(before), routeSnapshot(updated))
+	db.replaceRouteStops(ctx, route.ID, before.Stops, updated.Stops)
+	return nil
+}
+
+// Аналог замены строк route_stops: прежние остановки удаляются, новые
+// добавляются с новыми идентификаторами. Вызывается под db.mu.
+func (db *MemoryDB) replaceRouteStops(ctx context.Context, routeID int, before, after []models.RouteStop) {
+	for i, id := range db.routeStopIDs[routeID] {
+		db.audit(ctx, models.AuditActionDelete, models.AuditEntityRouteStop, id, routeStopSnapshot(id, routeID, before[i]), nil)
+	}
+	ids := make([]int, len(after))
+	for i, stop := range after {
+		ids[i] = db.newID("route_stops")
+		db.audit(ctx, models.AuditActionCreate, models.AuditEntityRouteStop, ids[i], nil, routeStopSnapshot(ids[i], routeID, stop))
+	}
+	db.routeStopIDs[routeID] = ids
+}
+
+// Аналог ограничений таблиц routes и route_stops
+func checkRoute(route models.Route) error {
+	if route.DistanceKm != nil && *route.DistanceKm <= 0 {
+		return conflict("new row for relation \"routes\" violates check constraint \"routes_distance_positive\"")
+	}
+	if route.PlannedMinutes != nil && *route.PlannedMinutes <= 0 {
+		return conflict("new row for relation \"routes\" violates check constraint \"routes_planned_minutes_positive\"")
+	}
+	if !validGeoPoint(route.StartCoords) {
+		return conflict("new row for relation \"routes\" violates check constraint \"routes_start_coords\"")
+	}
+	if !validGeoPoint(route.EndCoords) {
+		return conflict("new row for relation \"routes\" violates check constraint \"routes_end_coords\"")
+	}
+	for _, stop := range route.Stops {
+		if !validGeoPoint(stop.Coords) {
+			return conflict("new row for relation \"route_stops\" violates check constraint \"route_stops_coords\"")
+		}
+	}
+	return nil
+}
+
+func validGeoPoint(point *models.GeoPoint) bool {
+	return point == nil || (point.Lat >= -90 && point.Lat <= 90 && point.Lon >= -180 && point.Lon <= 180)
+}
+
+// Копия маршрута; остановки нумеруются по порядку, как при записи в route_stops
+func routeView(route models.Route) models.Route {
+	route.StartCoords = copyGeoPoint(route.StartCoords)
+	route.EndCoords = copyGeoPoint(route.EndCoords)
+	if route.DistanceKm != nil {
+		distance := *route.DistanceKm
+		route.DistanceKm = &distance
+	}
+	route.PlannedMinutes = copyID(route.PlannedMinutes)
+	stops := make([]models.RouteStop, len(route.Stops))
+	for i, stop := range route.Stops {
+		stops[i] = models.RouteStop{Position: i + 1, Name: stop.Name, Coords: copyGeoPoint(stop.Coords)}
+	}
+	route.Stops = stops
+	route.ArchivedAt = copyDate(route.ArchivedAt)
+	return route
+}
+
+func copyGeoPoint(point *models.GeoPoint) *models.GeoPoint {
+	if point == nil {
+		return nil
+	}
+	value := *point
+	return &value
+}
+
+// Методы для работы с журналом
+func (db *MemoryDB) GetJournalEntries(ctx context.Context, filter models.JournalFilter) (*models.JournalPage, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	entries := make([]models.JournalView, 0, len(db.journal))
+	for _, row := range db.journal {
+		entry := db.journalView(row)
+		if matchesJournalFilter(entry, filter) {
+			entries = append(entries, entry)
+		}
+	}
+
+	less := journalLess(filter.SortBy)
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if filter.SortDesc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.JournalID < b.JournalID
+	})
+
+	page := &models.JournalPage{Total: len(entries), Page: filter.Page, PageSize: filter.PageSize}
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		if offset > len(entries) {
+			offset = len(entries)
+		}
+		end := offset + filter.PageSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		entries = entries[offset:end]
+	}
+	page.Entries = entries
+	return page, nil
+}
+
+func matchesJournalFilter(entry models.JournalView, filter models.JournalFilter) bool {
+	if filter.TimeOutFrom != nil && entry.TimeOut.Before(*filter.TimeOutFrom) {
+		return false
+	}
+	if filter.TimeOutTo != nil && !entry.TimeOut.Before(*filter.TimeOutTo) {
+		return false
+	}
+	if filter.DriverID > 0 && entry.DriverID != filter.DriverID {
+		return false
+	}
+	if filter.AutoNumber != "" && !strings.Contains(strings.ToLower(entry.AutoNumber), strings.ToLower(filter.AutoNumber)) {
+		return false
+	}
+	if filter.RouteID > 0 && entry.RouteID != filter.RouteID {
+		return false
+	}
+	switch filter.Status {
+	case models.JournalStatusInProgress:
+		return entry.TimeIn == nil
+	case models.JournalStatusCompleted:
+		return entry.TimeIn != nil
+	}
+	return true
+}
+
+// Порядок сортировки журнала; как и в PostgreSQL, незавершенные рейсы
+// (time_in IS NULL) идут после завершенных при сортировке по возрастанию
+func journalLess(sortBy string) func(a, b models.JournalView) bool {
+	switch sortBy {
+	case models.JournalSortTimeIn:
+		return func(a, b models.JournalView) bool {
+			if a.TimeIn == nil || b.TimeIn == nil {
+				return a.TimeIn != nil && b.TimeIn == nil
+			}
+			return a.TimeIn.Before(*b.TimeIn)
+		}
+	case models.JournalSortDriver:
+		return func(a, b models.JournalView) bool { return a.DriverName < b.DriverName }
+	case models.JournalSortAuto:
+		return func(a, b models.JournalView) bool { return a.AutoNumber < b.AutoNumber }
+	case models.JournalSortRoute:
+		return func(a, b models.JournalView) bool {
+			if a.StartPoint != b.StartPoint {
+				return a.StartPoint < b.StartPoint
+			}
+			return a.EndPoint < b.EndPoint
+		}
+	default:
+		return func(a, b models.JournalView) bool { return a.TimeOut.Before(b.TimeOut) }
+	}
+}
+
+func (db *MemoryDB) GetJournalEntryByID(ctx context.Context, journalID int) (*models.JournalView, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.journal[journalID]
+	if !ok {
+		return nil, fmt.Errorf("journal entry %w", database.ErrNotFound)
+	}
+	entry := db.journalView(row)
+	return &entry, nil
+}
+
+func (db *MemoryDB) GetAutosByDriverID(ctx context.Context, driverID int) ([]models.Auto, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var autos []models.Auto
+	now := time.Now()
+	for _, auto := range db.autos {
+		if auto.ArchivedAt == nil && db.isAssigned(auto.ID, driverID, now) {
+			autos = append(autos, auto)
+		}
+	}
+	sort.Slice(autos, func(i, j int) bool { return autos[i].ID < autos[j].ID })
+	return autos, nil
+}
+
+func (db *MemoryDB) AddJournalEntry(ctx context.Context, autoID, driverID, routeID int, timeOut time.Time, departure models.TripDeparture) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.addJournalRow(ctx, autoID, driverID, routeID, timeOut, departure)
+}
+
+// Вставка в journal с проверками внешних ключей и триггеров; вызывается под db.mu
+func (db *MemoryDB) addJournalRow(ctx context.Context, autoID, driverID, routeID int, timeOut time.Time, departure models.TripDeparture) (int, error) {
+	auto, ok := db.autos[autoID]
+	if !ok {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w", conflict("автомобиль с ID %d не существует", autoID))
+	}
+	driver, ok := db.drivers[driverID]
+	if !ok {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w", conflict("водитель с ID %d не существует", driverID))
+	}
+	route, ok := db.routes[routeID]
+	if !ok {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w", conflict("маршрут с ID %d не существует", routeID))
+	}
+	// Аналог триггера PREVENT_ARCHIVED_JOURNAL
+	if auto.ArchivedAt != nil {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w", conflict("Автомобиль %d находится в архиве", autoID))
+	}
+	if driver.ArchivedAt != nil {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w", conflict("Водитель с ID %d находится в архиве", driverID))
+	}
+	if route.ArchivedAt != nil {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w", conflict("Маршрут %d находится в архиве", routeID))
+	}
+
+	// Аналог триггера prevent_driver_double_booking
+	for _, row := range db.journal {
+		if row.TimeIn == nil && row.DriverID == driverID {
+			return 0, fmt.Errorf("failed to add journal_table entry: %w",
+				conflict("Водитель с ID %d не может быть отправлен в рейс, пока не вернется с предыдущего маршрута.", driverID))
+		}
+	}
+	// Аналог триггера PREVENT_AUTO_SENDING
+	for _, row := range db.journal {
+		if row.TimeIn == nil && row.AutoID == autoID {
+			return 0, fmt.Errorf("failed to add journal_table entry: %w",
+				conflict("Автомобиль %d еще не вернулся в парк, отправка невозможна", autoID))
+		}
+	}
+	// Аналог триггера PREVENT_UNASSIGNED_DRIVER
+	if !db.isAssigned(autoID, driverID, timeOut) {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w",
+			conflict("Водитель с ID %d не закреплен за автомобилем %d на %s", driverID, autoID, timeOut.Format("02.01.2006")))
+	}
+
+	row := journalRow{TimeOut: timeOut, RouteID: routeID, AutoID: autoID, DriverID: driverID, OdometerOut: departure.Odometer, FuelOut: departure.Fuel}
+	if err := db.checkOdometer(row); err != nil {
+		return 0, fmt.Errorf("failed to add journal_table entry: %w", err)
+	}
+
+	id := db.newID("journal")
+	row.ID = id
+	db.journal[id] = row
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityJournal, id, nil, journalSnapshot(db.journal[id]))
+	return id, nil
+}
+
+func (db *MemoryDB) CompleteJournalEntry(ctx context.Context, entryID int, timeIn time.Time, arrival models.TripReturn) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.journal[entryID]
+	if !ok {
+		return fmt.Errorf("journal entry %w", database.ErrNotFound)
+	}
+	// Аналог триггера PREVENT_INVALID_ARRIVAL_TIME
+	if timeIn.Before(row.TimeOut) {
+		return fmt.Errorf("failed to update journal_table entry: %w",
+			conflict("Время прибытия не может быть меньше времени отправления: time_in = %s, time_out = %s",
+				timeIn.Format("2006-01-02 15:04:05"), row.TimeOut.Format("2006-01-02 15:04:05")))
+	}
+
+	before := journalSnapshot(row)
+	row.TimeIn = &timeIn
+	row.OdometerIn = arrival.Odometer
+	row.FuelIn = arrival.Fuel
+	row.FuelAdded = arrival.FuelAdded
+	if err := db.checkOdometer(row); err != nil {
+		return fmt.Errorf("failed to update journal_table entry: %w", err)
+	}
+	db.journal[entryID] = row
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityJournal, entryID, before, journalSnapshot(row))
+	return nil
+}
+
+func (db *MemoryDB) DeleteJournalEntry(ctx context.Context, entryID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.journal[entryID]; !ok {
+		return fmt.Errorf("journal entry %w", database.ErrNotFound)
+	}
+	db.deleteJournalRow(ctx, entryID)
+	return nil
+}
+
+// Аналог ограничения journal_odometer_order и триггера PREVENT_ODOMETER_ROLLBACK:
+// показания одометра автомобиля не убывают от рейса к рейсу
+func (db *MemoryDB) checkOdometer(row journalRow) error {
+	low, high, ok := odometerRange(row)
+	if !ok {
+		return nil
+	}
+	if row.OdometerOut != nil && row.OdometerIn != nil && *row.OdometerIn < *row.OdometerOut {
+		return conflict("new row for relation \"journal\" violates check constraint \"journal_odometer_order\"")
+	}
+	for _, other := range db.journal {
+		if other.AutoID != row.AutoID || other.ID == row.ID {
+			continue
+		}
+		otherLow, otherHigh, ok := odometerRange(other)
+		if !ok {
+			continue
+		}
+		if other.TimeOut.Before(row.TimeOut) && low < otherHigh {
+			return conflict("Показания одометра %d меньше показаний предыдущего рейса автомобиля: %d", low, otherHigh)
+		}
+		if other.TimeOut.After(row.TimeOut) && high > otherLow {
+			return conflict("Показания одометра %d больше показаний следующего рейса автомобиля: %d", high, otherLow)
+		}
+	}
+	return nil
+}
+
+// Наименьшее и наибольшее из известных показаний одометра рейса
+func odometerRange(row journalRow) (low, high int, ok bool) {
+	for _, reading := range []*int{row.OdometerOut, row.OdometerIn} {
+		if reading == nil {
+			continue
+		}
+		if !ok || *reading < low {
+			low = *reading
+		}
+		if !ok || *reading > high {
+			high = *reading
+		}
+		ok = true
+	}
+	return low, high, ok
+}
+
+func (db *MemoryDB) GetOdometerReadings(ctx context.Context, autoID int) (map[int]int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	readings := make(map[int]int)
+	for _, row := range db.journal {
+		if autoID != 0 && row.AutoID != autoID {
+			continue
+		}
+		if _, high, ok := odometerRange(row); ok {
+			if current, exists := readings[row.AutoID]; !exists || high > current {
+				readings[row.AutoID] = high
+			}
+		}
+	}
+	return readings, nil
+}
+
+// Пакетный импорт: ограничения проверяются для всего пакета до записи,
+// чтобы ошибка в любой строке, как и откат транзакции, не оставляла частичных данных
+func (db *MemoryDB) ImportData(ctx context.Context, batch *models.ImportBatch) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	nums := make(map[string]bool)
+	for _, auto := range db.autos {
+		nums[auto.Num] = true
+	}
+	for _, auto := range batch.Autos {
+		if nums[auto.Num] {
+			return fmt.Errorf("failed to import car at row %d: %w", auto.Row, conflict("автомобиль с номером %s уже существует", auto.Num))
+		}
+		nums[auto.Num] = true
+		if auto.PersonalID != 0 {
+			driver, ok := db.drivers[auto.PersonalID]
+			if !ok {
+				return fmt.Errorf("failed to import car at row %d: %w", auto.Row, conflict("водитель с ID %d не существует", auto.PersonalID))
+			}
+			if driver.ArchivedAt != nil {
+				return fmt.Errorf("failed to import car at row %d: %w", auto.Row, conflict("Водитель с ID %d находится в архиве", auto.PersonalID))
+			}
+		} else if auto.DriverIndex < 0 || auto.DriverIndex >= len(batch.Drivers) {
+			return fmt.Errorf("failed to import car at row %d: %w", auto.Row, conflict("водитель не найден"))
+		}
+	}
+
+	driverIDs := make([]int, len(batch.Drivers))
+	for i, driver := range batch.Drivers {
+		id := db.newID("auto_personal")
+		db.drivers[id] = models.AutoPersonal{ID: id, FirstName: driver.FirstName, LastName: driver.LastName, FatherName: driver.FatherName,
+			DriverDocuments: copyDocuments(models.DriverDocuments{})}
+		db.audit(ctx, models.AuditActionCreate, models.AuditEntityDriver, id, nil, driverSnapshot(db.drivers[id]))
+		driverIDs[i] = id
+	}
+	for _, auto := range batch.Autos {
+		personalID := auto.PersonalID
+		if personalID == 0 {
+			personalID = driverIDs[auto.DriverIndex]
+		}
+		id := db.newID("auto")
+		db.autos[id] = models.Auto{ID: id, Num: auto.Num, Color: auto.Color, Mark: auto.Mark, Category: models.DefaultAutoCategory,
+			PersonalID: personalID}
+		db.audit(ctx, models.AuditActionCreate, models.AuditEntityAuto, id, nil, autoSnapshot(db.autos[id]))
+		db.assignPrimaryDriver(ctx, db.autos[id])
+	}
+	for _, route := range batch.Routes {
+		id := db.newID("routes")
+		db.routes[id] = models.Route{ID: id, StartPoint: route.StartPoint, EndPoint: route.EndPoint, Stops: []models.RouteStop{}}
+		db.audit(ctx, models.AuditActionCreate, models.AuditEntityRoute, id, nil, routeSnapshot(db.routes[id]))
+	}
+	return nil
+}
+
+// Аналог функции get_routes_vehicle_count()
+func (db *MemoryDB) GetRoutesVehicleCount(ctx context.Context) ([]models.RouteVehicleCount, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	autosByRoute := make(map[string]map[int]struct{})
+	for _, row := range db.journal {
+		route := db.routes[row.RouteID]
+		name := route.StartPoint + " - " + route.EndPoint
+		if autosByRoute[name] == nil {
+			autosByRoute[name] = make(map[int]struct{})
+		}
+		autosByRoute[name][row.AutoID] = struct{}{}
+	}
+
+	result := make([]models.RouteVehicleCount, 0, len(autosByRoute))
+	for name, autos := range autosByRoute {
+		result = append(result, models.RouteVehicleCount{RouteName: name, VehicleCount: int64(len(autos))})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].VehicleCount != result[j].VehicleCount {
+			return result[i].VehicleCount > result[j].VehicleCount
+		}
+		return result[i].RouteName < result[j].RouteName
+	})
+	return result, nil
+}
+
+// Методы для работы с пользователями автопарка
+func (db *MemoryDB) AddUser(ctx context.Context, username, passwordHash, role string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, user := range db.users {
+		if user.Username == username {
+			return fmt.Errorf("failed to add user: %w", conflict("пользователь %s уже существует", username))
+		}
+	}
+	if role != models.RoleAdmin && role != models.RoleUser {
+		return fmt.Errorf("failed to add user: %w", conflict("недопустимая роль %s", role))
+	}
+
+	id := db.newID("users")
+	db.users[id] = models.User{ID: id, Username: username, PasswordHash: passwordHash, Role: role, CreatedAt: time.Now()}
+	return nil
+}
+
+func (db *MemoryDB) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, user := range db.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+// Методы для работы с токенами доступа к API
+func (db *MemoryDB) AddAPIToken(ctx context.Context, userID int, name, tokenHash string, expiresAt *time.Time) (*models.APIToken, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[userID]; !ok {
+		return nil, fmt.Errorf("failed to add api token: %w", conflict("пользователь с ID %d не существует", userID))
+	}
+	for _, token := range db.apiTokens {
+		if token.TokenHash == tokenHash {
+			return nil, fmt.Errorf("failed to add api token: %w", conflict("токен уже существует"))
+		}
+	}
+
+	token := models.APIToken{ID: db.newID("api_tokens"), UserID: userID, Name: name, CreatedAt: time.Now(), ExpiresAt: expiresAt}
+	db.apiTokens[token.ID] = apiTokenRow{APIToken: token, TokenHash: tokenHash}
+	return &token, nil
+}
+
+func (db *MemoryDB) GetAPITokensByUserID(ctx context.Context, userID int) ([]models.APIToken, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var tokens []models.APIToken
+	for _, token := range db.apiTokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token.APIToken)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.After(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+func (db *MemoryDB) GetUserByAPITokenHash(ctx context.Context, tokenHash string, usedAt time.Time) (*models.User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for id, token := range db.apiTokens {
+		if token.TokenHash != tokenHash || token.RevokedAt != nil {
+			continue
+		}
+		if token.ExpiresAt != nil && !token.ExpiresAt.After(usedAt) {
+			continue
+		}
+		user, ok := db.users[token.UserID]
+		if !ok {
+			break
+		}
+		token.LastUsedAt = &usedAt
+		db.apiTokens[id] = token
+		return &user, nil
+	}
+	return nil, fmt.Errorf("api token %w", database.ErrNotFound)
+}
+
+func (db *MemoryDB) RevokeAPIToken(ctx context.Context, userID, tokenID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	token, ok := db.apiTokens[tokenID]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return fmt.Errorf("api token %w", database.ErrNotFound)
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	db.apiTokens[tokenID] = token
+	return nil
+}
+
+// Полное имя водителя, как в запросе GetCars
+func (db *MemoryDB) driverFullName(driverID int) string {
+	driver, ok := db.drivers[driverID]
+	if !ok {
+		return ""
+	}
+	return driver.LastName + " " + driver.FirstName + " " + driver.FatherName
+}
+
+// Аналог представления journal_view
+func (db *MemoryDB) journalView(row journalRow) models.JournalView {
+	route := db.routes[row.RouteID]
+	auto := db.autos[row.AutoID]
+	driver := db.drivers[row.DriverID]
+	return models.JournalView{
+		JournalID:  row.ID,
+		TimeOut:    row.TimeOut,
+		TimeIn:     row.TimeIn,
+		StartPoint: route.StartPoint,
+		EndPoint:   route.EndPoint,
+		AutoNumber: auto.Num,
+		AutoMark:   auto.Mark,
+		DriverName: driver.FirstName + " " + driver.LastName,
+		AutoID:     row.AutoID,
+		RouteID:    row.RouteID,
+		DriverID:   row.DriverID,
+
+		OdometerOut: row.OdometerOut,
+		OdometerIn:  row.OdometerIn,
+		FuelOut:     row.FuelOut,
+		FuelIn:      row.FuelIn,
+		FuelAdded:   row.FuelAdded,
+
+		PlannedDistance: route.DistanceKm,
+		PlannedMinutes:  route.PlannedMinutes,
+	}
+}
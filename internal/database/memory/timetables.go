@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Методы для работы с расписаниями маршрутов
+func (db *MemoryDB) GetTimetables(ctx context.Context, routeID int) ([]models.Timetable, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	timetables := []models.Timetable{}
+	for _, timetable := range db.timetables {
+		if routeID == 0 || timetable.RouteID == routeID {
+			timetables = append(timetables, db.timetableView(timetable))
+		}
+	}
+	sort.Slice(timetables, func(i, j int) bool {
+		if timetables[i].RouteName != timetables[j].RouteName {
+			return timetables[i].RouteName < timetables[j].RouteName
+		}
+		if timetables[i].DepartureTime != timetables[j].DepartureTime {
+			return timetables[i].DepartureTime < timetables[j].DepartureTime
+		}
+		return timetables[i].ID < timetables[j].ID
+	})
+	return timetables, nil
+}
+
+func (db *MemoryDB) GetTimetableByID(ctx context.Context, timetableID int) (*models.Timetable, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	timetable, ok := db.timetables[timetableID]
+	if !ok {
+		return nil, fmt.Errorf("timetable %w", database.ErrNotFound)
+	}
+	timetable = db.timetableView(timetable)
+	return &timetable, nil
+}
+
+// Внешние ключи и ограничения route_timetables_duration, route_timetables_weekdays
+// и route_timetables_period
+func (db *MemoryDB) AddTimetable(ctx context.Context, timetable models.Timetable) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.routes[timetable.RouteID]; !ok {
+		return 0, fmt.Errorf("failed to add timetable: %w", conflict("маршрут с ID %d не существует", timetable.RouteID))
+	}
+	if _, ok := db.autos[timetable.AutoID]; !ok {
+		return 0, fmt.Errorf("failed to add timetable: %w", conflict("автомобиль с ID %d не существует", timetable.AutoID))
+	}
+	if _, ok := db.drivers[timetable.DriverID]; !ok {
+		return 0, fmt.Errorf("failed to add timetable: %w", conflict("водитель с ID %d не существует", timetable.DriverID))
+	}
+	departure, err := time.Parse("15:04", timetable.DepartureTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add timetable: invalid input syntax for type time: %q", timetable.DepartureTime)
+	}
+	if d := timetable.DurationMinutes; d != nil && (*d < 1 || *d > 1440) {
+		return 0, fmt.Errorf("failed to add timetable: %w",
+			conflict("new row for relation \"route_timetables\" violates check constraint \"route_timetables_duration\""))
+	}
+	if len(timetable.Weekdays) == 0 {
+		return 0, fmt.Errorf("failed to add timetable: %w",
+			conflict("new row for relation \"route_timetables\" violates check constraint \"route_timetables_weekdays\""))
+	}
+	for _, day := range timetable.Weekdays {
+		if day < 1 || day > 7 {
+			return 0, fmt.Errorf("failed to add timetable: %w",
+				conflict("new row for relation \"route_timetables\" violates check constraint \"route_timetables_weekdays\""))
+		}
+	}
+	if timetable.ValidTo != nil && timetable.ValidTo.Before(timetable.ValidFrom) {
+		return 0, fmt.Errorf("failed to add timetable: %w",
+			conflict("new row for relation \"route_timetables\" violates check constraint \"route_timetables_period\""))
+	}
+
+	id := db.newID("route_timetables")
+	db.timetables[id] = models.Timetable{ID: id, RouteID: timetable.RouteID, AutoID: timetable.AutoID,
+		DriverID: timetable.DriverID, DepartureTime: departure.Format("15:04"), DurationMinutes: copyID(timetable.DurationMinutes),
+		Weekdays: append([]int(nil), timetable.Weekdays...), ValidFrom: timetable.ValidFrom, ValidTo: copyDate(timetable.ValidTo),
+		CreatedAt: nowTimestamp()}
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityTimetable, id, nil, timetableSnapshot(db.timetables[id]))
+	return id, nil
+}
+
+// Неотправленные планы с plansFrom удаляются, исключения расписания — каскадно,
+// у остальных сформированных планов ссылка на расписание обнуляется (ON DELETE SET NULL)
+func (db *MemoryDB) DeleteTimetable(ctx context.Context, timetableID int, plansFrom time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	timetable, ok := db.timetables[timetableID]
+	if !ok {
+		return fmt.Errorf("timetable %w", database.ErrNotFound)
+	}
+	for id, exception := range db.timetableExceptions {
+		if exception.TimetableID != nil && *exception.TimetableID == timetableID {
+			delete(db.timetableExceptions, id)
+			db.audit(ctx, models.AuditActionDelete, models.AuditEntityTimetableException, id,
+				timetableExceptionSnapshot(exception), nil)
+		}
+	}
+	for id, plan := range db.tripPlans {
+		if plan.TimetableID == nil || *plan.TimetableID != timetableID {
+			continue
+		}
+		if !plan.Dispatched() && !plan.PlannedOut.Before(plansFrom) {
+			delete(db.tripPlans, id)
+			db.audit(ctx, models.AuditActionDelete, models.AuditEntityTripPlan, id, tripPlanSnapshot(plan), nil)
+		} else {
+			before := tripPlanSnapshot(plan)
+			plan.TimetableID = nil
+			db.tripPlans[id] = plan
+			db.audit(ctx, models.AuditActionUpdate, models.AuditEntityTripPlan, id, before, tripPlanSnapshot(plan))
+		}
+	}
+	delete(db.timetables, timetableID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityTimetable, timetableID, timetableSnapshot(timetable), nil)
+	return nil
+}
+
+func (db *MemoryDB) SetTimetableGeneratedUntil(ctx context.Context, timetableID int, until time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	timetable, ok := db.timetables[timetableID]
+	if !ok {
+		return fmt.Errorf("timetable %w", database.ErrNotFound)
+	}
+	before := timetableSnapshot(timetable)
+	timetable.GeneratedUntil = &until
+	db.timetables[timetableID] = timetable
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityTimetable, timetableID, before, timetableSnapshot(timetable))
+	return nil
+}
+
+func (db *MemoryDB) GetTimetableExceptions(ctx context.Context) ([]models.TimetableException, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	exceptions := make([]models.TimetableException, 0, len(db.timetableExceptions))
+	for _, exception := range db.timetableExceptions {
+		exception.TimetableID = copyID(exception.TimetableID)
+		exceptions = append(exceptions, exception)
+	}
+	sort.Slice(exceptions, func(i, j int) bool {
+		if !exceptions[i].Date.Equal(exceptions[j].Date) {
+			return exceptions[i].Date.Before(exceptions[j].Date)
+		}
+		return exceptions[i].ID < exceptions[j].ID
+	})
+	return exceptions, nil
+}
+
+// Аналог уникального индекса idx_timetable_exceptions_unique
+func (db *MemoryDB) AddTimetableException(ctx context.Context, exception models.TimetableException) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if exception.TimetableID != nil {
+		if _, ok := db.timetables[*exception.TimetableID]; !ok {
+			return 0, fmt.Errorf("failed to add timetable exception: %w",
+				conflict("расписание с ID %d не существует", *exception.TimetableID))
+		}
+	}
+	for _, other := range db.timetableExceptions {
+		if other.Date.Equal(exception.Date) && sameTimetable(other.TimetableID, exception.TimetableID) {
+			return 0, fmt.Errorf("failed to add timetable exception: %w",
+				conflict("duplicate key value violates unique constraint \"idx_timetable_exceptions_unique\""))
+		}
+	}
+
+	id := db.newID("timetable_exceptions")
+	db.timetableExceptions[id] = models.TimetableException{ID: id, TimetableID: copyID(exception.TimetableID),
+		Date: exception.Date, Reason: exception.Reason}
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityTimetableException, id, nil,
+		timetableExceptionSnapshot(db.timetableExceptions[id]))
+	return id, nil
+}
+
+func (db *MemoryDB) DeleteTimetableException(ctx context.Context, exceptionID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	exception, ok := db.timetableExceptions[exceptionID]
+	if !ok {
+		return fmt.Errorf("timetable exception %w", database.ErrNotFound)
+	}
+	delete(db.timetableExceptions, exceptionID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityTimetableException, exceptionID,
+		timetableExceptionSnapshot(exception), nil)
+	return nil
+}
+
+func sameTimetable(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (db *MemoryDB) timetableView(timetable models.Timetable) models.Timetable {
+	driver := db.drivers[timetable.DriverID]
+	route := db.routes[timetable.RouteID]
+	timetable.RouteName = route.StartPoint + " - " + route.EndPoint
+	timetable.AutoNum = db.autos[timetable.AutoID].Num
+	timetable.DriverName = driver.LastName + " " + driver.FirstName
+	timetable.DurationMinutes = copyID(timetable.DurationMinutes)
+	timetable.Weekdays = append([]int(nil), timetable.Weekdays...)
+	timetable.ValidTo = copyDate(timetable.ValidTo)
+	timetable.GeneratedUntil = copyDate(timetable.GeneratedUntil)
+	return timetable
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Методы для работы с планами рейсов
+func (db *MemoryDB) GetTripPlans(ctx context.Context, filter models.TripPlanFilter) ([]models.TripPlan, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	plans := []models.TripPlan{}
+	for _, plan := range db.tripPlans {
+		if filter.From != nil && plan.PlannedOut.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !plan.PlannedOut.Before(*filter.To) {
+			continue
+		}
+		if filter.AutoID > 0 && plan.AutoID != filter.AutoID {
+			continue
+		}
+		if filter.DriverID > 0 && plan.DriverID != filter.DriverID {
+			continue
+		}
+		if filter.TimetableID > 0 && (plan.TimetableID == nil || *plan.TimetableID != filter.TimetableID) {
+			continue
+		}
+		plans = append(plans, db.tripPlanView(plan))
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		if !plans[i].PlannedOut.Equal(plans[j].PlannedOut) {
+			return plans[i].PlannedOut.Before(plans[j].PlannedOut)
+		}
+		return plans[i].ID < plans[j].ID
+	})
+	return plans, nil
+}
+
+func (db *MemoryDB) GetTripPlanByID(ctx context.Context, planID int) (*models.TripPlan, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	plan, ok := db.tripPlans[planID]
+	if !ok {
+		return nil, fmt.Errorf("trip plan %w", database.ErrNotFound)
+	}
+	plan = db.tripPlanView(plan)
+	return &plan, nil
+}
+
+// Внешние ключи, ограничения trip_plans_period и trip_plans_timetable_unique
+func (db *MemoryDB) AddTripPlan(ctx context.Context, plan models.TripPlan) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.autos[plan.AutoID]; !ok {
+		return 0, fmt.Errorf("failed to add trip plan: %w", conflict("автомобиль с ID %d не существует", plan.AutoID))
+	}
+	if _, ok := db.drivers[plan.DriverID]; !ok {
+		return 0, fmt.Errorf("failed to add trip plan: %w", conflict("водитель с ID %d не существует", plan.DriverID))
+	}
+	if _, ok := db.routes[plan.RouteID]; !ok {
+		return 0, fmt.Errorf("failed to add trip plan: %w", conflict("маршрут с ID %d не существует", plan.RouteID))
+	}
+	if plan.PlannedIn != nil && !plan.PlannedIn.After(plan.PlannedOut) {
+		return 0, fmt.Errorf("failed to add trip plan: %w",
+			conflict("new row for relation \"trip_plans\" violates check constraint \"trip_plans_period\""))
+	}
+
+	if plan.TimetableID != nil {
+		if _, ok := db.timetables[*plan.TimetableID]; !ok {
+			return 0, fmt.Errorf("failed to add trip plan: %w", conflict("расписание с ID %d не существует", *plan.TimetableID))
+		}
+		for _, other := range db.tripPlans {
+			if other.TimetableID != nil && *other.TimetableID == *plan.TimetableID && other.PlannedOut.Equal(plan.PlannedOut) {
+				return 0, fmt.Errorf("failed to add trip plan: %w", database.ErrTimetablePlanExists)
+			}
+		}
+	}
+
+	id := db.newID("trip_plans")
+	db.tripPlans[id] = models.TripPlan{ID: id, AutoID: plan.AutoID, DriverID: plan.DriverID, RouteID: plan.RouteID,
+		PlannedOut: plan.PlannedOut, PlannedIn: copyDate(plan.PlannedIn), Notes: plan.Notes,
+		TimetableID: copyID(plan.TimetableID), CreatedAt: nowTimestamp()}
+	db.audit(ctx, models.AuditActionCreate, models.AuditEntityTripPlan, id, nil, tripPlanSnapshot(db.tripPlans[id]))
+	return id, nil
+}
+
+func (db *MemoryDB) DeleteTripPlan(ctx context.Context, planID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	plan, ok := db.tripPlans[planID]
+	if !ok {
+		return fmt.Errorf("trip plan %w", database.ErrNotFound)
+	}
+	delete(db.tripPlans, planID)
+	db.audit(ctx, models.AuditActionDelete, models.AuditEntityTripPlan, planID, tripPlanSnapshot(plan), nil)
+	return nil
+}
+
+func (db *MemoryDB) DispatchTripPlan(ctx context.Context, plan models.TripPlan, timeOut time.Time, departure models.TripDeparture) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.tripPlans[plan.ID]
+	if !ok || stored.JournalID != nil {
+		return 0, fmt.Errorf("%w: план %d уже отправлен в рейс или удален", database.ErrConflict, plan.ID)
+	}
+	entryID, err := db.addJournalRow(ctx, plan.AutoID, plan.DriverID, plan.RouteID, timeOut, departure)
+	if err != nil {
+		return 0, err
+	}
+	before := tripPlanSnapshot(stored)
+	stored.JournalID = &entryID
+	db.tripPlans[plan.ID] = stored
+	db.audit(ctx, models.AuditActionUpdate, models.AuditEntityTripPlan, plan.ID, before, tripPlanSnapshot(stored))
+	return entryID, nil
+}
+
+// Аналог внешнего ключа fk_trip_plans_journal с ON DELETE SET NULL
+func (db *MemoryDB) unlinkTripPlans(ctx context.Context, journalID int) {
+	for id, plan := range db.tripPlans {
+		if plan.JournalID != nil && *plan.JournalID == journalID {
+			before := tripPlanSnapshot(plan)
+			plan.JournalID = nil
+			db.tripPlans[id] = plan
+			db.audit(ctx, models.AuditActionUpdate, models.AuditEntityTripPlan, id, before, tripPlanSnapshot(plan))
+		}
+	}
+}
+
+func (db *MemoryDB) tripPlanView(plan models.TripPlan) models.TripPlan {
+	driver := db.drivers[plan.DriverID]
+	route := db.routes[plan.RouteID]
+	plan.AutoNum = db.autos[plan.AutoID].Num
+	plan.DriverName = driver.LastName + " " + driver.FirstName
+	plan.RouteName = route.StartPoint + " - " + route.EndPoint
+	plan.PlannedIn = copyDate(plan.PlannedIn)
+	plan.JournalID = copyID(plan.JournalID)
+	plan.TimetableID = copyID(plan.TimetableID)
+	return plan
+}
+
+func copyID(id *int) *int {
+	if id == nil {
+		return nil
+	}
+	value := *id
+	return &value
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+type userSessionRow struct {
+	models.UserSession
+	TokenHash string
+}
+
+// Методы для работы с серверными сессиями
+func (db *MemoryDB) AddUserSession(ctx context.Context, session models.UserSession, tokenHash string) (*models.UserSession, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	user, ok := db.users[session.UserID]
+	if !ok {
+		return nil, fmt.Errorf("failed to add user session: %w", conflict("пользователь с ID %d не существует", session.UserID))
+	}
+	for _, row := range db.userSessions {
+		if row.TokenHash == tokenHash {
+			return nil, fmt.Errorf("failed to add user session: %w", conflict("сессия уже существует"))
+		}
+	}
+
+	now := time.Now()
+	session.ID = db.newID("user_sessions")
+	session.Username = user.Username
+	session.CreatedAt, session.LastSeenAt = now, now
+	db.userSessions[session.ID] = userSessionRow{UserSession: session, TokenHash: tokenHash}
+	return &session, nil
+}
+
+func (db *MemoryDB) GetUserBySessionHash(ctx context.Context, tokenHash string, seenAt time.Time) (*models.User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for id, row := range db.userSessions {
+		if row.TokenHash != tokenHash || !row.ExpiresAt.After(seenAt) {
+			continue
+		}
+		user, ok := db.users[row.UserID]
+		if !ok {
+			break
+		}
+		row.LastSeenAt = seenAt
+		db.userSessions[id] = row
+		return &user, nil
+	}
+	return nil, fmt.Errorf("user session %w", database.ErrNotFound)
+}
+
+func (db *MemoryDB) GetUserSessions(ctx context.Context, userID int, now time.Time) ([]models.UserSession, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var sessions []models.UserSession
+	for _, row := range db.userSessions {
+		if row.ExpiresAt.After(now) && (userID == 0 || row.UserID == userID) {
+			sessions = append(sessions, row.UserSession)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (db *MemoryDB) DeleteUserSession(ctx context.Context, userID, sessionID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.userSessions[sessionID]
+	if !ok || (userID != 0 && row.UserID != userID) {
+		return fmt.Errorf("user session %w", database.ErrNotFound)
+	}
+	delete(db.userSessions, sessionID)
+	return nil
+}
+
+func (db *MemoryDB) DeleteUserSessionByHash(ctx context.Context, tokenHash string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for id, row := range db.userSessions {
+		if row.TokenHash == tokenHash {
+			delete(db.userSessions, id)
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) DeleteExpiredUserSessions(ctx context.Context, now time.Time) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	deleted := 0
+	for id, row := range db.userSessions {
+		if !row.ExpiresAt.After(now) {
+			delete(db.userSessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Метод для сохранения нового токена доступа
+func (db *PostgresDB) AddAPIToken(ctx context.Context, userID int, name, tokenHash string, expiresAt *time.Time) (*models.APIToken, error) {
+	token := models.APIToken{UserID: userID, Name: name, ExpiresAt: expiresAt}
+
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := db.Pool.QueryRow(ctx, query, userID, name, tokenHash, expiresAt).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add api token: %w", translateError(err))
+	}
+
+	return &token, nil
+}
+
+// Метод для получения токенов пользователя
+func (db *PostgresDB) GetAPITokensByUserID(ctx context.Context, userID int) ([]models.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, created_at, last_used_at, expires_at, revoked_at
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []models.APIToken
+	for rows.Next() {
+		var token models.APIToken
+		if err := rows.Scan(&token.ID, &token.UserID, &token.Name, &token.CreatedAt, &token.LastUsedAt, &token.ExpiresAt, &token.RevokedAt); err != nil {
+			return nil, fmt.Errorf("error scanning api token row: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Метод для поиска владельца действующего токена с отметкой времени использования
+func (db *PostgresDB) GetUserByAPITokenHash(ctx context.Context, tokenHash string, usedAt time.Time) (*models.User, error) {
+	query := `
+		WITH token AS (
+			UPDATE api_tokens SET last_used_at = $2
+			WHERE token_hash = $1
+			  AND revoked_at IS NULL
+			  AND (expires_at IS NULL OR expires_at > $2)
+			RETURNING user_id
+		)
+		SELECT u.id, u.username, u.password_hash, u.role
+		FROM users u
+		JOIN token t ON t.user_id = u.id
+	`
+	var user models.User
+	err := db.Pool.QueryRow(ctx, query, tokenHash, usedAt).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("api token %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user by api token: %v", err)
+	}
+
+	return &user, nil
+}
+
+// Метод для отзыва токена пользователя
+func (db *PostgresDB) RevokeAPIToken(ctx context.Context, userID, tokenID int) error {
+	query := `UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	result, err := db.Pool.Exec(ctx, query, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("api token %w", ErrNotFound)
+	}
+	return nil
+}
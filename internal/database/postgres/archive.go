@@ -0,0 +1,214 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Проверка, что по условию нет незавершенных рейсов
+func checkNoTripsInProgress(ctx context.Context, tx pgx.Tx, condition string, id int, what string) error {
+	var inProgress bool
+	query := `SELECT EXISTS (
+		SELECT 1 FROM journal j JOIN auto a ON a.id = j.auto_id
+		WHERE j.time_in IS NULL AND ` + condition + `)`
+	if err := tx.QueryRow(ctx, query, id).Scan(&inProgress); err != nil {
+		return fmt.Errorf("failed to check trips in progress: %v", err)
+	}
+	if inProgress {
+		return fmt.Errorf("%w: %s находится в рейсе", ErrConflict, what)
+	}
+	return nil
+}
+
+// Перенос водителя в архив вместе с его автомобилями. История рейсов сохраняется.
+func (db *PostgresDB) ArchiveDriver(ctx context.Context, driverID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		var archivedAt time.Time
+		query := `UPDATE auto_personal SET archived_at = now() WHERE id = $1 AND archived_at IS NULL RETURNING archived_at`
+		if err := tx.QueryRow(ctx, query, driverID).Scan(&archivedAt); err != nil {
+			if err == pgx.ErrNoRows {
+				return fmt.Errorf("driver %w", ErrNotFound)
+			}
+			return fmt.Errorf("failed to archive driver: %w", translateError(err))
+		}
+
+		if err := checkNoTripsInProgress(ctx, tx, "(a.personal_id = $1 OR j.personal_id = $1)", driverID, "водитель или его автомобиль"); err != nil {
+			return err
+		}
+
+		carsQuery := `UPDATE auto SET archived_at = $1 WHERE personal_id = $2 AND archived_at IS NULL`
+		if _, err := tx.Exec(ctx, carsQuery, archivedAt, driverID); err != nil {
+			return fmt.Errorf("failed to archive driver cars: %w", translateError(err))
+		}
+		return nil
+	})
+}
+
+// Восстановление водителя; автомобили, попавшие в архив вместе с ним, тоже восстанавливаются
+func (db *PostgresDB) RestoreDriver(ctx context.Context, driverID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		var archivedAt time.Time
+		query := `SELECT archived_at FROM auto_personal WHERE id = $1 AND archived_at IS NOT NULL FOR UPDATE`
+		if err := tx.QueryRow(ctx, query, driverID).Scan(&archivedAt); err != nil {
+			if err == pgx.ErrNoRows {
+				return fmt.Errorf("archived driver %w", ErrNotFound)
+			}
+			return fmt.Errorf("failed to restore driver: %v", err)
+		}
+
+		restoreQuery := `UPDATE auto_personal SET archived_at = NULL WHERE id = $1`
+		if _, err := tx.Exec(ctx, restoreQuery, driverID); err != nil {
+			return fmt.Errorf("failed to restore driver: %w", translateError(err))
+		}
+
+		carsQuery := `UPDATE auto SET archived_at = NULL WHERE personal_id = $1 AND archived_at = $2`
+		if _, err := tx.Exec(ctx, carsQuery, driverID, archivedAt); err != nil {
+			return fmt.Errorf("failed to restore driver cars: %w", translateError(err))
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) ArchiveCar(ctx context.Context, carID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `UPDATE auto SET archived_at = now() WHERE id = $1 AND archived_at IS NULL`
+		result, err := tx.Exec(ctx, query, carID)
+		if err != nil {
+			return fmt.Errorf("failed to archive car: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("car %w", ErrNotFound)
+		}
+		return checkNoTripsInProgress(ctx, tx, "a.id = $1", carID, "автомобиль")
+	})
+}
+
+// Восстановление автомобиля; триггер не даст восстановить его за архивным водителем
+func (db *PostgresDB) RestoreCar(ctx context.Context, carID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `UPDATE auto SET archived_at = NULL WHERE id = $1 AND archived_at IS NOT NULL`
+		result, err := tx.Exec(ctx, query, carID)
+		if err != nil {
+			return fmt.Errorf("не удалось восстановить автомобиль с ID %d: %w", carID, translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("archived car %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) ArchiveRoute(ctx context.Context, routeID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `UPDATE routes SET archived_at = now() WHERE id = $1 AND archived_at IS NULL`
+		result, err := tx.Exec(ctx, query, routeID)
+		if err != nil {
+			return fmt.Errorf("failed to archive route: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("route %w", ErrNotFound)
+		}
+		return checkNoTripsInProgress(ctx, tx, "j.route_id = $1", routeID, "маршрут")
+	})
+}
+
+func (db *PostgresDB) RestoreRoute(ctx context.Context, routeID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `UPDATE routes SET archived_at = NULL WHERE id = $1 AND archived_at IS NOT NULL`
+		result, err := tx.Exec(ctx, query, routeID)
+		if err != nil {
+			return fmt.Errorf("failed to restore route: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("archived route %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// Получение архивных водителей, автомобилей и маршрутов, последние архивированные — первыми
+func (db *PostgresDB) GetArchive(ctx context.Context) (*models.Archive, error) {
+	archive := &models.Archive{
+		Drivers: []models.AutoPersonal{},
+		Autos:   []models.Auto{},
+		Routes:  []models.Route{},
+	}
+
+	driversQuery := `
+		SELECT id, first_name, last_name, father_name, archived_at, ` + driverDocumentColumns + `
+		FROM auto_personal
+		WHERE archived_at IS NOT NULL
+		ORDER BY archived_at DESC, id DESC
+	`
+	rows, err := db.Pool.Query(ctx, driversQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching archived drivers: %w", err)
+	}
+	for rows.Next() {
+		var driver models.AutoPersonal
+		fields := append([]interface{}{&driver.ID, &driver.FirstName, &driver.LastName, &driver.FatherName, &driver.ArchivedAt},
+			driverDocumentFields(&driver.DriverDocuments)...)
+		if err := rows.Scan(fields...); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning archived driver row: %w", err)
+		}
+		archive.Drivers = append(archive.Drivers, driver)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	autosQuery := `
+		SELECT a.id, a.num, a.color, a.mark, a.category, a.personal_id, a.archived_at,
+		       CONCAT(p.last_name, ' ', p.first_name, ' ', p.father_name) AS driver_full_name
+		FROM auto a
+		LEFT JOIN auto_personal p ON a.personal_id = p.id
+		WHERE a.archived_at IS NOT NULL
+		ORDER BY a.archived_at DESC, a.id DESC
+	`
+	rows, err = db.Pool.Query(ctx, autosQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching archived cars: %w", err)
+	}
+	for rows.Next() {
+		var car models.Auto
+		if err := rows.Scan(&car.ID, &car.Num, &car.Color, &car.Mark, &car.Category, &car.PersonalID, &car.ArchivedAt, &car.DriverFullName); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning archived car row: %w", err)
+		}
+		archive.Autos = append(archive.Autos, car)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	routesQuery := `
+		SELECT id, start_point, end_point, archived_at
+		FROM routes
+		WHERE archived_at IS NOT NULL
+		ORDER BY archived_at DESC, id DESC
+	`
+	rows, err = db.Pool.Query(ctx, routesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching archived routes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var route models.Route
+		if err := rows.Scan(&route.ID, &route.StartPoint, &route.EndPoint, &route.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("error scanning archived route row: %w", err)
+		}
+		archive.Routes = append(archive.Routes, route)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return archive, nil
+}
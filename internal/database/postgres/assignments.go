@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Методы для работы с закреплением водителей за автомобилями
+const assignmentColumns = `s.id, s.auto_id, a.num, s.personal_id,
+	p.last_name || ' ' || p.first_name || ' ' || p.father_name, s.valid_from, s.valid_to, s.created_at`
+
+const assignmentFrom = `
+	FROM auto_assignments s
+	JOIN auto a ON a.id = s.auto_id
+	JOIN auto_personal p ON p.id = s.personal_id`
+
+func scanAssignment(row pgx.Row, assignment *models.Assignment) error {
+	return row.Scan(&assignment.ID, &assignment.AutoID, &assignment.AutoNum, &assignment.DriverID,
+		&assignment.DriverName, &assignment.ValidFrom, &assignment.ValidTo, &assignment.CreatedAt)
+}
+
+func (db *PostgresDB) GetAssignments(ctx context.Context, filter models.AssignmentFilter) ([]models.Assignment, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.AutoID > 0 {
+		add("s.auto_id = $%d", filter.AutoID)
+	}
+	if filter.DriverID > 0 {
+		add("s.personal_id = $%d", filter.DriverID)
+	}
+	if filter.ActiveOn != nil {
+		add("s.valid_from <= $%d::DATE", *filter.ActiveOn)
+		add("(s.valid_to IS NULL OR s.valid_to >= $%d::DATE)", *filter.ActiveOn)
+	}
+
+	query := "SELECT " + assignmentColumns + assignmentFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY a.num, s.valid_from DESC, s.id DESC"
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []models.Assignment{}
+	for rows.Next() {
+		var assignment models.Assignment
+		if err := scanAssignment(rows, &assignment); err != nil {
+			return nil, fmt.Errorf("error scanning assignment row: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return assignments, nil
+}
+
+func (db *PostgresDB) GetAssignmentByID(ctx context.Context, assignmentID int) (*models.Assignment, error) {
+	var assignment models.Assignment
+	query := "SELECT " + assignmentColumns + assignmentFrom + " WHERE s.id = $1"
+	if err := scanAssignment(db.Pool.QueryRow(ctx, query, assignmentID), &assignment); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("assignment %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get assignment: %v", err)
+	}
+	return &assignment, nil
+}
+
+func (db *PostgresDB) AddAssignment(ctx context.Context, assignment models.Assignment) (int, error) {
+	var assignmentID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `INSERT INTO auto_assignments (auto_id, personal_id, valid_from, valid_to) VALUES ($1, $2, $3, $4) RETURNING id`
+		err := tx.QueryRow(ctx, query, assignment.AutoID, assignment.DriverID, assignment.ValidFrom, assignment.ValidTo).
+			Scan(&assignmentID)
+		if err != nil {
+			return fmt.Errorf("failed to add assignment: %w", translateError(err))
+		}
+		return nil
+	})
+	return assignmentID, err
+}
+
+func (db *PostgresDB) UpdateAssignmentPeriod(ctx context.Context, assignmentID int, validFrom time.Time, validTo *time.Time) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `UPDATE auto_assignments SET valid_from = $1, valid_to = $2 WHERE id = $3`
+		result, err := tx.Exec(ctx, query, validFrom, validTo, assignmentID)
+		if err != nil {
+			return fmt.Errorf("failed to update assignment: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("assignment %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) DeleteAssignment(ctx context.Context, assignmentID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM auto_assignments WHERE id = $1`, assignmentID)
+		if err != nil {
+			return fmt.Errorf("failed to delete assignment: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("assignment %w", ErrNotFound)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Передача пользователя из контекста запроса триггеру аудита.
+// Параметры действуют только до конца текущей транзакции.
+func setAuditActor(ctx context.Context, tx pgx.Tx) error {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	query := `SELECT set_config('app.user_id', $1, true), set_config('app.username', $2, true)`
+	if _, err := tx.Exec(ctx, query, strconv.Itoa(user.ID), user.Username); err != nil {
+		return fmt.Errorf("failed to set audit actor: %v", err)
+	}
+	return nil
+}
+
+func auditFilterClause(filter models.AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+	if filter.UserID > 0 {
+		add("user_id = $%d", filter.UserID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		add("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID > 0 {
+		add("entity_id = $%d", filter.EntityID)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// Записи журнала аудита, новые первыми
+func (db *PostgresDB) GetAuditLog(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error) {
+	where, args := auditFilterClause(filter)
+
+	page := &models.AuditPage{Page: filter.Page, PageSize: filter.PageSize}
+	if err := db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM audit_log "+where, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count audit log entries: %v", err)
+	}
+
+	query := `
+		SELECT id, user_id, COALESCE(username, ''), action, entity_type, entity_id, before_data, after_data, created_at
+		FROM audit_log ` + where + ` ORDER BY id DESC`
+	if filter.PageSize > 0 {
+		args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.Username, &entry.Action, &entry.EntityType,
+			&entry.EntityID, &before, &after, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log entry: %v", err)
+		}
+		entry.Before, entry.After = before, after
+		page.Entries = append(page.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return page, nil
+}
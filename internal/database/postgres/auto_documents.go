@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Методы для работы с документами автомобилей
+func (db *PostgresDB) GetAutoDocuments(ctx context.Context, autoID int) ([]models.AutoDocument, error) {
+	query := `
+		SELECT d.id, d.auto_id, a.num, d.type, d.number, d.issued_at, d.expires_at, d.notes, d.created_at
+		FROM auto_documents d
+		JOIN auto a ON a.id = d.auto_id
+		WHERE $1 = 0 OR d.auto_id = $1
+		ORDER BY a.num ASC, d.type ASC, d.expires_at DESC NULLS FIRST, d.id DESC
+	`
+	rows, err := db.Pool.Query(ctx, query, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching auto documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []models.AutoDocument{}
+	index := make(map[int]int)
+	for rows.Next() {
+		var document models.AutoDocument
+		if err := rows.Scan(&document.ID, &document.AutoID, &document.AutoNum, &document.Type, &document.Number,
+			&document.IssuedAt, &document.ExpiresAt, &document.Notes, &document.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning auto document row: %w", err)
+		}
+		document.Files = []models.AutoDocumentFile{}
+		index[document.ID] = len(documents)
+		documents = append(documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	rows.Close()
+
+	filesQuery := `
+		SELECT f.id, f.document_id, f.file_name, f.content_type, f.size, f.stored_name, f.uploaded_at
+		FROM auto_document_files f
+		JOIN auto_documents d ON d.id = f.document_id
+		WHERE $1 = 0 OR d.auto_id = $1
+		ORDER BY f.uploaded_at ASC, f.id ASC
+	`
+	fileRows, err := db.Pool.Query(ctx, filesQuery, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching auto document files: %w", err)
+	}
+	defer fileRows.Close()
+	for fileRows.Next() {
+		var file models.AutoDocumentFile
+		if err := scanAutoDocumentFile(fileRows, &file); err != nil {
+			return nil, err
+		}
+		if i, ok := index[file.DocumentID]; ok {
+			documents[i].Files = append(documents[i].Files, file)
+		}
+	}
+	if err := fileRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return documents, nil
+}
+
+func (db *PostgresDB) GetAutoDocumentByID(ctx context.Context, documentID int) (*models.AutoDocument, error) {
+	query := `
+		SELECT d.id, d.auto_id, a.num, d.type, d.number, d.issued_at, d.expires_at, d.notes, d.created_at
+		FROM auto_documents d
+		JOIN auto a ON a.id = d.auto_id
+		WHERE d.id = $1
+	`
+	var document models.AutoDocument
+	err := db.Pool.QueryRow(ctx, query, documentID).Scan(&document.ID, &document.AutoID, &document.AutoNum,
+		&document.Type, &document.Number, &document.IssuedAt, &document.ExpiresAt, &document.Notes, &document.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("auto document %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("error fetching auto document: %w", err)
+	}
+
+	filesQuery := `
+		SELECT id, document_id, file_name, content_type, size, stored_name, uploaded_at
+		FROM auto_document_files
+		WHERE document_id = $1
+		ORDER BY uploaded_at ASC, id ASC
+	`
+	rows, err := db.Pool.Query(ctx, filesQuery, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching auto document files: %w", err)
+	}
+	defer rows.Close()
+
+	document.Files = []models.AutoDocumentFile{}
+	for rows.Next() {
+		var file models.AutoDocumentFile
+		if err := scanAutoDocumentFile(rows, &file); err != nil {
+			return nil, err
+		}
+		document.Files = append(document.Files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return &document, nil
+}
+
+func (db *PostgresDB) AddAutoDocument(ctx context.Context, document models.AutoDocument) (int, error) {
+	var documentID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO auto_documents (auto_id, type, number, issued_at, expires_at, notes)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+		`
+		err := tx.QueryRow(ctx, query, document.AutoID, document.Type, document.Number,
+			document.IssuedAt, document.ExpiresAt, document.Notes).Scan(&documentID)
+		if err != nil {
+			return fmt.Errorf("failed to add auto document: %w", translateError(err))
+		}
+		return nil
+	})
+	return documentID, err
+}
+
+// Сканы документа удаляются из таблицы каскадно; файлы на диске удаляет сервис
+func (db *PostgresDB) DeleteAutoDocument(ctx context.Context, documentID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM auto_documents WHERE id = $1`, documentID)
+		if err != nil {
+			return fmt.Errorf("failed to delete auto document: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("auto document %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) AddAutoDocumentFile(ctx context.Context, file models.AutoDocumentFile) (int, error) {
+	var fileID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO auto_document_files (document_id, file_name, content_type, size, stored_name)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id
+		`
+		err := tx.QueryRow(ctx, query, file.DocumentID, file.FileName, file.ContentType,
+			file.Size, file.StoredName).Scan(&fileID)
+		if err != nil {
+			return fmt.Errorf("failed to add auto document file: %w", translateError(err))
+		}
+		return nil
+	})
+	return fileID, err
+}
+
+func (db *PostgresDB) GetAutoDocumentFile(ctx context.Context, fileID int) (*models.AutoDocumentFile, error) {
+	query := `
+		SELECT id, document_id, file_name, content_type, size, stored_name, uploaded_at
+		FROM auto_document_files
+		WHERE id = $1
+	`
+	var file models.AutoDocumentFile
+	if err := scanAutoDocumentFile(db.Pool.QueryRow(ctx, query, fileID), &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (db *PostgresDB) DeleteAutoDocumentFile(ctx context.Context, fileID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM auto_document_files WHERE id = $1`, fileID)
+		if err != nil {
+			return fmt.Errorf("failed to delete auto document file: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("auto document file %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func scanAutoDocumentFile(row pgx.Row, file *models.AutoDocumentFile) error {
+	err := row.Scan(&file.ID, &file.DocumentID, &file.FileName, &file.ContentType, &file.Size,
+		&file.StoredName, &file.UploadedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("auto document file %w", ErrNotFound)
+		}
+		return fmt.Errorf("error scanning auto document file row: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// Запись не найдена
+	ErrNotFound = errors.New("not found")
+	// Операция нарушает бизнес-правило или ограничение базы данных
+	ErrConflict = errors.New("conflict")
+	// План по расписанию на это время уже сформирован (ограничение trip_plans_timetable_unique)
+	ErrTimetablePlanExists = fmt.Errorf("%w: план по расписанию на это время уже сформирован", ErrConflict)
+)
+
+// Приведение ошибок триггеров и ограничений PostgreSQL к ErrConflict
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "P0001", "23503", "23505", "23514":
+			return fmt.Errorf("%w: %s", ErrConflict, pgErr.Message)
+		}
+	}
+	return err
+}
+
+// Нарушение ограничения уникальности constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
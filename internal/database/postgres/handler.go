@@ -8,34 +8,100 @@ import (
 )
 
 type DBHandler interface {
+	// Проверка доступности хранилища для /readyz
+	Ping(ctx context.Context) error
+	// Выполнение fn под advisory-блокировкой key, если ее не держит другой экземпляр
+	// приложения; false — блокировка занята и fn не вызывалась
+	TryAdvisoryLock(ctx context.Context, key int64, fn func() error) (bool, error)
+
 	// Методы для работы с водителями
 	GetDrivers(ctx context.Context) ([]models.AutoPersonal, error)
 	GetDriverByID(ctx context.Context, driverID int) (*models.AutoPersonal, error)
-	AddDriver(ctx context.Context, firstName, lastName, fatherName string) error
-	UpdateDriver(ctx context.Context, driverID int, firstName, lastName, fatherName string) error
-	DeleteDriver(ctx context.Context, driverID int) error
+	AddDriver(ctx context.Context, firstName, lastName, fatherName string, docs models.DriverDocuments) (int, error)
+	UpdateDriver(ctx context.Context, driverID int, firstName, lastName, fatherName string, docs models.DriverDocuments) error
+	ArchiveDriver(ctx context.Context, driverID int) error
+	RestoreDriver(ctx context.Context, driverID int) error
 
 	// Методы для работы с автомобилями
 	GetCars(ctx context.Context) ([]models.Auto, error)
 	GetCarByID(ctx context.Context, carID int) (*models.Auto, string, error)
-	AddCar(ctx context.Context, num, color, mark string, personalID int) error
-	UpdateCar(ctx context.Context, carID int, num, color, mark string, personalID int) error
-	DeleteCar(ctx context.Context, carID int) error
+	AddCar(ctx context.Context, num, color, mark, category string, personalID int) (int, error)
+	UpdateCar(ctx context.Context, carID int, num, color, mark, category string, personalID int) error
+	ArchiveCar(ctx context.Context, carID int) error
+	RestoreCar(ctx context.Context, carID int) error
 
 	// Методы для работы с маршрутами
 	GetRoutes(ctx context.Context) ([]models.Route, error)
 	GetRouteByID(ctx context.Context, routeID int) (*models.Route, error)
-	AddRoute(ctx context.Context, startPoint, endPoint string) error
+	AddRoute(ctx context.Context, route models.Route) (int, error)
 	UpdateRoute(ctx context.Context, route *models.Route) error
-	DeleteRoute(ctx context.Context, routeID int) error
+	ArchiveRoute(ctx context.Context, routeID int) error
+	RestoreRoute(ctx context.Context, routeID int) error
+
+	// Закрепление водителей за автомобилями
+	GetAssignments(ctx context.Context, filter models.AssignmentFilter) ([]models.Assignment, error)
+	GetAssignmentByID(ctx context.Context, assignmentID int) (*models.Assignment, error)
+	AddAssignment(ctx context.Context, assignment models.Assignment) (int, error)
+	UpdateAssignmentPeriod(ctx context.Context, assignmentID int, validFrom time.Time, validTo *time.Time) error
+	DeleteAssignment(ctx context.Context, assignmentID int) error
+
+	// Архив водителей, автомобилей и маршрутов
+	GetArchive(ctx context.Context) (*models.Archive, error)
 
 	// Методы для работы с журналом
-	GetAllJournalEntries(ctx context.Context) ([]models.JournalView, error)
+	GetJournalEntries(ctx context.Context, filter models.JournalFilter) (*models.JournalPage, error)
 	GetJournalEntryByID(ctx context.Context, journalID int) (*models.JournalView, error)
+	// Автомобили, за которыми водитель закреплен на текущую дату
 	GetAutosByDriverID(ctx context.Context, driverID int) ([]models.Auto, error)
-	AddJournalEntry(ctx context.Context, autoID, routeID int, timeOut time.Time) error
-	CompleteJournalEntry(ctx context.Context, entryID int, timeIn time.Time) error
+	AddJournalEntry(ctx context.Context, autoID, driverID, routeID int, timeOut time.Time, departure models.TripDeparture) (int, error)
+	CompleteJournalEntry(ctx context.Context, entryID int, timeIn time.Time, arrival models.TripReturn) error
 	DeleteJournalEntry(ctx context.Context, entryID int) error
+	// Наибольшие показания одометра по журналу рейсов: автомобиля autoID или всех при autoID = 0
+	GetOdometerReadings(ctx context.Context, autoID int) (map[int]int, error)
+
+	// Планирование рейсов
+	GetTripPlans(ctx context.Context, filter models.TripPlanFilter) ([]models.TripPlan, error)
+	GetTripPlanByID(ctx context.Context, planID int) (*models.TripPlan, error)
+	AddTripPlan(ctx context.Context, plan models.TripPlan) (int, error)
+	DeleteTripPlan(ctx context.Context, planID int) error
+	// Запись журнала по плану и связь плана с ней в одной транзакции;
+	// план, уже отправленный в рейс или удаленный, — конфликт
+	DispatchTripPlan(ctx context.Context, plan models.TripPlan, timeOut time.Time, departure models.TripDeparture) (int, error)
+
+	// Расписания маршрутов; routeID = 0 — по всем маршрутам
+	GetTimetables(ctx context.Context, routeID int) ([]models.Timetable, error)
+	GetTimetableByID(ctx context.Context, timetableID int) (*models.Timetable, error)
+	AddTimetable(ctx context.Context, timetable models.Timetable) (int, error)
+	// Удаление расписания вместе с его неотправленными планами с момента plansFrom
+	DeleteTimetable(ctx context.Context, timetableID int, plansFrom time.Time) error
+	// Планы по расписанию сформированы по день until включительно
+	SetTimetableGeneratedUntil(ctx context.Context, timetableID int, until time.Time) error
+	GetTimetableExceptions(ctx context.Context) ([]models.TimetableException, error)
+	AddTimetableException(ctx context.Context, exception models.TimetableException) (int, error)
+	DeleteTimetableException(ctx context.Context, exceptionID int) error
+
+	// Техническое обслуживание автомобилей; autoID = 0 — по всем автомобилям
+	GetMaintenanceRecords(ctx context.Context, autoID int) ([]models.MaintenanceRecord, error)
+	AddMaintenanceRecord(ctx context.Context, record models.MaintenanceRecord) (int, error)
+	DeleteMaintenanceRecord(ctx context.Context, recordID int) error
+	GetMaintenanceSchedules(ctx context.Context, autoID int) ([]models.MaintenanceSchedule, error)
+	AddMaintenanceSchedule(ctx context.Context, schedule models.MaintenanceSchedule) (int, error)
+	DeleteMaintenanceSchedule(ctx context.Context, scheduleID int) error
+
+	// Документы автомобилей со сканами; autoID = 0 — по всем автомобилям
+	GetAutoDocuments(ctx context.Context, autoID int) ([]models.AutoDocument, error)
+	GetAutoDocumentByID(ctx context.Context, documentID int) (*models.AutoDocument, error)
+	AddAutoDocument(ctx context.Context, document models.AutoDocument) (int, error)
+	DeleteAutoDocument(ctx context.Context, documentID int) error
+	AddAutoDocumentFile(ctx context.Context, file models.AutoDocumentFile) (int, error)
+	GetAutoDocumentFile(ctx context.Context, fileID int) (*models.AutoDocumentFile, error)
+	DeleteAutoDocumentFile(ctx context.Context, fileID int) error
+
+	// Пакетный импорт справочников в одной транзакции
+	ImportData(ctx context.Context, batch *models.ImportBatch) error
+
+	// Журнал аудита изменений
+	GetAuditLog(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error)
 
 	// Процедуры для аналитики
 	GetRoutesVehicleCount(ctx context.Context) ([]models.RouteVehicleCount, error)
@@ -43,4 +109,18 @@ type DBHandler interface {
 	// Методы для работы с пользователями автопарка
 	AddUser(ctx context.Context, username, passwordHash, role string) error
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+
+	// Методы для работы с токенами доступа к API
+	AddAPIToken(ctx context.Context, userID int, name, tokenHash string, expiresAt *time.Time) (*models.APIToken, error)
+	GetAPITokensByUserID(ctx context.Context, userID int) ([]models.APIToken, error)
+	GetUserByAPITokenHash(ctx context.Context, tokenHash string, usedAt time.Time) (*models.User, error)
+	RevokeAPIToken(ctx context.Context, userID, tokenID int) error
+
+	// Серверные сессии веб-интерфейса; userID = 0 в выборке и удалении — любой пользователь
+	AddUserSession(ctx context.Context, session models.UserSession, tokenHash string) (*models.UserSession, error)
+	GetUserBySessionHash(ctx context.Context, tokenHash string, seenAt time.Time) (*models.User, error)
+	GetUserSessions(ctx context.Context, userID int, now time.Time) ([]models.UserSession, error)
+	DeleteUserSession(ctx context.Context, userID, sessionID int) error
+	DeleteUserSessionByHash(ctx context.Context, tokenHash string) error
+	DeleteExpiredUserSessions(ctx context.Context, now time.Time) (int, error)
 }
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Запись проверенного пакета импорта. Ошибка в любой строке откатывает весь импорт.
+func (db *PostgresDB) ImportData(ctx context.Context, batch *models.ImportBatch) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		driverIDs := make([]int, len(batch.Drivers))
+		for i, driver := range batch.Drivers {
+			query := `INSERT INTO auto_personal (first_name, last_name, father_name) VALUES ($1, $2, $3) RETURNING id`
+			if err := tx.QueryRow(ctx, query, driver.FirstName, driver.LastName, driver.FatherName).Scan(&driverIDs[i]); err != nil {
+				return fmt.Errorf("failed to import driver at row %d: %w", driver.Row, translateError(err))
+			}
+		}
+
+		for _, auto := range batch.Autos {
+			personalID := auto.PersonalID
+			if personalID == 0 {
+				personalID = driverIDs[auto.DriverIndex]
+			}
+			query := `INSERT INTO auto (num, color, mark, personal_id) VALUES ($1, $2, $3, $4)`
+			if _, err := tx.Exec(ctx, query, auto.Num, auto.Color, auto.Mark, personalID); err != nil {
+				return fmt.Errorf("failed to import car at row %d: %w", auto.Row, translateError(err))
+			}
+		}
+
+		for _, route := range batch.Routes {
+			query := `INSERT INTO routes (start_point, end_point) VALUES ($1, $2)`
+			if _, err := tx.Exec(ctx, query, route.StartPoint, route.EndPoint); err != nil {
+				return fmt.Errorf("failed to import route at row %d: %w", route.Row, translateError(err))
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Методы для работы с техническим обслуживанием
+func (db *PostgresDB) GetMaintenanceRecords(ctx context.Context, autoID int) ([]models.MaintenanceRecord, error) {
+	query := `
+		SELECT m.id, m.auto_id, a.num, m.type, m.performed_at, m.odometer, m.cost::float8, m.notes
+		FROM maintenance m
+		JOIN auto a ON a.id = m.auto_id
+		WHERE $1 = 0 OR m.auto_id = $1
+		ORDER BY m.performed_at DESC, m.id DESC
+	`
+	rows, err := db.Pool.Query(ctx, query, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching maintenance records: %w", err)
+	}
+	defer rows.Close()
+
+	records := []models.MaintenanceRecord{}
+	for rows.Next() {
+		var record models.MaintenanceRecord
+		if err := rows.Scan(&record.ID, &record.AutoID, &record.AutoNum, &record.Type, &record.PerformedAt,
+			&record.Odometer, &record.Cost, &record.Notes); err != nil {
+			return nil, fmt.Errorf("error scanning maintenance row: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return records, nil
+}
+
+func (db *PostgresDB) AddMaintenanceRecord(ctx context.Context, record models.MaintenanceRecord) (int, error) {
+	var recordID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO maintenance (auto_id, type, performed_at, odometer, cost, notes)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+		`
+		err := tx.QueryRow(ctx, query, record.AutoID, record.Type, record.PerformedAt,
+			record.Odometer, record.Cost, record.Notes).Scan(&recordID)
+		if err != nil {
+			return fmt.Errorf("failed to add maintenance record: %w", translateError(err))
+		}
+		return nil
+	})
+	return recordID, err
+}
+
+func (db *PostgresDB) DeleteMaintenanceRecord(ctx context.Context, recordID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM maintenance WHERE id = $1`, recordID)
+		if err != nil {
+			return fmt.Errorf("failed to delete maintenance record: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("maintenance record %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) GetMaintenanceSchedules(ctx context.Context, autoID int) ([]models.MaintenanceSchedule, error) {
+	query := `
+		SELECT s.id, s.auto_id, a.num, s.type, s.interval_days, s.interval_km, s.mandatory, s.start_odometer, s.created_at
+		FROM maintenance_schedules s
+		JOIN auto a ON a.id = s.auto_id
+		WHERE $1 = 0 OR s.auto_id = $1
+		ORDER BY a.num ASC, s.type ASC
+	`
+	rows, err := db.Pool.Query(ctx, query, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching maintenance schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []models.MaintenanceSchedule{}
+	for rows.Next() {
+		var schedule models.MaintenanceSchedule
+		if err := rows.Scan(&schedule.ID, &schedule.AutoID, &schedule.AutoNum, &schedule.Type,
+			&schedule.IntervalDays, &schedule.IntervalKm, &schedule.Mandatory, &schedule.StartOdometer, &schedule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning maintenance schedule row: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return schedules, nil
+}
+
+func (db *PostgresDB) AddMaintenanceSchedule(ctx context.Context, schedule models.MaintenanceSchedule) (int, error) {
+	var scheduleID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO maintenance_schedules (auto_id, type, interval_days, interval_km, mandatory, start_odometer)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+		`
+		err := tx.QueryRow(ctx, query, schedule.AutoID, schedule.Type, schedule.IntervalDays,
+			schedule.IntervalKm, schedule.Mandatory, schedule.StartOdometer).Scan(&scheduleID)
+		if err != nil {
+			return fmt.Errorf("failed to add maintenance schedule: %w", translateError(err))
+		}
+		return nil
+	})
+	return scheduleID, err
+}
+
+func (db *PostgresDB) DeleteMaintenanceSchedule(ctx context.Context, scheduleID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM maintenance_schedules WHERE id = $1`, scheduleID)
+		if err != nil {
+			return fmt.Errorf("failed to delete maintenance schedule: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("maintenance schedule %w", ErrNotFound)
+		}
+		return nil
+	})
+}
@@ -6,24 +6,38 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
 	"AutoParkWeb/internal/config"
 	"AutoParkWeb/internal/models"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/tracelog"
 )
 
 type PostgresDB struct {
-	Pool *pgxpool.Pool
+	Pool   *pgxpool.Pool
+	logger *slog.Logger
 }
 
-func NewPostgresDB(cfg *config.Config) (*PostgresDB, error) {
+// Подключение к PostgreSQL. При уровне логирования debug в журнал пишутся
+// все запросы с длительностью, без значений параметров.
+func NewPostgresDB(cfg *config.Config, logger *slog.Logger) (*PostgresDB, error) {
 	connString := cfg.GetPostgresConnectionString()
 	poolConfig, err := pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse connection string: %v", err)
 	}
+	if cfg.PostgresMaxConns > 0 {
+		poolConfig.MaxConns = int32(cfg.PostgresMaxConns)
+	}
+	if cfg.PostgresMinConns > 0 {
+		poolConfig.MinConns = int32(cfg.PostgresMinConns)
+	}
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		poolConfig.ConnConfig.Tracer = &tracelog.TraceLog{Logger: queryLogger(logger), LogLevel: tracelog.LogLevelInfo}
+	}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
@@ -34,22 +48,71 @@ func NewPostgresDB(cfg *config.Config) (*PostgresDB, error) {
 		return nil, fmt.Errorf("unable to ping database: %v", err)
 	}
 
-	log.Println("Successfully connected to PostgreSQL")
+	logger.Info("Connected to PostgreSQL", "host", cfg.PostgresHost, "database", cfg.PostgresDB)
+
+	return &PostgresDB{Pool: pool, logger: logger}, nil
+}
 
-	return &PostgresDB{Pool: pool}, nil
+// Запросы пишутся на уровне debug; параметры отбрасываются, чтобы в журнал
+// не попадали хеши паролей и токенов
+func queryLogger(logger *slog.Logger) tracelog.Logger {
+	return tracelog.LoggerFunc(func(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+		attrs := make([]slog.Attr, 0, len(data))
+		for key, value := range data {
+			if key == "args" {
+				continue
+			}
+			attrs = append(attrs, slog.Any(key, value))
+		}
+		logger.LogAttrs(ctx, slog.LevelDebug, "Postgres: "+msg, attrs...)
+	})
 }
 
 func (db *PostgresDB) Close() {
 	db.Pool.Close()
 }
 
+func (db *PostgresDB) Ping(ctx context.Context) error {
+	return db.Pool.Ping(ctx)
+}
+
+// Блокировка сеансовая: держится на отдельном соединении, пока выполняется fn
+func (db *PostgresDB) TryAdvisoryLock(ctx context.Context, key int64, fn func() error) (bool, error) {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			db.logger.WarnContext(ctx, "Failed to release advisory lock", "key", key, "error", err)
+		}
+	}()
+	return true, fn()
+}
+
 func (db *PostgresDB) withTransaction(ctx context.Context, fn func(tx pgx.Tx) error) error {
 	tx, err := db.Pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
-	defer tx.Rollback(ctx)
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) && ctx.Err() == nil {
+			db.logger.WarnContext(ctx, "Failed to roll back transaction", "error", err)
+		}
+	}()
 
+	if err := setAuditActor(ctx, tx); err != nil {
+		return err
+	}
 	if err := fn(tx); err != nil {
 		return err
 	}
@@ -60,13 +123,25 @@ func (db *PostgresDB) withTransaction(ctx context.Context, fn func(tx pgx.Tx) er
 	return nil
 }
 
+// Столбцы документов водителя; пустые строки хранятся как NULL
+const driverDocumentColumns = `COALESCE(phone, ''), COALESCE(license_number, ''), license_categories,
+	license_issued_at, license_expires_at, medical_expires_at`
+
+func driverDocumentFields(docs *models.DriverDocuments) []interface{} {
+	return []interface{}{
+		&docs.Phone, &docs.LicenseNumber, &docs.LicenseCategories,
+		&docs.LicenseIssuedAt, &docs.LicenseExpiresAt, &docs.MedicalExpiresAt,
+	}
+}
+
 // Методы для работы с водителями
 func (db *PostgresDB) GetDrivers(ctx context.Context) ([]models.AutoPersonal, error) {
 	var drivers []models.AutoPersonal
 
 	query := `
-		SELECT id, first_name, last_name, father_name
+		SELECT id, first_name, last_name, father_name, ` + driverDocumentColumns + `
 		FROM auto_personal
+		WHERE archived_at IS NULL
 		ORDER BY first_name ASC
 	`
 	rows, err := db.Pool.Query(ctx, query)
@@ -77,7 +152,9 @@ func (db *PostgresDB) GetDrivers(ctx context.Context) ([]models.AutoPersonal, er
 
 	for rows.Next() {
 		var driver models.AutoPersonal
-		if err := rows.Scan(&driver.ID, &driver.FirstName, &driver.LastName, &driver.FatherName); err != nil {
+		fields := append([]interface{}{&driver.ID, &driver.FirstName, &driver.LastName, &driver.FatherName},
+			driverDocumentFields(&driver.DriverDocuments)...)
+		if err := rows.Scan(fields...); err != nil {
 			return nil, fmt.Errorf("error scanning driver row: %w", err)
 		}
 		drivers = append(drivers, driver)
@@ -92,17 +169,19 @@ func (db *PostgresDB) GetDrivers(ctx context.Context) ([]models.AutoPersonal, er
 
 func (db *PostgresDB) GetDriverByID(ctx context.Context, driverID int) (*models.AutoPersonal, error) {
 	query := `
-		SELECT id, first_name, last_name, father_name
+		SELECT id, first_name, last_name, father_name, archived_at, ` + driverDocumentColumns + `
 		FROM auto_personal
 		WHERE id = $1
 	`
 	row := db.Pool.QueryRow(ctx, query, driverID)
 
 	var driver models.AutoPersonal
-	err := row.Scan(&driver.ID, &driver.FirstName, &driver.LastName, &driver.FatherName)
+	fields := append([]interface{}{&driver.ID, &driver.FirstName, &driver.LastName, &driver.FatherName, &driver.ArchivedAt},
+		driverDocumentFields(&driver.DriverDocuments)...)
+	err := row.Scan(fields...)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, errors.New("driver not found")
+			return nil, fmt.Errorf("driver %w", ErrNotFound)
 		}
 		return nil, err
 	}
@@ -110,64 +189,65 @@ func (db *PostgresDB) GetDriverByID(ctx context.Context, driverID int) (*models.
 	return &driver, nil
 }
 
-func (db *PostgresDB) AddDriver(ctx context.Context, firstName, lastName, fatherName string) error {
-	tx, err := db.Pool.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
-	}
-	defer func(tx pgx.Tx, ctx context.Context) {
-		err := tx.Rollback(ctx)
-		if err != nil {
-
-		}
-	}(tx, ctx)
-
-	query := `INSERT INTO auto_personal (first_name, last_name, father_name) VALUES ($1, $2, $3)`
-	_, err = tx.Exec(ctx, query, firstName, lastName, fatherName)
-	if err != nil {
-		return fmt.Errorf("failed to add driver: %v", err)
-	}
-
-	return tx.Commit(ctx)
-}
-
-func (db *PostgresDB) UpdateDriver(ctx context.Context, driverID int, firstName, lastName, fatherName string) error {
-	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		query := `UPDATE auto_personal SET first_name = $1, last_name = $2, father_name = $3 WHERE id = $4`
-		_, err := tx.Exec(ctx, query, firstName, lastName, fatherName, driverID)
-		if err != nil {
-			return fmt.Errorf("failed to update driver: %v", err)
+func (db *PostgresDB) AddDriver(ctx context.Context, firstName, lastName, fatherName string, docs models.DriverDocuments) (int, error) {
+	var driverID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO auto_personal (first_name, last_name, father_name, phone, license_number, license_categories,
+			                           license_issued_at, license_expires_at, medical_expires_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9)
+			RETURNING id
+		`
+		args := append([]interface{}{firstName, lastName, fatherName}, driverDocumentArgs(docs)...)
+		if err := tx.QueryRow(ctx, query, args...).Scan(&driverID); err != nil {
+			return fmt.Errorf("failed to add driver: %w", translateError(err))
 		}
 		return nil
 	})
+	return driverID, err
 }
 
-func (db *PostgresDB) DeleteDriver(ctx context.Context, driverID int) error {
+func (db *PostgresDB) UpdateDriver(ctx context.Context, driverID int, firstName, lastName, fatherName string, docs models.DriverDocuments) error {
 	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		deleteCarsQuery := `DELETE FROM auto WHERE personal_id = $1`
-		_, err := tx.Exec(ctx, deleteCarsQuery, driverID)
+		query := `
+			UPDATE auto_personal
+			SET first_name = $1, last_name = $2, father_name = $3, phone = NULLIF($4, ''), license_number = NULLIF($5, ''),
+			    license_categories = $6, license_issued_at = $7, license_expires_at = $8, medical_expires_at = $9
+			WHERE id = $10
+		`
+		args := append([]interface{}{firstName, lastName, fatherName}, driverDocumentArgs(docs)...)
+		result, err := tx.Exec(ctx, query, append(args, driverID)...)
 		if err != nil {
-			return fmt.Errorf("failed to delete cars: %v", err)
+			return fmt.Errorf("failed to update driver: %w", translateError(err))
 		}
-
-		deleteDriverQuery := `DELETE FROM auto_personal WHERE id = $1`
-		_, err = tx.Exec(ctx, deleteDriverQuery, driverID)
-		if err != nil {
-			return fmt.Errorf("failed to delete driver: %v", err)
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("driver %w", ErrNotFound)
 		}
 		return nil
 	})
 }
 
+func driverDocumentArgs(docs models.DriverDocuments) []interface{} {
+	categories := docs.LicenseCategories
+	if categories == nil {
+		categories = []string{}
+	}
+	return []interface{}{
+		docs.Phone, docs.LicenseNumber, categories,
+		docs.LicenseIssuedAt, docs.LicenseExpiresAt, docs.MedicalExpiresAt,
+	}
+}
+
 // Методы для работы с автомобилями
 func (db *PostgresDB) GetCars(ctx context.Context) ([]models.Auto, error) {
 	var cars []models.Auto
 
 	query := `
-		SELECT a.id, a.num, a.color, a.mark, a.personal_id, 
+		SELECT a.id, a.num, a.color, a.mark, a.category, a.personal_id,
 		       CONCAT(p.last_name, ' ', p.first_name, ' ', p.father_name) AS driver_name
 		FROM auto a
 		LEFT JOIN auto_personal p ON a.personal_id = p.id
+		WHERE a.archived_at IS NULL
 		ORDER BY a.num ASC
 	`
 
@@ -181,7 +261,7 @@ func (db *PostgresDB) GetCars(ctx context.Context) ([]models.Auto, error) {
 		var car models.Auto
 		var driverName string
 
-		if err := rows.Scan(&car.ID, &car.Num, &car.Color, &car.Mark, &car.PersonalID, &driverName); err != nil {
+		if err := rows.Scan(&car.ID, &car.Num, &car.Color, &car.Mark, &car.Category, &car.PersonalID, &driverName); err != nil {
 			return nil, fmt.Errorf("error scanning car row: %w", err)
 		}
 
@@ -199,7 +279,7 @@ func (db *PostgresDB) GetCars(ctx context.Context) ([]models.Auto, error) {
 
 func (db *PostgresDB) GetCarByID(ctx context.Context, carID int) (*models.Auto, string, error) {
 	query := `
-		SELECT a.id, a.num, a.color, a.mark, a.personal_id, 
+		SELECT a.id, a.num, a.color, a.mark, a.category, a.personal_id, a.archived_at,
 		       CONCAT(p.last_name, ' ', p.first_name, ' ', p.father_name) AS driver_full_name
 		FROM auto a
 		LEFT JOIN auto_personal p ON a.personal_id = p.id
@@ -209,10 +289,10 @@ func (db *PostgresDB) GetCarByID(ctx context.Context, carID int) (*models.Auto,
 
 	var car models.Auto
 	var driverFullName sql.NullString
-	err := row.Scan(&car.ID, &car.Num, &car.Color, &car.Mark, &car.PersonalID, &driverFullName)
+	err := row.Scan(&car.ID, &car.Num, &car.Color, &car.Mark, &car.Category, &car.PersonalID, &car.ArchivedAt, &driverFullName)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, "", errors.New("car not found")
+			return nil, "", fmt.Errorf("car %w", ErrNotFound)
 		}
 		return nil, "", err
 	}
@@ -224,76 +304,67 @@ func (db *PostgresDB) GetCarByID(ctx context.Context, carID int) (*models.Auto,
 	return &car, "", nil
 }
 
-func (db *PostgresDB) AddCar(ctx context.Context, num, color, mark string, personalID int) error {
-	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		query := `INSERT INTO auto (num, color, mark, personal_id) VALUES ($1, $2, $3, $4)`
-		_, err := tx.Exec(ctx, query, num, color, mark, personalID)
-		if err != nil {
-			return fmt.Errorf("failed to add car: %v", err)
+func (db *PostgresDB) AddCar(ctx context.Context, num, color, mark, category string, personalID int) (int, error) {
+	var carID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `INSERT INTO auto (num, color, mark, category, personal_id) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		if err := tx.QueryRow(ctx, query, num, color, mark, category, personalID).Scan(&carID); err != nil {
+			return fmt.Errorf("failed to add car: %w", translateError(err))
 		}
 		return nil
 	})
+	return carID, err
 }
 
-func (db *PostgresDB) UpdateCar(ctx context.Context, carID int, num, color, mark string, personalID int) error {
+func (db *PostgresDB) UpdateCar(ctx context.Context, carID int, num, color, mark, category string, personalID int) error {
 	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		query := `UPDATE auto SET num = $1, color = $2, mark = $3, personal_id = $4 WHERE id = $5`
-		_, err := tx.Exec(ctx, query, num, color, mark, personalID, carID)
+		query := `UPDATE auto SET num = $1, color = $2, mark = $3, category = $4, personal_id = $5 WHERE id = $6`
+		result, err := tx.Exec(ctx, query, num, color, mark, category, personalID, carID)
 		if err != nil {
-			return fmt.Errorf("не удалось обновить автомобиль с ID %d: %v", carID, err)
+			return fmt.Errorf("не удалось обновить автомобиль с ID %d: %w", carID, translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("car %w", ErrNotFound)
 		}
 		return nil
 	})
 }
 
-func (db *PostgresDB) DeleteCar(ctx context.Context, carID int) error {
-	var exists bool
-	checkQuery := `SELECT EXISTS (SELECT 1 FROM auto WHERE id = $1)`
-	if err := db.Pool.QueryRow(ctx, checkQuery, carID).Scan(&exists); err != nil {
-		return fmt.Errorf("failed to check if car exists: %v", err)
-	}
+// Методы для работы с маршрутами
+const routeColumns = `id, start_point, end_point, start_lat, start_lon, end_lat, end_lon,
+	distance_km::FLOAT8, planned_minutes, archived_at`
 
-	if !exists {
-		return sql.ErrNoRows
+func scanRoute(row pgx.Row, route *models.Route) error {
+	var startLat, startLon, endLat, endLon *float64
+	err := row.Scan(&route.ID, &route.StartPoint, &route.EndPoint, &startLat, &startLon, &endLat, &endLon,
+		&route.DistanceKm, &route.PlannedMinutes, &route.ArchivedAt)
+	if err != nil {
+		return err
 	}
+	route.StartCoords = geoPoint(startLat, startLon)
+	route.EndCoords = geoPoint(endLat, endLon)
+	return nil
+}
 
-	var hasJournalRecords bool
-	journalQuery := `SELECT EXISTS (SELECT 1 FROM journal WHERE auto_id = $1)`
-	if err := db.Pool.QueryRow(ctx, journalQuery, carID).Scan(&hasJournalRecords); err != nil {
-		return fmt.Errorf("failed to check journal_table records: %v", err)
+func geoPoint(lat, lon *float64) *models.GeoPoint {
+	if lat == nil || lon == nil {
+		return nil
 	}
+	return &models.GeoPoint{Lat: *lat, Lon: *lon}
+}
 
-	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		log.Printf("Attempting to delete car with ID: %d", carID)
-
-		if hasJournalRecords {
-			deleteJournalQuery := `DELETE FROM journal WHERE auto_id = $1`
-			_, err := tx.Exec(ctx, deleteJournalQuery, carID)
-			if err != nil {
-				return fmt.Errorf("failed to delete car journal_table records: %v", err)
-			}
-		}
-
-		deleteCarQuery := `DELETE FROM auto WHERE id = $1`
-		result, err := tx.Exec(ctx, deleteCarQuery, carID)
-		if err != nil {
-			return fmt.Errorf("failed to delete car: %v", err)
-		}
-
-		rowsAffected := result.RowsAffected()
-		if rowsAffected == 0 {
-			return fmt.Errorf("no car deleted, possible concurrent modification")
-		}
-
-		return nil
-	})
+// Широта и долгота точки для записи в NULL-допустимые столбцы
+func geoColumns(point *models.GeoPoint) (*float64, *float64) {
+	if point == nil {
+		return nil, nil
+	}
+	return &point.Lat, &point.Lon
 }
 
-// Методы для работы с маршрутами
 func (db *PostgresDB) GetRoutes(ctx context.Context) ([]models.Route, error) {
 	var routes []models.Route
 
-	query := "SELECT id, start_point, end_point FROM routes ORDER BY id ASC"
+	query := "SELECT " + routeColumns + " FROM routes WHERE archived_at IS NULL ORDER BY id ASC"
 	rows, err := db.Pool.Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching routes: %w", err)
@@ -302,7 +373,7 @@ func (db *PostgresDB) GetRoutes(ctx context.Context) ([]models.Route, error) {
 
 	for rows.Next() {
 		var route models.Route
-		if err := rows.Scan(&route.ID, &route.StartPoint, &route.EndPoint); err != nil {
+		if err := scanRoute(rows, &route); err != nil {
 			return nil, fmt.Errorf("error scanning route row: %w", err)
 		}
 		routes = append(routes, route)
@@ -312,91 +383,253 @@ func (db *PostgresDB) GetRoutes(ctx context.Context) ([]models.Route, error) {
 		return nil, fmt.Errorf("error iterating over rows: %w", err)
 	}
 
+	if err := db.loadRouteStops(ctx, routes); err != nil {
+		return nil, err
+	}
 	return routes, nil
 }
 
 func (db *PostgresDB) GetRouteByID(ctx context.Context, routeID int) (*models.Route, error) {
-	query := `SELECT id, start_point, end_point FROM routes WHERE id = $1`
+	query := "SELECT " + routeColumns + " FROM routes WHERE id = $1"
 	row := db.Pool.QueryRow(ctx, query, routeID)
 
 	var route models.Route
-	err := row.Scan(&route.ID, &route.StartPoint, &route.EndPoint)
+	err := scanRoute(row, &route)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, errors.New("route not found")
+			return nil, fmt.Errorf("route %w", ErrNotFound)
 		}
 		return nil, err
 	}
 
-	return &route, nil
+	routes := []models.Route{route}
+	if err := db.loadRouteStops(ctx, routes); err != nil {
+		return nil, err
+	}
+	return &routes[0], nil
 }
 
-func (db *PostgresDB) AddRoute(ctx context.Context, startPoint, endPoint string) error {
-	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		query := `INSERT INTO routes (start_point, end_point) VALUES ($1, $2)`
-		_, err := tx.Exec(ctx, query, startPoint, endPoint)
-		if err != nil {
-			return fmt.Errorf("failed to add route: %v", err)
-		}
+// Промежуточные остановки для списка маршрутов одним запросом
+func (db *PostgresDB) loadRouteStops(ctx context.Context, routes []models.Route) error {
+	if len(routes) == 0 {
 		return nil
-	})
+	}
+	index := make(map[int]int, len(routes))
+	ids := make([]int, 0, len(routes))
+	for i := range routes {
+		routes[i].Stops = []models.RouteStop{}
+		index[routes[i].ID] = i
+		ids = append(ids, routes[i].ID)
+	}
+
+	query := `SELECT route_id, position, name, lat, lon FROM route_stops WHERE route_id = ANY($1) ORDER BY route_id, position`
+	rows, err := db.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("error fetching route stops: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var routeID int
+		var stop models.RouteStop
+		var lat, lon *float64
+		if err := rows.Scan(&routeID, &stop.Position, &stop.Name, &lat, &lon); err != nil {
+			return fmt.Errorf("error scanning route stop row: %w", err)
+		}
+		stop.Coords = geoPoint(lat, lon)
+		route := &routes[index[routeID]]
+		route.Stops = append(route.Stops, stop)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return nil
 }
 
-func (db *PostgresDB) UpdateRoute(ctx context.Context, route *models.Route) error {
-	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		query := `UPDATE routes SET start_point = $1, end_point = $2 WHERE id = $3`
-		_, err := tx.Exec(ctx, query, route.StartPoint, route.EndPoint, route.ID)
+// Замена списка остановок маршрута; позиции нумеруются заново по порядку
+func replaceRouteStops(ctx context.Context, tx pgx.Tx, routeID int, stops []models.RouteStop) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM route_stops WHERE route_id = $1`, routeID); err != nil {
+		return fmt.Errorf("failed to delete route stops: %w", translateError(err))
+	}
+	for i, stop := range stops {
+		lat, lon := geoColumns(stop.Coords)
+		query := `INSERT INTO route_stops (route_id, position, name, lat, lon) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := tx.Exec(ctx, query, routeID, i+1, stop.Name, lat, lon); err != nil {
+			return fmt.Errorf("failed to add route stop: %w", translateError(err))
+		}
+	}
+	return nil
+}
+
+func (db *PostgresDB) AddRoute(ctx context.Context, route models.Route) (int, error) {
+	var routeID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		startLat, startLon := geoColumns(route.StartCoords)
+		endLat, endLon := geoColumns(route.EndCoords)
+		query := `
+			INSERT INTO routes (start_point, end_point, start_lat, start_lon, end_lat, end_lon, distance_km, planned_minutes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+		`
+		err := tx.QueryRow(ctx, query, route.StartPoint, route.EndPoint, startLat, startLon, endLat, endLon,
+			route.DistanceKm, route.PlannedMinutes).Scan(&routeID)
 		if err != nil {
-			return fmt.Errorf("failed to update route: %v", err)
+			return fmt.Errorf("failed to add route: %w", translateError(err))
 		}
-		return nil
+		return replaceRouteStops(ctx, tx, routeID, route.Stops)
 	})
+	return routeID, err
 }
 
-func (db *PostgresDB) DeleteRoute(ctx context.Context, routeID int) error {
+func (db *PostgresDB) UpdateRoute(ctx context.Context, route *models.Route) error {
 	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		query := `DELETE FROM routes WHERE id = $1`
-		_, err := tx.Exec(ctx, query, routeID)
+		startLat, startLon := geoColumns(route.StartCoords)
+		endLat, endLon := geoColumns(route.EndCoords)
+		query := `
+			UPDATE routes
+			SET start_point = $1, end_point = $2, start_lat = $3, start_lon = $4, end_lat = $5, end_lon = $6,
+			    distance_km = $7, planned_minutes = $8
+			WHERE id = $9
+		`
+		result, err := tx.Exec(ctx, query, route.StartPoint, route.EndPoint, startLat, startLon, endLat, endLon,
+			route.DistanceKm, route.PlannedMinutes, route.ID)
 		if err != nil {
-			return fmt.Errorf("failed to delete route: %v", err)
+			return fmt.Errorf("failed to update route: %w", translateError(err))
 		}
-		return nil
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("route %w", ErrNotFound)
+		}
+		return replaceRouteStops(ctx, tx, route.ID, route.Stops)
 	})
 }
 
 // Методы для работы с журналом
-func (db *PostgresDB) GetAllJournalEntries(ctx context.Context) ([]models.JournalView, error) {
-	var entries []models.JournalView
-	query := "SELECT * FROM journal_view ORDER BY journal_view.time_out"
-	rows, err := db.Pool.Query(ctx, query)
+const journalViewColumns = `journal_id, time_out, time_in, start_point, end_point, auto_number, auto_mark, driver_name, auto_id, route_id, driver_id,
+	odometer_out, odometer_in, fuel_out, fuel_in, fuel_added, planned_distance_km, planned_minutes`
+
+// Допустимые поля сортировки журнала и соответствующие им столбцы
+var journalSortColumns = map[string]string{
+	models.JournalSortTimeOut: "time_out",
+	models.JournalSortTimeIn:  "time_in",
+	models.JournalSortDriver:  "driver_name",
+	models.JournalSortAuto:    "auto_number",
+	models.JournalSortRoute:   "start_point, end_point",
+}
+
+func scanJournalEntry(row pgx.Row, entry *models.JournalView) error {
+	return row.Scan(&entry.JournalID, &entry.TimeOut, &entry.TimeIn, &entry.StartPoint, &entry.EndPoint,
+		&entry.AutoNumber, &entry.AutoMark, &entry.DriverName, &entry.AutoID, &entry.RouteID, &entry.DriverID,
+		&entry.OdometerOut, &entry.OdometerIn, &entry.FuelOut, &entry.FuelIn, &entry.FuelAdded,
+		&entry.PlannedDistance, &entry.PlannedMinutes)
+}
+
+// Построение условия WHERE по фильтру журнала
+func journalFilterClause(filter models.JournalFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.TimeOutFrom != nil {
+		add("time_out >= $%d", *filter.TimeOutFrom)
+	}
+	if filter.TimeOutTo != nil {
+		add("time_out < $%d", *filter.TimeOutTo)
+	}
+	if filter.DriverID > 0 {
+		add("driver_id = $%d", filter.DriverID)
+	}
+	if filter.AutoNumber != "" {
+		add("auto_number ILIKE '%%' || $%d || '%%'", filter.AutoNumber)
+	}
+	if filter.RouteID > 0 {
+		add("route_id = $%d", filter.RouteID)
+	}
+	switch filter.Status {
+	case models.JournalStatusInProgress:
+		conditions = append(conditions, "time_in IS NULL")
+	case models.JournalStatusCompleted:
+		conditions = append(conditions, "time_in IS NOT NULL")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (db *PostgresDB) GetJournalEntries(ctx context.Context, filter models.JournalFilter) (*models.JournalPage, error) {
+	where, args := journalFilterClause(filter)
+
+	page := &models.JournalPage{Page: filter.Page, PageSize: filter.PageSize}
+	countQuery := "SELECT COUNT(*) FROM journal_view " + where
+	if err := db.Pool.QueryRow(ctx, countQuery, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count journal_table entries: %v", err)
+	}
+
+	sortColumn, ok := journalSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = journalSortColumns[models.JournalSortTimeOut]
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+	var order []string
+	for _, column := range strings.Split(sortColumn, ", ") {
+		order = append(order, column+" "+direction)
+	}
+	order = append(order, "journal_id "+direction)
+
+	query := fmt.Sprintf("SELECT %s FROM journal_view %s ORDER BY %s", journalViewColumns, where, strings.Join(order, ", "))
+	if filter.PageSize > 0 {
+		args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := db.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all journal_table entries: %v", err)
+		return nil, fmt.Errorf("failed to get journal_table entries: %v", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var entry models.JournalView
-		if err := rows.Scan(&entry.JournalID, &entry.TimeOut, &entry.TimeIn, &entry.StartPoint, &entry.EndPoint, &entry.AutoNumber, &entry.AutoMark, &entry.DriverName); err != nil {
+		if err := scanJournalEntry(rows, &entry); err != nil {
 			return nil, err
 		}
-		entries = append(entries, entry)
+		page.Entries = append(page.Entries, entry)
 	}
-	return entries, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return page, nil
 }
 
 func (db *PostgresDB) GetJournalEntryByID(ctx context.Context, journalID int) (*models.JournalView, error) {
 	var entry models.JournalView
-	query := "SELECT * FROM journal_view WHERE journal_id = $1"
-	err := db.Pool.QueryRow(ctx, query, journalID).Scan(&entry.JournalID, &entry.TimeOut, &entry.TimeIn, &entry.StartPoint, &entry.EndPoint, &entry.AutoNumber, &entry.AutoMark, &entry.DriverName)
+	query := "SELECT " + journalViewColumns + " FROM journal_view WHERE journal_id = $1"
+	err := scanJournalEntry(db.Pool.QueryRow(ctx, query, journalID), &entry)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("journal entry %w", ErrNotFound)
+		}
 		return nil, fmt.Errorf("failed to get journal_table entry by ID: %v", err)
 	}
 	return &entry, nil
 }
 
 func (db *PostgresDB) GetAutosByDriverID(ctx context.Context, driverID int) ([]models.Auto, error) {
-	query := `SELECT id, num, color, mark, personal_id FROM auto WHERE personal_id = $1`
-
+	query := `
+		SELECT a.id, a.num, a.color, a.mark, a.category, a.personal_id
+		FROM auto a
+		JOIN auto_assignments s ON s.auto_id = a.id
+		WHERE s.personal_id = $1 AND a.archived_at IS NULL
+		  AND s.valid_from <= CURRENT_DATE AND (s.valid_to IS NULL OR s.valid_to >= CURRENT_DATE)
+		ORDER BY a.id
+	`
 	rows, err := db.Pool.Query(ctx, query, driverID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query autos for driver %d: %w", driverID, err)
@@ -411,6 +644,7 @@ func (db *PostgresDB) GetAutosByDriverID(ctx context.Context, driverID int) ([]m
 			&auto.Num,
 			&auto.Color,
 			&auto.Mark,
+			&auto.Category,
 			&auto.PersonalID,
 		)
 		if err != nil {
@@ -425,23 +659,27 @@ func (db *PostgresDB) GetAutosByDriverID(ctx context.Context, driverID int) ([]m
 	return autos, nil
 }
 
-func (db *PostgresDB) AddJournalEntry(ctx context.Context, autoID, routeID int, timeOut time.Time) error {
-	return db.withTransaction(ctx, func(tx pgx.Tx) error { // Используем pgx.Tx
-		query := `INSERT INTO journal (auto_id, route_id, time_out) VALUES ($1, $2, $3)`
-		_, err := tx.Exec(ctx, query, autoID, routeID, timeOut)
-		if err != nil {
-			return fmt.Errorf("failed to add journal_table entry: %v", err)
+func (db *PostgresDB) AddJournalEntry(ctx context.Context, autoID, driverID, routeID int, timeOut time.Time, departure models.TripDeparture) (int, error) {
+	var entryID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error { // Используем pgx.Tx
+		query := `INSERT INTO journal (auto_id, personal_id, route_id, time_out, odometer_out, fuel_out) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		if err := tx.QueryRow(ctx, query, autoID, driverID, routeID, timeOut, departure.Odometer, departure.Fuel).Scan(&entryID); err != nil {
+			return fmt.Errorf("failed to add journal_table entry: %w", translateError(err))
 		}
 		return nil
 	})
+	return entryID, err
 }
 
-func (db *PostgresDB) CompleteJournalEntry(ctx context.Context, entryID int, timeIn time.Time) error {
+func (db *PostgresDB) CompleteJournalEntry(ctx context.Context, entryID int, timeIn time.Time, arrival models.TripReturn) error {
 	return db.withTransaction(ctx, func(tx pgx.Tx) error {
-		query := `UPDATE journal SET time_in = $1 WHERE id = $2`
-		_, err := tx.Exec(ctx, query, timeIn, entryID)
+		query := `UPDATE journal SET time_in = $1, odometer_in = $2, fuel_in = $3, fuel_added = $4 WHERE id = $5`
+		result, err := tx.Exec(ctx, query, timeIn, arrival.Odometer, arrival.Fuel, arrival.FuelAdded, entryID)
 		if err != nil {
-			return fmt.Errorf("failed to update journal_table entry: %v", err)
+			return fmt.Errorf("failed to update journal_table entry: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("journal entry %w", ErrNotFound)
 		}
 		return nil
 	})
@@ -450,14 +688,45 @@ func (db *PostgresDB) CompleteJournalEntry(ctx context.Context, entryID int, tim
 func (db *PostgresDB) DeleteJournalEntry(ctx context.Context, entryID int) error {
 	return db.withTransaction(ctx, func(tx pgx.Tx) error { // Используем pgx.Tx
 		query := `DELETE FROM journal WHERE id = $1`
-		_, err := tx.Exec(ctx, query, entryID)
+		result, err := tx.Exec(ctx, query, entryID)
 		if err != nil {
-			return fmt.Errorf("failed to delete journal_table entry: %v", err)
+			return fmt.Errorf("failed to delete journal_table entry: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("journal entry %w", ErrNotFound)
 		}
 		return nil
 	})
 }
 
+func (db *PostgresDB) GetOdometerReadings(ctx context.Context, autoID int) (map[int]int, error) {
+	query := `
+		SELECT auto_id, MAX(GREATEST(odometer_out, odometer_in))
+		FROM journal
+		WHERE (odometer_out IS NOT NULL OR odometer_in IS NOT NULL)
+			AND ($1 = 0 OR auto_id = $1)
+		GROUP BY auto_id
+	`
+	rows, err := db.Pool.Query(ctx, query, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get odometer readings: %v", err)
+	}
+	defer rows.Close()
+
+	readings := make(map[int]int)
+	for rows.Next() {
+		var autoID, odometer int
+		if err := rows.Scan(&autoID, &odometer); err != nil {
+			return nil, fmt.Errorf("failed to scan odometer reading: %v", err)
+		}
+		readings[autoID] = odometer
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return readings, nil
+}
+
 // Получение количества машин на каждом маршруте
 func (db *PostgresDB) GetRoutesVehicleCount(ctx context.Context) ([]models.RouteVehicleCount, error) {
 	query := `SELECT * FROM get_routes_vehicle_count()`
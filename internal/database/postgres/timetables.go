@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Методы для работы с расписаниями маршрутов
+const timetableColumns = `t.id, t.route_id, r.start_point || ' - ' || r.end_point, t.auto_id, a.num,
+	t.personal_id, p.last_name || ' ' || p.first_name, to_char(t.departure_time, 'HH24:MI'), t.duration_minutes,
+	t.weekdays, t.valid_from, t.valid_to, t.generated_until, t.created_at`
+
+const timetableFrom = `
+	FROM route_timetables t
+	JOIN routes r ON r.id = t.route_id
+	JOIN auto a ON a.id = t.auto_id
+	JOIN auto_personal p ON p.id = t.personal_id`
+
+func scanTimetable(row pgx.Row, timetable *models.Timetable) error {
+	return row.Scan(&timetable.ID, &timetable.RouteID, &timetable.RouteName, &timetable.AutoID, &timetable.AutoNum,
+		&timetable.DriverID, &timetable.DriverName, &timetable.DepartureTime, &timetable.DurationMinutes,
+		&timetable.Weekdays, &timetable.ValidFrom, &timetable.ValidTo, &timetable.GeneratedUntil, &timetable.CreatedAt)
+}
+
+func (db *PostgresDB) GetTimetables(ctx context.Context, routeID int) ([]models.Timetable, error) {
+	query := "SELECT " + timetableColumns + timetableFrom + `
+		WHERE $1 = 0 OR t.route_id = $1
+		ORDER BY r.start_point, r.end_point, t.departure_time, t.id
+	`
+	rows, err := db.Pool.Query(ctx, query, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching timetables: %w", err)
+	}
+	defer rows.Close()
+
+	timetables := []models.Timetable{}
+	for rows.Next() {
+		var timetable models.Timetable
+		if err := scanTimetable(rows, &timetable); err != nil {
+			return nil, fmt.Errorf("error scanning timetable row: %w", err)
+		}
+		timetables = append(timetables, timetable)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return timetables, nil
+}
+
+func (db *PostgresDB) GetTimetableByID(ctx context.Context, timetableID int) (*models.Timetable, error) {
+	var timetable models.Timetable
+	query := "SELECT " + timetableColumns + timetableFrom + " WHERE t.id = $1"
+	if err := scanTimetable(db.Pool.QueryRow(ctx, query, timetableID), &timetable); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("timetable %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get timetable: %v", err)
+	}
+	return &timetable, nil
+}
+
+func (db *PostgresDB) AddTimetable(ctx context.Context, timetable models.Timetable) (int, error) {
+	var timetableID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO route_timetables (route_id, auto_id, personal_id, departure_time, duration_minutes,
+			                              weekdays, valid_from, valid_to)
+			VALUES ($1, $2, $3, $4::TIME, $5, $6, $7, $8) RETURNING id
+		`
+		err := tx.QueryRow(ctx, query, timetable.RouteID, timetable.AutoID, timetable.DriverID, timetable.DepartureTime,
+			timetable.DurationMinutes, timetable.Weekdays, timetable.ValidFrom, timetable.ValidTo).Scan(&timetableID)
+		if err != nil {
+			return fmt.Errorf("failed to add timetable: %w", translateError(err))
+		}
+		return nil
+	})
+	return timetableID, err
+}
+
+func (db *PostgresDB) DeleteTimetable(ctx context.Context, timetableID int, plansFrom time.Time) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `DELETE FROM trip_plans WHERE timetable_id = $1 AND journal_id IS NULL AND planned_out >= $2`
+		if _, err := tx.Exec(ctx, query, timetableID, plansFrom); err != nil {
+			return fmt.Errorf("failed to delete timetable plans: %w", translateError(err))
+		}
+		result, err := tx.Exec(ctx, `DELETE FROM route_timetables WHERE id = $1`, timetableID)
+		if err != nil {
+			return fmt.Errorf("failed to delete timetable: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("timetable %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) SetTimetableGeneratedUntil(ctx context.Context, timetableID int, until time.Time) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `UPDATE route_timetables SET generated_until = $1 WHERE id = $2`, until, timetableID)
+		if err != nil {
+			return fmt.Errorf("failed to update timetable: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("timetable %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) GetTimetableExceptions(ctx context.Context) ([]models.TimetableException, error) {
+	query := `SELECT id, timetable_id, exception_date, reason FROM timetable_exceptions ORDER BY exception_date, id`
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching timetable exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := []models.TimetableException{}
+	for rows.Next() {
+		var exception models.TimetableException
+		if err := rows.Scan(&exception.ID, &exception.TimetableID, &exception.Date, &exception.Reason); err != nil {
+			return nil, fmt.Errorf("error scanning timetable exception row: %w", err)
+		}
+		exceptions = append(exceptions, exception)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return exceptions, nil
+}
+
+func (db *PostgresDB) AddTimetableException(ctx context.Context, exception models.TimetableException) (int, error) {
+	var exceptionID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `INSERT INTO timetable_exceptions (timetable_id, exception_date, reason) VALUES ($1, $2, $3) RETURNING id`
+		err := tx.QueryRow(ctx, query, exception.TimetableID, exception.Date, exception.Reason).Scan(&exceptionID)
+		if err != nil {
+			return fmt.Errorf("failed to add timetable exception: %w", translateError(err))
+		}
+		return nil
+	})
+	return exceptionID, err
+}
+
+func (db *PostgresDB) DeleteTimetableException(ctx context.Context, exceptionID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM timetable_exceptions WHERE id = $1`, exceptionID)
+		if err != nil {
+			return fmt.Errorf("failed to delete timetable exception: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("timetable exception %w", ErrNotFound)
+		}
+		return nil
+	})
+}
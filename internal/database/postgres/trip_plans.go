@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Методы для работы с планами рейсов
+const tripPlanColumns = `t.id, t.auto_id, a.num, t.personal_id, p.last_name || ' ' || p.first_name,
+	t.route_id, r.start_point || ' - ' || r.end_point, t.planned_out, t.planned_in, t.notes, t.journal_id, t.timetable_id, t.created_at`
+
+const tripPlanFrom = `
+	FROM trip_plans t
+	JOIN auto a ON a.id = t.auto_id
+	JOIN auto_personal p ON p.id = t.personal_id
+	JOIN routes r ON r.id = t.route_id`
+
+func scanTripPlan(row pgx.Row, plan *models.TripPlan) error {
+	return row.Scan(&plan.ID, &plan.AutoID, &plan.AutoNum, &plan.DriverID, &plan.DriverName,
+		&plan.RouteID, &plan.RouteName, &plan.PlannedOut, &plan.PlannedIn, &plan.Notes, &plan.JournalID, &plan.TimetableID, &plan.CreatedAt)
+}
+
+func (db *PostgresDB) GetTripPlans(ctx context.Context, filter models.TripPlanFilter) ([]models.TripPlan, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.From != nil {
+		add("t.planned_out >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("t.planned_out < $%d", *filter.To)
+	}
+	if filter.AutoID > 0 {
+		add("t.auto_id = $%d", filter.AutoID)
+	}
+	if filter.DriverID > 0 {
+		add("t.personal_id = $%d", filter.DriverID)
+	}
+	if filter.TimetableID > 0 {
+		add("t.timetable_id = $%d", filter.TimetableID)
+	}
+
+	query := "SELECT " + tripPlanColumns + tripPlanFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY t.planned_out, t.id"
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching trip plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []models.TripPlan{}
+	for rows.Next() {
+		var plan models.TripPlan
+		if err := scanTripPlan(rows, &plan); err != nil {
+			return nil, fmt.Errorf("error scanning trip plan row: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return plans, nil
+}
+
+func (db *PostgresDB) GetTripPlanByID(ctx context.Context, planID int) (*models.TripPlan, error) {
+	var plan models.TripPlan
+	query := "SELECT " + tripPlanColumns + tripPlanFrom + " WHERE t.id = $1"
+	if err := scanTripPlan(db.Pool.QueryRow(ctx, query, planID), &plan); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("trip plan %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get trip plan: %v", err)
+	}
+	return &plan, nil
+}
+
+func (db *PostgresDB) AddTripPlan(ctx context.Context, plan models.TripPlan) (int, error) {
+	var planID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO trip_plans (auto_id, personal_id, route_id, planned_out, planned_in, notes, timetable_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		`
+		err := tx.QueryRow(ctx, query, plan.AutoID, plan.DriverID, plan.RouteID, plan.PlannedOut, plan.PlannedIn, plan.Notes,
+			plan.TimetableID).Scan(&planID)
+		if isUniqueViolation(err, "trip_plans_timetable_unique") {
+			return fmt.Errorf("failed to add trip plan: %w", ErrTimetablePlanExists)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add trip plan: %w", translateError(err))
+		}
+		return nil
+	})
+	return planID, err
+}
+
+func (db *PostgresDB) DeleteTripPlan(ctx context.Context, planID int) error {
+	return db.withTransaction(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `DELETE FROM trip_plans WHERE id = $1`, planID)
+		if err != nil {
+			return fmt.Errorf("failed to delete trip plan: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("trip plan %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) DispatchTripPlan(ctx context.Context, plan models.TripPlan, timeOut time.Time, departure models.TripDeparture) (int, error) {
+	var entryID int
+	err := db.withTransaction(ctx, func(tx pgx.Tx) error {
+		query := `INSERT INTO journal (auto_id, personal_id, route_id, time_out, odometer_out, fuel_out) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		err := tx.QueryRow(ctx, query, plan.AutoID, plan.DriverID, plan.RouteID, timeOut,
+			departure.Odometer, departure.Fuel).Scan(&entryID)
+		if err != nil {
+			return fmt.Errorf("failed to add journal_table entry: %w", translateError(err))
+		}
+
+		result, err := tx.Exec(ctx, `UPDATE trip_plans SET journal_id = $1 WHERE id = $2 AND journal_id IS NULL`, entryID, plan.ID)
+		if err != nil {
+			return fmt.Errorf("failed to link trip plan with journal entry: %w", translateError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("%w: план %d уже отправлен в рейс или удален", ErrConflict, plan.ID)
+		}
+		return nil
+	})
+	return entryID, err
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Метод для сохранения новой сессии
+func (db *PostgresDB) AddUserSession(ctx context.Context, session models.UserSession, tokenHash string) (*models.UserSession, error) {
+	query := `
+		INSERT INTO user_sessions (user_id, token_hash, expires_at, user_agent, remote_addr)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, last_seen_at
+	`
+	err := db.Pool.QueryRow(ctx, query, session.UserID, tokenHash, session.ExpiresAt, session.UserAgent, session.RemoteAddr).
+		Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add user session: %w", translateError(err))
+	}
+
+	return &session, nil
+}
+
+// Метод для поиска владельца действующей сессии с отметкой времени обращения
+func (db *PostgresDB) GetUserBySessionHash(ctx context.Context, tokenHash string, seenAt time.Time) (*models.User, error) {
+	query := `
+		WITH session AS (
+			UPDATE user_sessions SET last_seen_at = $2
+			WHERE token_hash = $1 AND expires_at > $2
+			RETURNING user_id
+		)
+		SELECT u.id, u.username, u.password_hash, u.role
+		FROM users u
+		JOIN session s ON s.user_id = u.id
+	`
+	var user models.User
+	err := db.Pool.QueryRow(ctx, query, tokenHash, seenAt).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user session %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user by session: %v", err)
+	}
+
+	return &user, nil
+}
+
+// Метод для получения действующих сессий пользователя или всех пользователей при userID = 0
+func (db *PostgresDB) GetUserSessions(ctx context.Context, userID int, now time.Time) ([]models.UserSession, error) {
+	query := `
+		SELECT s.id, s.user_id, u.username, s.created_at, s.last_seen_at, s.expires_at, s.user_agent, s.remote_addr
+		FROM user_sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.expires_at > $1 AND ($2 = 0 OR s.user_id = $2)
+		ORDER BY s.last_seen_at DESC
+	`
+	rows, err := db.Pool.Query(ctx, query, now, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.UserSession
+	for rows.Next() {
+		var session models.UserSession
+		if err := rows.Scan(&session.ID, &session.UserID, &session.Username, &session.CreatedAt, &session.LastSeenAt,
+			&session.ExpiresAt, &session.UserAgent, &session.RemoteAddr); err != nil {
+			return nil, fmt.Errorf("error scanning user session row: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Метод для отзыва сессии пользователя или любой сессии при userID = 0
+func (db *PostgresDB) DeleteUserSession(ctx context.Context, userID, sessionID int) error {
+	query := `DELETE FROM user_sessions WHERE id = $1 AND ($2 = 0 OR user_id = $2)`
+	result, err := db.Pool.Exec(ctx, query, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user session: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user session %w", ErrNotFound)
+	}
+	return nil
+}
+
+// Метод для завершения сессии при выходе; отсутствие сессии не ошибка
+func (db *PostgresDB) DeleteUserSessionByHash(ctx context.Context, tokenHash string) error {
+	if _, err := db.Pool.Exec(ctx, `DELETE FROM user_sessions WHERE token_hash = $1`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete user session: %v", err)
+	}
+	return nil
+}
+
+// Метод для удаления истекших сессий
+func (db *PostgresDB) DeleteExpiredUserSessions(ctx context.Context, now time.Time) (int, error) {
+	result, err := db.Pool.Exec(ctx, `DELETE FROM user_sessions WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired user sessions: %v", err)
+	}
+	return int(result.RowsAffected()), nil
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Выгрузка журнала в CSV. Разделитель «;» и BOM нужны, чтобы файл
+// корректно открывался в Excel с русской локалью.
+func WriteCSV(w io.Writer, entries []models.JournalView) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+
+	if err := writer.Write([]string{"Маршрут", "Автомобиль", "Водитель", "Время отправления", "Время прибытия", "В пути, ч"}); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		timeIn, hours := "", ""
+		if h, ok := tripHours(entry); ok {
+			timeIn = entry.TimeIn.Format("2006-01-02 15:04")
+			hours = strconv.FormatFloat(h, 'f', 2, 64)
+		}
+		record := []string{
+			routeName(entry),
+			fmt.Sprintf("%s (%s)", entry.AutoNumber, entry.AutoMark),
+			entry.DriverName,
+			entry.TimeOut.Format("2006-01-02 15:04"),
+			timeIn,
+			hours,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
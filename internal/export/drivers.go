@@ -0,0 +1,134 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"AutoParkWeb/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	driversSheet     = "Водители"
+	driverTripsSheet = "Рейсы"
+)
+
+// Выгрузка отчета по водителям в Excel: лист рейтинга и, если переданы рейсы,
+// лист с рейсами водителя
+func WriteDriverReportXLSX(w io.Writer, drivers []models.DriverPerformance, trips []models.DriverTrip) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", driversSheet); err != nil {
+		return err
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeDriversSheet(f, drivers, headerStyle); err != nil {
+		return err
+	}
+	if trips != nil {
+		if _, err := f.NewSheet(driverTripsSheet); err != nil {
+			return err
+		}
+		dateFormat := "dd.mm.yyyy hh:mm"
+		dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+		if err != nil {
+			return err
+		}
+		if err := writeDriverTripsSheet(f, trips, headerStyle, dateStyle); err != nil {
+			return err
+		}
+	}
+
+	return f.Write(w)
+}
+
+func writeDriversSheet(f *excelize.File, drivers []models.DriverPerformance, headerStyle int) error {
+	headers := []interface{}{"Место", "Водитель", "Рейсов", "Завершено", "Часов в пути", "Средний рейс, мин",
+		"Пунктуальность, %", "Простой, ч", "Загрузка, %"}
+	if err := f.SetSheetRow(driversSheet, "A1", &headers); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(driversSheet, "A1", "I1", headerStyle); err != nil {
+		return err
+	}
+
+	for i, driver := range drivers {
+		values := []interface{}{
+			driver.Rank, driver.DriverName, driver.Trips, driver.CompletedTrips, driver.TotalHours,
+			optionalCell(driver.AverageMinutes), optionalCell(driver.PunctualityPercent),
+			driver.IdleHours, optionalCell(driver.UtilisationPercent),
+		}
+		if err := f.SetSheetRow(driversSheet, fmt.Sprintf("A%d", i+2), &values); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetColWidth(driversSheet, "A", "A", 8); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(driversSheet, "B", "B", 30); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(driversSheet, "C", "I", 16); err != nil {
+		return err
+	}
+	return f.SetPanes(driversSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+func writeDriverTripsSheet(f *excelize.File, trips []models.DriverTrip, headerStyle, dateStyle int) error {
+	headers := []interface{}{"Маршрут", "Автомобиль", "Время отправления", "Время прибытия", "В пути, мин",
+		"План, мин", "Задержка, мин", "Простой перед рейсом, мин"}
+	if err := f.SetSheetRow(driverTripsSheet, "A1", &headers); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(driverTripsSheet, "A1", "H1", headerStyle); err != nil {
+		return err
+	}
+
+	for i, trip := range trips {
+		var timeIn interface{} = "В пути"
+		if trip.TimeIn != nil {
+			timeIn = *trip.TimeIn
+		}
+		var planned interface{}
+		if trip.PlannedMinutes != nil {
+			planned = *trip.PlannedMinutes
+		}
+		values := []interface{}{
+			routeName(trip.JournalView),
+			fmt.Sprintf("%s (%s)", trip.AutoNumber, trip.AutoMark),
+			trip.TimeOut, timeIn,
+			optionalCell(trip.DurationMinutes), planned,
+			optionalCell(trip.DelayMinutes), optionalCell(trip.IdleBeforeMinutes),
+		}
+		if err := f.SetSheetRow(driverTripsSheet, fmt.Sprintf("A%d", i+2), &values); err != nil {
+			return err
+		}
+	}
+	if len(trips) > 0 {
+		if err := f.SetCellStyle(driverTripsSheet, "C2", fmt.Sprintf("D%d", len(trips)+1), dateStyle); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetColWidth(driverTripsSheet, "A", "B", 30); err != nil {
+		return err
+	}
+	return f.SetColWidth(driverTripsSheet, "C", "H", 18)
+}
+
+// Пустая ячейка для неизвестного значения
+func optionalCell(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
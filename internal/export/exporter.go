@@ -0,0 +1,84 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Формат выгрузки журнала
+type Format string
+
+const (
+	FormatXLSX Format = "xlsx"
+	FormatCSV  Format = "csv"
+	FormatPDF  Format = "pdf"
+)
+
+// Формат из параметра ?format=, по умолчанию Excel
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatXLSX:
+		return FormatXLSX, nil
+	case FormatCSV, FormatPDF:
+		return Format(value), nil
+	}
+	return "", fmt.Errorf("неизвестный формат выгрузки: %s", value)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func (f Format) FileName() string {
+	return "journal." + string(f)
+}
+
+// Выгрузка журнала во все поддерживаемые форматы.
+// Шрифт для PDF загружается один раз при первой выгрузке.
+type JournalExporter struct {
+	fontPath string
+	logger   *slog.Logger
+	fontOnce sync.Once
+	font     []byte
+	fontErr  error
+}
+
+func NewJournalExporter(fontPath string, logger *slog.Logger) *JournalExporter {
+	return &JournalExporter{fontPath: fontPath, logger: logger}
+}
+
+func (e *JournalExporter) Write(w io.Writer, format Format, entries []models.JournalView) error {
+	summary := Summarize(entries)
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, entries)
+	case FormatPDF:
+		font, err := e.pdfFont()
+		if err != nil {
+			return err
+		}
+		return writePDF(w, entries, summary, font, time.Now())
+	}
+	return WriteXLSX(w, entries, summary)
+}
+
+func (e *JournalExporter) pdfFont() ([]byte, error) {
+	e.fontOnce.Do(func() {
+		e.font, e.fontErr = findFont(e.fontPath)
+		if e.fontErr == nil && e.font == nil {
+			e.logger.Warn("PDF export: no TrueType font found, Cyrillic text will be transliterated; set PDF_FONT_PATH")
+		}
+	})
+	return e.font, e.fontErr
+}
@@ -0,0 +1,267 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"github.com/go-pdf/fpdf"
+)
+
+// Альбомный A4 в пунктах
+const (
+	pageHeight   = 595.0
+	pageMargin   = 36.0
+	rowHeight    = 14.0
+	textSize     = 9.0
+	titleSize    = 14.0
+	ellipsis     = "..."
+	cellPaddingX = 3.0
+)
+
+// Шрифты с кириллицей, которые ищутся, если путь к шрифту не задан
+var systemFontPaths = []string{
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/TTF/DejaVuSans.ttf",
+	"/usr/share/fonts/truetype/liberation/LiberationSans-Regular.ttf",
+	"/usr/share/fonts/liberation/LiberationSans-Regular.ttf",
+	"/Library/Fonts/Arial.ttf",
+	"/System/Library/Fonts/Supplemental/Arial.ttf",
+	`C:\Windows\Fonts\arial.ttf`,
+}
+
+// Шрифт TrueType по явному пути или из списка системных шрифтов;
+// nil без ошибки — подходящий шрифт не найден
+func findFont(path string) ([]byte, error) {
+	if path == "" {
+		for _, candidate := range systemFontPaths {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+		if path == "" {
+			return nil, nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read font: %w", err)
+	}
+	// Шрифт проверяется один раз при загрузке, а не при каждой выгрузке
+	if err := newPDFDocument(data).pdf.Error(); err != nil {
+		return nil, fmt.Errorf("load font %s: %w", path, err)
+	}
+	return data, nil
+}
+
+type pdfColumn struct {
+	title string
+	width float64
+	right bool
+}
+
+// Документ PDF с таблицами одним шрифтом. Шрифт TrueType встраивается
+// подмножеством глифов и кириллица выводится как есть; без него используется
+// стандартный Helvetica, а кириллица транслитерируется.
+type pdfDocument struct {
+	pdf     *fpdf.Fpdf
+	unicode bool
+}
+
+func newPDFDocument(font []byte) *pdfDocument {
+	pdf := fpdf.New("L", "pt", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetCellMargin(cellPaddingX)
+	pdf.SetFillColor(224, 224, 224)
+	pdf.SetLineWidth(0.5)
+	pdf.AliasNbPages("")
+
+	d := &pdfDocument{pdf: pdf, unicode: font != nil}
+	family := "Helvetica"
+	if d.unicode {
+		family = "Sans"
+		pdf.AddUTF8FontFromBytes(family, "", font)
+	}
+	pdf.SetFont(family, "", textSize)
+	pdf.SetFooterFunc(func() {
+		pdf.SetFontSize(textSize - 1)
+		pdf.SetY(pageHeight - pageMargin/2 - rowHeight)
+		pdf.CellFormat(0, rowHeight, d.str(fmt.Sprintf("Стр. %d из {nb}", pdf.PageNo())), "", 0, "R", false, 0, "")
+		pdf.SetFontSize(textSize)
+	})
+	return d
+}
+
+// Строка в кодировке текущего шрифта
+func (d *pdfDocument) str(s string) string {
+	if d.unicode {
+		return s
+	}
+	return transliterate(s)
+}
+
+// Перенос на новую страницу, если не хватает места на height пунктов
+func (d *pdfDocument) ensureSpace(height float64) bool {
+	if d.pdf.PageNo() == 0 || d.pdf.GetY()+height > pageHeight-pageMargin-rowHeight {
+		d.pdf.AddPage()
+		return true
+	}
+	return false
+}
+
+// Обрезка строки под ширину колонки
+func (d *pdfDocument) fit(s string, maxWidth float64) string {
+	if d.pdf.GetStringWidth(d.str(s)) <= maxWidth {
+		return d.str(s)
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && d.pdf.GetStringWidth(d.str(string(runes)+ellipsis)) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return d.str(string(runes) + ellipsis)
+}
+
+// Строка таблицы; header выделяется заливкой
+func (d *pdfDocument) row(columns []pdfColumn, values []string, header bool) {
+	for i, column := range columns {
+		align := "L"
+		if column.right {
+			align = "R"
+		}
+		value := d.fit(values[i], column.width-2*cellPaddingX)
+		d.pdf.CellFormat(column.width, rowHeight, value, "B", 0, align, header, 0, "")
+	}
+	d.pdf.Ln(rowHeight)
+}
+
+// Таблица с повтором заголовка на каждой новой странице
+func (d *pdfDocument) table(columns []pdfColumn, rows [][]string) {
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.title
+	}
+	d.ensureSpace(2 * rowHeight)
+	d.row(columns, titles, true)
+	for _, values := range rows {
+		if d.ensureSpace(rowHeight) {
+			d.row(columns, titles, true)
+		}
+		d.row(columns, values, false)
+	}
+}
+
+func (d *pdfDocument) heading(s string) {
+	d.ensureSpace(titleSize + 3*rowHeight)
+	d.pdf.SetFontSize(titleSize)
+	d.pdf.CellFormat(0, titleSize+10, d.str(s), "", 1, "L", false, 0, "")
+	d.pdf.SetFontSize(textSize)
+}
+
+func (d *pdfDocument) paragraph(s string) {
+	d.ensureSpace(rowHeight)
+	d.pdf.CellFormat(0, rowHeight, d.str(s), "", 1, "L", false, 0, "")
+}
+
+// Печатная версия журнала: таблица рейсов и сводка
+func writePDF(w io.Writer, entries []models.JournalView, summary Summary, font []byte, generatedAt time.Time) error {
+	d := newPDFDocument(font)
+	d.pdf.SetCreationDate(generatedAt)
+	d.pdf.AddPage()
+
+	d.heading("Журнал рейсов")
+	d.paragraph("Сформирован: " + generatedAt.Format("02.01.2006 15:04"))
+	d.pdf.Ln(rowHeight / 2)
+
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		timeIn, hours := "В пути", ""
+		if h, ok := tripHours(entry); ok {
+			timeIn = entry.TimeIn.Format("02.01.2006 15:04")
+			hours = fmt.Sprintf("%.2f", h)
+		}
+		rows = append(rows, []string{
+			routeName(entry),
+			fmt.Sprintf("%s (%s)", entry.AutoNumber, entry.AutoMark),
+			entry.DriverName,
+			entry.TimeOut.Format("02.01.2006 15:04"),
+			timeIn,
+			hours,
+		})
+	}
+	d.table([]pdfColumn{
+		{title: "Маршрут", width: 230},
+		{title: "Автомобиль", width: 150},
+		{title: "Водитель", width: 160},
+		{title: "Отправление", width: 85},
+		{title: "Прибытие", width: 85},
+		{title: "В пути, ч", width: 60, right: true},
+	}, rows)
+
+	d.pdf.Ln(rowHeight)
+	d.heading("Сводка")
+	d.paragraph(fmt.Sprintf("Всего рейсов: %d, завершено: %d, в пути: %d", summary.TotalTrips, summary.CompletedTrips, summary.InProgressTrips))
+	d.paragraph(fmt.Sprintf("Часов в пути (завершенные рейсы): %.2f", summary.TotalHours))
+
+	summaryTable := func(title string, items []SummaryRow) {
+		d.pdf.Ln(rowHeight / 2)
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, []string{item.Name, fmt.Sprint(item.Trips), fmt.Sprint(item.CompletedTrips), fmt.Sprintf("%.2f", item.Hours)})
+		}
+		d.table([]pdfColumn{
+			{title: title, width: 400},
+			{title: "Рейсов", width: 90, right: true},
+			{title: "Завершено", width: 90, right: true},
+			{title: "Часов в пути", width: 90, right: true},
+		}, rows)
+	}
+	summaryTable("Водитель", summary.ByDriver)
+	summaryTable("Маршрут", summary.ByRoute)
+
+	return d.pdf.Output(w)
+}
+
+var cyrillicTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// Перевод строки в WinAnsi для стандартного шрифта: кириллица транслитерируется,
+// остальные символы вне Latin-1 заменяются на «?»
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		case cyrillicTranslit[r] != "" || r == 'ъ' || r == 'ь':
+			b.WriteString(cyrillicTranslit[r])
+		case cyrillicTranslit[toLowerCyrillic(r)] != "":
+			latin := cyrillicTranslit[toLowerCyrillic(r)]
+			b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+		case r == 'Ъ' || r == 'Ь':
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func toLowerCyrillic(r rune) rune {
+	switch {
+	case r >= 'А' && r <= 'Я':
+		return r + ('а' - 'А')
+	case r == 'Ё':
+		return 'ё'
+	}
+	return r
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+var (
+	pagePattern   = regexp.MustCompile(`/Type /Page\b[^s]`)
+	streamPattern = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+)
+
+// Количество страниц и распакованный текст всех потоков документа
+func parsePDF(t *testing.T, data []byte) (pages int, text string) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.HasSuffix(bytes.TrimSpace(data), []byte("%%EOF")) {
+		t.Fatalf("output is not a complete PDF file")
+	}
+	var b strings.Builder
+	for _, match := range streamPattern.FindAllSubmatch(data, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			b.Write(match[1])
+			continue
+		}
+		plain, _ := io.ReadAll(zr)
+		b.Write(plain)
+	}
+	return len(pagePattern.FindAll(data, -1)), b.String()
+}
+
+func testEntries(n int) []models.JournalView {
+	timeOut := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	entries := make([]models.JournalView, 0, n)
+	for i := 0; i < n; i++ {
+		entry := models.JournalView{
+			JournalID: i + 1, TimeOut: timeOut.Add(time.Duration(i) * time.Hour),
+			StartPoint: "Депо", EndPoint: "Вокзал (A)", AutoNumber: "А123ВС77", AutoMark: "ГАЗель",
+			DriverName: "Иван Жуков", DriverID: 1, RouteID: 1, AutoID: 1,
+		}
+		if i%2 == 0 {
+			timeIn := entry.TimeOut.Add(90 * time.Minute)
+			entry.TimeIn = &timeIn
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestWritePDFWithoutFont(t *testing.T) {
+	entries := testEntries(3)
+	var buf bytes.Buffer
+	if err := writePDF(&buf, entries, Summarize(entries), nil, time.Date(2024, 3, 2, 10, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("writePDF: %v", err)
+	}
+
+	pages, text := parsePDF(t, buf.Bytes())
+	if pages != 1 {
+		t.Errorf("pages = %d, want 1", pages)
+	}
+	for _, want := range []string{"(Zhurnal reysov)", "(Sformirovan: 02.03.2024 10:30)", "(Depo - Vokzal \\(A\\))", "(Str. 1 iz 1)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("content stream does not contain %s", want)
+		}
+	}
+}
+
+// Шрифт встраивается подмножеством глифов, а не файлом целиком
+func TestWritePDFEmbedsFontSubset(t *testing.T) {
+	font, err := findFont("")
+	if err != nil {
+		t.Fatalf("findFont: %v", err)
+	}
+	if font == nil {
+		t.Skip("no system TrueType font")
+	}
+	entries := testEntries(80)
+	var buf bytes.Buffer
+	if err := writePDF(&buf, entries, Summarize(entries), font, time.Now()); err != nil {
+		t.Fatalf("writePDF: %v", err)
+	}
+
+	pages, _ := parsePDF(t, buf.Bytes())
+	if pages < 2 {
+		t.Errorf("pages = %d, want table split over several pages", pages)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/FontFile2")) {
+		t.Errorf("TrueType font is not embedded")
+	}
+	if buf.Len() > len(font)/4 {
+		t.Errorf("PDF is %d bytes for a %d byte font, want a font subset", buf.Len(), len(font))
+	}
+}
+
+func TestFindFontRejectsCorruptedFont(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.ttf")
+	if err := os.WriteFile(path, []byte("not a font"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := findFont(path); err == nil {
+		t.Errorf("findFont(corrupted) succeeded, want error")
+	}
+	if _, err := findFont(filepath.Join(t.TempDir(), "missing.ttf")); err == nil {
+		t.Errorf("findFont(missing) succeeded, want error")
+	}
+}
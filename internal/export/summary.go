@@ -0,0 +1,88 @@
+package export
+
+import (
+	"sort"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Сводка по выгружаемым рейсам
+type Summary struct {
+	TotalTrips      int
+	CompletedTrips  int
+	InProgressTrips int
+	TotalHours      float64
+	ByDriver        []SummaryRow
+	ByRoute         []SummaryRow
+}
+
+type SummaryRow struct {
+	Name           string
+	Trips          int
+	CompletedTrips int
+	Hours          float64
+}
+
+// Длительность завершенного рейса в часах
+func tripHours(entry models.JournalView) (float64, bool) {
+	if entry.TimeIn == nil {
+		return 0, false
+	}
+	return entry.TimeIn.Sub(entry.TimeOut).Hours(), true
+}
+
+func routeName(entry models.JournalView) string {
+	return entry.StartPoint + " - " + entry.EndPoint
+}
+
+// Подсчет рейсов и часов в пути по водителям и маршрутам
+func Summarize(entries []models.JournalView) Summary {
+	var summary Summary
+	byDriver := make(map[string]*SummaryRow)
+	byRoute := make(map[string]*SummaryRow)
+
+	add := func(rows map[string]*SummaryRow, name string, hours float64, completed bool) {
+		row, ok := rows[name]
+		if !ok {
+			row = &SummaryRow{Name: name}
+			rows[name] = row
+		}
+		row.Trips++
+		if completed {
+			row.CompletedTrips++
+			row.Hours += hours
+		}
+	}
+
+	for _, entry := range entries {
+		summary.TotalTrips++
+		hours, completed := tripHours(entry)
+		if completed {
+			summary.CompletedTrips++
+			summary.TotalHours += hours
+		} else {
+			summary.InProgressTrips++
+		}
+		add(byDriver, entry.DriverName, hours, completed)
+		add(byRoute, routeName(entry), hours, completed)
+	}
+
+	summary.ByDriver = sortedRows(byDriver)
+	summary.ByRoute = sortedRows(byRoute)
+	return summary
+}
+
+// Строки сводки по убыванию числа рейсов
+func sortedRows(rows map[string]*SummaryRow) []SummaryRow {
+	result := make([]SummaryRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Trips != result[j].Trips {
+			return result[i].Trips > result[j].Trips
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,159 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"AutoParkWeb/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	journalSheet = "Журнал"
+	summarySheet = "Сводка"
+)
+
+// Выгрузка журнала в Excel: лист с рейсами и лист со сводкой
+func WriteXLSX(w io.Writer, entries []models.JournalView, summary Summary) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", journalSheet); err != nil {
+		return err
+	}
+	if _, err := f.NewSheet(summarySheet); err != nil {
+		return err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+	dateFormat := "dd.mm.yyyy hh:mm"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return err
+	}
+	hoursFormat := "0.00"
+	hoursStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &hoursFormat})
+	if err != nil {
+		return err
+	}
+
+	if err := writeJournalSheet(f, entries, headerStyle, dateStyle, hoursStyle); err != nil {
+		return err
+	}
+	if err := writeSummarySheet(f, summary, headerStyle, hoursStyle); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
+
+func writeJournalSheet(f *excelize.File, entries []models.JournalView, headerStyle, dateStyle, hoursStyle int) error {
+	headers := []interface{}{"Маршрут", "Автомобиль", "Водитель", "Время отправления", "Время прибытия", "В пути, ч"}
+	if err := f.SetSheetRow(journalSheet, "A1", &headers); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(journalSheet, "A1", "F1", headerStyle); err != nil {
+		return err
+	}
+
+	for i, entry := range entries {
+		row := i + 2
+		values := []interface{}{
+			routeName(entry),
+			fmt.Sprintf("%s (%s)", entry.AutoNumber, entry.AutoMark),
+			entry.DriverName,
+			entry.TimeOut,
+		}
+		if hours, ok := tripHours(entry); ok {
+			values = append(values, *entry.TimeIn, hours)
+		} else {
+			values = append(values, "В пути", nil)
+		}
+		if err := f.SetSheetRow(journalSheet, fmt.Sprintf("A%d", row), &values); err != nil {
+			return err
+		}
+	}
+
+	if len(entries) > 0 {
+		last := len(entries) + 1
+		if err := f.SetCellStyle(journalSheet, "D2", fmt.Sprintf("E%d", last), dateStyle); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(journalSheet, "F2", fmt.Sprintf("F%d", last), hoursStyle); err != nil {
+			return err
+		}
+		if err := f.AutoFilter(journalSheet, fmt.Sprintf("A1:F%d", last), nil); err != nil {
+			return err
+		}
+	}
+
+	for col, width := range map[string]float64{"A": 35, "B": 22, "C": 25, "D": 18, "E": 18, "F": 10} {
+		if err := f.SetColWidth(journalSheet, col, col, width); err != nil {
+			return err
+		}
+	}
+
+	return f.SetPanes(journalSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+func writeSummarySheet(f *excelize.File, summary Summary, headerStyle, hoursStyle int) error {
+	row := 1
+	setRow := func(values ...interface{}) error {
+		err := f.SetSheetRow(summarySheet, fmt.Sprintf("A%d", row), &values)
+		row++
+		return err
+	}
+
+	totals := [][]interface{}{
+		{"Всего рейсов", summary.TotalTrips},
+		{"Завершено", summary.CompletedTrips},
+		{"В пути", summary.InProgressTrips},
+		{"Часов в пути (завершенные рейсы)", summary.TotalHours},
+	}
+	for _, total := range totals {
+		if err := setRow(total...); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(summarySheet, fmt.Sprintf("B%d", row-1), fmt.Sprintf("B%d", row-1), hoursStyle); err != nil {
+		return err
+	}
+
+	writeTable := func(title string, rows []SummaryRow) error {
+		row++
+		if err := setRow(title, "Рейсов", "Завершено", "Часов в пути"); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(summarySheet, fmt.Sprintf("A%d", row-1), fmt.Sprintf("D%d", row-1), headerStyle); err != nil {
+			return err
+		}
+		first := row
+		for _, r := range rows {
+			if err := setRow(r.Name, r.Trips, r.CompletedTrips, r.Hours); err != nil {
+				return err
+			}
+		}
+		if len(rows) > 0 {
+			return f.SetCellStyle(summarySheet, fmt.Sprintf("D%d", first), fmt.Sprintf("D%d", row-1), hoursStyle)
+		}
+		return nil
+	}
+
+	if err := writeTable("Водитель", summary.ByDriver); err != nil {
+		return err
+	}
+	if err := writeTable("Маршрут", summary.ByRoute); err != nil {
+		return err
+	}
+
+	if err := f.SetColWidth(summarySheet, "A", "A", 35); err != nil {
+		return err
+	}
+	return f.SetColWidth(summarySheet, "B", "D", 14)
+}
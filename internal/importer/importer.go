@@ -0,0 +1,223 @@
+// Пакет importer разбирает файлы XLSX и CSV со справочниками автопарка
+// (водители, автомобили, маршруты) в пакет для проверки и импорта.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"AutoParkWeb/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// Вид справочника в файле
+type Kind string
+
+const (
+	KindDrivers Kind = "drivers"
+	KindAutos   Kind = "autos"
+	KindRoutes  Kind = "routes"
+)
+
+// Ошибка формата файла; ошибки в данных отдельных строк сюда не относятся
+type FileError struct {
+	Message string
+}
+
+func (e *FileError) Error() string {
+	return e.Message
+}
+
+func invalidFile(format string, args ...interface{}) error {
+	return &FileError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Названия листов книги Excel для каждого справочника
+var sheetNames = map[string]Kind{
+	"водители":   KindDrivers,
+	"drivers":    KindDrivers,
+	"автомобили": KindAutos,
+	"autos":      KindAutos,
+	"cars":       KindAutos,
+	"маршруты":   KindRoutes,
+	"routes":     KindRoutes,
+}
+
+// Заголовки колонок: допускаются русские названия, как в интерфейсе, и имена полей API
+var columnNames = map[Kind]map[string]string{
+	KindDrivers: {
+		"имя": "first_name", "first_name": "first_name",
+		"фамилия": "last_name", "last_name": "last_name",
+		"отчество": "father_name", "father_name": "father_name",
+	},
+	KindAutos: {
+		"номер": "num", "госномер": "num", "num": "num",
+		"цвет": "color", "color": "color",
+		"марка": "mark", "mark": "mark",
+		"водитель": "driver", "driver": "driver",
+	},
+	KindRoutes: {
+		"начальный пункт": "start_point", "откуда": "start_point", "start_point": "start_point",
+		"конечный пункт": "end_point", "куда": "end_point", "end_point": "end_point",
+	},
+}
+
+var requiredColumns = map[Kind][]string{
+	KindDrivers: {"first_name", "last_name"},
+	KindAutos:   {"num", "color", "mark", "driver"},
+	KindRoutes:  {"start_point", "end_point"},
+}
+
+func ParseKind(value string) (Kind, error) {
+	switch Kind(value) {
+	case KindDrivers, KindAutos, KindRoutes:
+		return Kind(value), nil
+	case "":
+		return "", nil
+	}
+	return "", invalidFile("неизвестный справочник: %s", value)
+}
+
+// Разбор файла по расширению. Для CSV обязателен kind, для XLSX справочник
+// определяется по названию листа, а kind используется, если листы не названы.
+func Parse(fileName string, data []byte, kind Kind) (*models.ImportBatch, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xlsx":
+		return ParseXLSX(bytes.NewReader(data), kind)
+	case ".csv":
+		if kind == "" {
+			return nil, invalidFile("для CSV-файла нужно указать справочник")
+		}
+		return ParseCSV(bytes.NewReader(data), kind)
+	}
+	return nil, invalidFile("поддерживаются только файлы .xlsx и .csv")
+}
+
+func ParseXLSX(r io.Reader, kind Kind) (*models.ImportBatch, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, invalidFile("не удалось прочитать файл Excel: %v", err)
+	}
+	defer f.Close()
+
+	batch := &models.ImportBatch{}
+	sheets := f.GetSheetList()
+	found := false
+	for _, sheet := range sheets {
+		sheetKind, ok := sheetNames[strings.ToLower(strings.TrimSpace(sheet))]
+		if !ok {
+			continue
+		}
+		found = true
+		if err := parseSheet(f, sheet, sheetKind, batch); err != nil {
+			return nil, err
+		}
+	}
+
+	if !found {
+		if kind == "" || len(sheets) == 0 {
+			return nil, invalidFile("в книге нет листов «Водители», «Автомобили» или «Маршруты»")
+		}
+		if err := parseSheet(f, sheets[0], kind, batch); err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
+
+func parseSheet(f *excelize.File, sheet string, kind Kind, batch *models.ImportBatch) error {
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return invalidFile("не удалось прочитать лист %s: %v", sheet, err)
+	}
+	if err := addRows(batch, kind, rows); err != nil {
+		var fileErr *FileError
+		if errors.As(err, &fileErr) {
+			return invalidFile("лист %s: %s", sheet, fileErr.Message)
+		}
+		return err
+	}
+	return nil
+}
+
+// CSV с разделителем «,» или «;» (как сохраняет Excel с русской локалью)
+func ParseCSV(r io.Reader, kind Kind) (*models.ImportBatch, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, invalidFile("не удалось прочитать CSV: %v", err)
+	}
+
+	batch := &models.ImportBatch{}
+	if err := addRows(batch, kind, rows); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// Строки таблицы с заголовком в первой строке; пустые строки пропускаются
+func addRows(batch *models.ImportBatch, kind Kind, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	columns := make(map[string]int)
+	for i, title := range rows[0] {
+		if field, ok := columnNames[kind][strings.ToLower(strings.TrimSpace(title))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range requiredColumns[kind] {
+		if _, ok := columns[field]; !ok {
+			return invalidFile("нет обязательной колонки %s", field)
+		}
+	}
+
+	for i, row := range rows[1:] {
+		value := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		number := i + 2
+		switch kind {
+		case KindDrivers:
+			batch.Drivers = append(batch.Drivers, models.ImportDriver{
+				Row: number, FirstName: value("first_name"), LastName: value("last_name"), FatherName: value("father_name"),
+			})
+		case KindAutos:
+			batch.Autos = append(batch.Autos, models.ImportAuto{
+				Row: number, Num: value("num"), Color: value("color"), Mark: value("mark"), DriverName: value("driver"),
+			})
+		case KindRoutes:
+			batch.Routes = append(batch.Routes, models.ImportRoute{
+				Row: number, StartPoint: value("start_point"), EndPoint: value("end_point"),
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"AutoParkWeb/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// CSV из Excel с русской локалью: BOM, разделитель «;», русские заголовки
+// в любом порядке; пустые строки пропускаются, но номера строк сохраняются
+func TestParseCSV(t *testing.T) {
+	data := "\ufeffГосномер;Водитель;Марка;Цвет\nА123ВС77; Иван Иванов ;ГАЗель;Белый\n;;;\nВ456ОР77;Петр Петров;Ford\n"
+	batch, err := Parse("autos.csv", []byte(data), KindAutos)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []models.ImportAuto{
+		{Row: 2, Num: "А123ВС77", Color: "Белый", Mark: "ГАЗель", DriverName: "Иван Иванов"},
+		{Row: 4, Num: "В456ОР77", Mark: "Ford", DriverName: "Петр Петров"},
+	}
+	if !reflect.DeepEqual(batch.Autos, want) {
+		t.Errorf("autos = %+v, want %+v", batch.Autos, want)
+	}
+}
+
+// Справочник определяется по названию листа книги
+func TestParseXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetName("Sheet1", "Водители")
+	f.SetSheetRow("Водители", "A1", &[]string{"Фамилия", "Имя", "Отчество"})
+	f.SetSheetRow("Водители", "A2", &[]string{"Иванов", "Иван", "Иванович"})
+	f.NewSheet("Routes")
+	f.SetSheetRow("Routes", "A1", &[]string{"start_point", "end_point"})
+	f.SetSheetRow("Routes", "A2", &[]string{"Депо", "Вокзал"})
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := Parse("import.XLSX", buf.Bytes(), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := &models.ImportBatch{
+		Drivers: []models.ImportDriver{{Row: 2, FirstName: "Иван", LastName: "Иванов", FatherName: "Иванович"}},
+		Routes:  []models.ImportRoute{{Row: 2, StartPoint: "Депо", EndPoint: "Вокзал"}},
+	}
+	if !reflect.DeepEqual(batch, want) {
+		t.Errorf("batch = %+v, want %+v", batch, want)
+	}
+}
+
+func TestParseRejectsInvalidFile(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		fileName string
+		data     string
+		kind     Kind
+	}{
+		{"unknown extension", "drivers.txt", "first_name,last_name\n", KindDrivers},
+		{"csv without kind", "drivers.csv", "first_name,last_name\n", ""},
+		{"missing column", "drivers.csv", "first_name,father_name\nИван,Иванович\n", KindDrivers},
+		{"broken workbook", "drivers.xlsx", "not a workbook", KindDrivers},
+	} {
+		var fileErr *FileError
+		if _, err := Parse(tc.fileName, []byte(tc.data), tc.kind); !errors.As(err, &fileErr) {
+			t.Errorf("%s: got %v, want FileError", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// Форматы логов
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Логгер с заданными уровнем и форматом. Записи, сделанные с контекстом
+// запроса (InfoContext, ErrorContext и т.д.), получают поле request_id.
+func New(w io.Writer, level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: parseLevel(level)}
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+func parseLevel(level string) slog.Level {
+	var result slog.Level
+	if err := result.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return result
+}
+
+// Добавляет к записи идентификатор запроса из контекста
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Сведения о запросе, общие для всех middleware. Пользователь становится
+// известен только после аутентификации, поэтому хранится по указателю.
+type requestInfo struct {
+	id   string
+	user string
+}
+
+type requestInfoKey struct{}
+
+func withRequestInfo(ctx context.Context, info *requestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+func requestInfoFrom(ctx context.Context) *requestInfo {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(requestInfoKey{}).(*requestInfo)
+	return info
+}
+
+// Идентификатор текущего запроса; пустая строка вне HTTP-запроса
+func RequestID(ctx context.Context) string {
+	if info := requestInfoFrom(ctx); info != nil {
+		return info.id
+	}
+	return ""
+}
+
+// Запоминает пользователя запроса для журнала доступа
+func SetUser(ctx context.Context, username string) {
+	if info := requestInfoFrom(ctx); info != nil {
+		info.user = username
+	}
+}
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Заголовок с идентификатором запроса: принимается от прокси и возвращается клиенту
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 64
+
+// Middleware для router.Use: присваивает запросу идентификатор и после ответа
+// пишет в журнал метод, шаблон маршрута, статус, длительность и пользователя.
+// Идентификатор возвращается клиенту в заголовке X-Request-ID, чтобы по нему
+// можно было найти запись в логах. Запросы к quietRoutes (проверки живости,
+// метрики) пишутся на уровне debug.
+func Middleware(logger *slog.Logger, quietRoutes ...string) mux.MiddlewareFunc {
+	quiet := make(map[string]bool, len(quietRoutes))
+	for _, route := range quietRoutes {
+		quiet[route] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info := &requestInfo{id: requestID(r)}
+			w.Header().Set(RequestIDHeader, info.id)
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			ctx := withRequestInfo(r.Context(), info)
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			route := "unknown"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+			level := slog.LevelInfo
+			switch {
+			case recorder.status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case quiet[route]:
+				level = slog.LevelDebug
+			}
+			logger.LogAttrs(ctx, level, "http request",
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", recorder.status),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("user", info.user),
+			)
+		})
+	}
+}
+
+// Идентификатор из заголовка запроса, если он разумной длины и без
+// посторонних символов, иначе новый случайный
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id
+	}
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Потоковые ответы: Flush передается исходному ResponseWriter
+func (r *statusRecorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Для http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// Записи журнала в формате JSON, по одной на строку
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Идентификатор запроса доходит до обработчика, его записей в журнале,
+// записи журнала доступа и заголовка ответа
+func TestMiddlewareRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+	for _, tc := range []struct {
+		name     string
+		incoming string
+		want     *regexp.Regexp
+	}{
+		{"id from proxy", "edge-42.a_b", regexp.MustCompile(`^edge-42\.a_b$`)},
+		{"no id", "", generated},
+		{"unsafe id", "id\nforged=1", generated},
+		{"too long id", strings.Repeat("a", maxRequestIDLength+1), generated},
+	} {
+		var buf bytes.Buffer
+		logger := New(&buf, "debug", FormatJSON)
+		var handlerID string
+		router := mux.NewRouter()
+		router.Use(Middleware(logger))
+		router.HandleFunc("/journal/{id}", func(w http.ResponseWriter, r *http.Request) {
+			handlerID = RequestID(r.Context())
+			SetUser(r.Context(), "admin")
+			logger.InfoContext(r.Context(), "handler")
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/journal/7", nil)
+		if tc.incoming != "" {
+			req.Header.Set(RequestIDHeader, tc.incoming)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		id := rec.Header().Get(RequestIDHeader)
+		if !tc.want.MatchString(id) || handlerID != id {
+			t.Errorf("%s: response id %q, handler id %q, want %s", tc.name, id, handlerID, tc.want)
+			continue
+		}
+		records := logRecords(t, &buf)
+		if len(records) != 2 {
+			t.Fatalf("%s: %d log records, want handler and access log", tc.name, len(records))
+		}
+		for _, record := range records {
+			if record["request_id"] != id {
+				t.Errorf("%s: %q record request_id = %v, want %q", tc.name, record["msg"], record["request_id"], id)
+			}
+		}
+		access := records[1]
+		if access["route"] != "/journal/{id}" || access["status"] != float64(http.StatusCreated) || access["user"] != "admin" || access["level"] != "INFO" {
+			t.Errorf("%s: access log = %v", tc.name, access)
+		}
+	}
+}
+
+// Ошибки сервера пишутся на уровне error, служебные маршруты — на уровне debug
+func TestMiddlewareLevels(t *testing.T) {
+	var buf bytes.Buffer
+	router := mux.NewRouter()
+	router.Use(Middleware(New(&buf, "debug", FormatJSON), "/healthz"))
+	router.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "fail", http.StatusInternalServerError)
+	})
+
+	for _, path := range []string{"/healthz", "/fail"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	records := logRecords(t, &buf)
+	if len(records) != 2 || records[0]["level"] != "DEBUG" || records[1]["level"] != "ERROR" {
+		t.Errorf("access log levels = %v, want DEBUG for /healthz and ERROR for /fail", records)
+	}
+}
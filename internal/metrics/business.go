@@ -0,0 +1,46 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Счетчики бизнес-событий автопарка; реализует services.EventRecorder.
+// Рейсы в час — increase(autopark_trips_started_total[1h]).
+type Business struct {
+	tripsStarted     prometheus.Counter
+	tripsCompleted   prometheus.Counter
+	dispatchRejected *prometheus.CounterVec
+}
+
+func NewBusiness(r prometheus.Registerer) (*Business, error) {
+	b := &Business{
+		tripsStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "autopark_trips_started_total",
+			Help: "Отправленные в рейс автомобили",
+		}),
+		tripsCompleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "autopark_trips_completed_total",
+			Help: "Завершенные рейсы",
+		}),
+		dispatchRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "autopark_dispatch_rejected_total",
+			Help: "Отклоненные попытки отправки в рейс: check — проверки сервиса, database — триггеры и ограничения базы",
+		}, []string{"stage"}),
+	}
+	for _, collector := range []prometheus.Collector{b.tripsStarted, b.tripsCompleted, b.dispatchRejected} {
+		if err := r.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (b *Business) TripStarted() {
+	b.tripsStarted.Inc()
+}
+
+func (b *Business) TripCompleted() {
+	b.tripsCompleted.Inc()
+}
+
+func (b *Business) DispatchRejected(stage string) {
+	b.dispatchRejected.WithLabelValues(stage).Inc()
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Сколько ждать значения показателя из базы данных при опросе
+const cachedGaugeTimeout = 2 * time.Second
+
+// Показатель, который вычисляется запросом к базе данных. Значение
+// кешируется на ttl, чтобы частые опросы Prometheus с нескольких серверов
+// не нагружали базу. Если обновить значение не удалось, выдается
+// последнее известное; до первого успешного запроса показатель не выдается.
+type cachedGauge struct {
+	name   string
+	desc   *prometheus.Desc
+	fn     func(ctx context.Context) (float64, error)
+	ttl    time.Duration
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	value     float64
+	known     bool
+	updatedAt time.Time
+}
+
+func RegisterCachedGauge(r prometheus.Registerer, logger *slog.Logger, name, help string, ttl time.Duration,
+	fn func(ctx context.Context) (float64, error)) error {
+	return r.Register(&cachedGauge{name: name, desc: prometheus.NewDesc(name, help, nil, nil), fn: fn, ttl: ttl, logger: logger})
+}
+
+func (g *cachedGauge) Describe(ch chan<- *prometheus.Desc) {
+	ch <- g.desc
+}
+
+func (g *cachedGauge) Collect(ch chan<- prometheus.Metric) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if time.Since(g.updatedAt) >= g.ttl {
+		ctx, cancel := context.WithTimeout(context.Background(), cachedGaugeTimeout)
+		value, err := g.fn(ctx)
+		cancel()
+		// Следующая попытка после ошибки тоже не раньше чем через ttl
+		g.updatedAt = time.Now()
+		if err != nil {
+			g.logger.Warn("Metrics: failed to update gauge", "metric", g.name, "error", err)
+		} else {
+			g.value, g.known = value, true
+		}
+	}
+	if g.known {
+		ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, g.value)
+	}
+}
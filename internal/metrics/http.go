@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Метрики HTTP-запросов в разрезе шаблонов маршрутов mux, чтобы идентификаторы
+// в пути не порождали отдельные ряды
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	route    promhttp.Option
+}
+
+type routeKey struct{}
+
+func NewHTTPMetrics(r prometheus.Registerer) (*HTTPMetrics, error) {
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Количество HTTP-запросов",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Длительность обработки HTTP-запросов в секундах",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		route: promhttp.WithLabelFromCtx("route", func(ctx context.Context) string {
+			route, _ := ctx.Value(routeKey{}).(string)
+			return route
+		}),
+	}
+	for _, collector := range []prometheus.Collector{m.requests, m.duration} {
+		if err := r.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Middleware для router.Use: вызывается после сопоставления маршрута.
+// Статус и длительность снимает promhttp, его обертка ResponseWriter
+// сохраняет http.Flusher и другие интерфейсы исходного ответа.
+func (m *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	instrumented := promhttp.InstrumentHandlerDuration(m.duration,
+		promhttp.InstrumentHandlerCounter(m.requests, next, m.route), m.route)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		instrumented.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, route)))
+	})
+}
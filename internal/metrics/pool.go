@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Статистика пула соединений PostgreSQL из pgxpool.Stat, снимается при каждом опросе
+type poolCollector struct {
+	pool    *pgxpool.Pool
+	metrics []poolMetric
+}
+
+type poolMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(*pgxpool.Stat) float64
+}
+
+func RegisterPoolStats(r prometheus.Registerer, pool *pgxpool.Pool) error {
+	gauge := func(name, help string, value func(*pgxpool.Stat) float64) poolMetric {
+		return poolMetric{prometheus.NewDesc(name, help, nil, nil), prometheus.GaugeValue, value}
+	}
+	counter := func(name, help string, value func(*pgxpool.Stat) float64) poolMetric {
+		return poolMetric{prometheus.NewDesc(name, help, nil, nil), prometheus.CounterValue, value}
+	}
+
+	return r.Register(&poolCollector{pool: pool, metrics: []poolMetric{
+		gauge("pgxpool_acquired_conns", "Соединения, выданные из пула",
+			func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }),
+		gauge("pgxpool_idle_conns", "Свободные соединения в пуле",
+			func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }),
+		gauge("pgxpool_constructing_conns", "Соединения, которые устанавливаются",
+			func(s *pgxpool.Stat) float64 { return float64(s.ConstructingConns()) }),
+		gauge("pgxpool_total_conns", "Всего соединений в пуле",
+			func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }),
+		gauge("pgxpool_max_conns", "Максимальный размер пула",
+			func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }),
+
+		counter("pgxpool_acquire_total", "Успешные получения соединения из пула",
+			func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }),
+		counter("pgxpool_acquire_duration_seconds_total", "Суммарное время ожидания соединения в секундах",
+			func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }),
+		counter("pgxpool_empty_acquire_total", "Получения соединения, которым пришлось ждать свободного",
+			func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }),
+		counter("pgxpool_canceled_acquire_total", "Получения соединения, отмененные контекстом",
+			func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) }),
+		counter("pgxpool_new_conns_total", "Новые соединения с базой данных",
+			func(s *pgxpool.Stat) float64 { return float64(s.NewConnsCount()) }),
+	}})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		ch <- m.desc
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	for _, m := range c.metrics {
+		ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.value(stat))
+	}
+}
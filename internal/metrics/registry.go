@@ -0,0 +1,46 @@
+// Пакет metrics собирает метрики приложения на основе клиента Prometheus:
+// HTTP-запросы, пул соединений PostgreSQL, бизнес-показатели автопарка,
+// а также стандартные метрики Go-рантайма и процесса.
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Реестр метрик приложения; HTTP — метрики запросов для middleware роутера
+type Registry struct {
+	*prometheus.Registry
+	HTTP   *HTTPMetrics
+	logger *slog.Logger
+}
+
+func NewRegistry(logger *slog.Logger) (*Registry, error) {
+	r := &Registry{Registry: prometheus.NewRegistry(), logger: logger}
+	if err := r.Register(collectors.NewGoCollector()); err != nil {
+		return nil, err
+	}
+	if err := r.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return nil, err
+	}
+
+	httpMetrics, err := NewHTTPMetrics(r)
+	if err != nil {
+		return nil, err
+	}
+	r.HTTP = httpMetrics
+	return r, nil
+}
+
+// Обработчик /metrics. Ошибка одного сборщика не мешает выдаче остальных
+// метрик и пишется в лог.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.Registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(r.logger.Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
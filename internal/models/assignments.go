@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Закрепление водителя за автомобилем. Даты хранятся без времени;
+// ValidTo — последний день действия включительно, nil — бессрочно.
+type Assignment struct {
+	ID         int        `db:"id" json:"id"`
+	AutoID     int        `db:"auto_id" json:"auto_id"`
+	AutoNum    string     `db:"auto_num" json:"auto_num"`
+	DriverID   int        `db:"personal_id" json:"driver_id"`
+	DriverName string     `db:"driver_name" json:"driver_name"`
+	ValidFrom  time.Time  `db:"valid_from" json:"valid_from"`
+	ValidTo    *time.Time `db:"valid_to" json:"valid_to"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Закрепление действует в день at (по календарной дате at)
+func (a Assignment) ActiveOn(at time.Time) bool {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	return !a.ValidFrom.After(day) && (a.ValidTo == nil || !a.ValidTo.Before(day))
+}
+
+// Параметры выборки закреплений; нулевые поля не ограничивают выборку.
+// ActiveOn оставляет только закрепления, действующие в этот день.
+type AssignmentFilter struct {
+	AutoID   int
+	DriverID int
+	ActiveOn *time.Time
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия, которые фиксируются в журнале аудита
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Типы сущностей в журнале аудита
+const (
+	AuditEntityDriver             = "driver"
+	AuditEntityAuto               = "auto"
+	AuditEntityRoute              = "route"
+	AuditEntityJournal            = "journal"
+	AuditEntityAssignment         = "assignment"
+	AuditEntityMaintenance        = "maintenance"
+	AuditEntitySchedule           = "maintenance_schedule"
+	AuditEntityAutoDocument       = "auto_document"
+	AuditEntityAutoDocumentFile   = "auto_document_file"
+	AuditEntityTripPlan           = "trip_plan"
+	AuditEntityTimetable          = "timetable"
+	AuditEntityTimetableException = "timetable_exception"
+	AuditEntityRouteStop          = "route_stop"
+)
+
+// Запись журнала аудита. Before и After — снимки строки таблицы до и после
+// изменения; UserID пуст для изменений, сделанных не из запроса пользователя.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	UserID     *int            `json:"user_id"`
+	Username   string          `json:"username"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Параметры выборки журнала аудита; интервал по времени полуоткрытый, как в JournalFilter
+type AuditFilter struct {
+	From       *time.Time
+	To         *time.Time
+	UserID     int
+	Action     string
+	EntityType string
+	EntityID   int
+	Page       int
+	PageSize   int
+}
+
+type AuditPage struct {
+	Entries  []AuditEntry `json:"entries"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
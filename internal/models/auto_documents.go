@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Виды документов автомобиля
+const (
+	AutoDocumentInsurance    = "insurance"
+	AutoDocumentRegistration = "registration"
+	AutoDocumentInspection   = "inspection"
+)
+
+// Документ автомобиля. Даты хранятся без времени; ExpiresAt = nil — бессрочный.
+type AutoDocument struct {
+	ID        int                `db:"id" json:"id"`
+	AutoID    int                `db:"auto_id" json:"auto_id"`
+	AutoNum   string             `db:"auto_num" json:"auto_num"`
+	Type      string             `db:"type" json:"type"`
+	Number    string             `db:"number" json:"number"`
+	IssuedAt  *time.Time         `db:"issued_at" json:"issued_at"`
+	ExpiresAt *time.Time         `db:"expires_at" json:"expires_at"`
+	Notes     string             `db:"notes" json:"notes"`
+	CreatedAt time.Time          `db:"created_at" json:"created_at"`
+	Files     []AutoDocumentFile `json:"files"`
+}
+
+// Документ просрочен на дату at, если срок действия закончился до этого дня
+func (d AutoDocument) ExpiredAt(at time.Time) bool {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	return d.ExpiresAt != nil && d.ExpiresAt.Before(day)
+}
+
+// Скан документа; StoredName — имя файла в каталоге вложений
+type AutoDocumentFile struct {
+	ID          int       `db:"id" json:"id"`
+	DocumentID  int       `db:"document_id" json:"document_id"`
+	FileName    string    `db:"file_name" json:"file_name"`
+	ContentType string    `db:"content_type" json:"content_type"`
+	Size        int64     `db:"size" json:"size"`
+	StoredName  string    `db:"stored_name" json:"-"`
+	UploadedAt  time.Time `db:"uploaded_at" json:"uploaded_at"`
+}
@@ -3,38 +3,183 @@ package models
 import "time"
 
 type AutoPersonal struct {
-	ID         int    `db:"id"`
-	FirstName  string `db:"first_name"`
-	LastName   string `db:"last_name"`
-	FatherName string `db:"father_name"`
+	ID         int        `db:"id" json:"id"`
+	FirstName  string     `db:"first_name" json:"first_name"`
+	LastName   string     `db:"last_name" json:"last_name"`
+	FatherName string     `db:"father_name" json:"father_name"`
+	ArchivedAt *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+	DriverDocuments
 }
 
 type Auto struct {
-	ID             int    `db:"id"`
-	Num            string `db:"num"`
-	Color          string `db:"color"`
-	Mark           string `db:"mark"`
-	PersonalID     int    `db:"personal_id"`
-	DriverFullName string `db:"driver_full_name"`
+	ID             int        `db:"id" json:"id"`
+	Num            string     `db:"num" json:"num"`
+	Color          string     `db:"color" json:"color"`
+	Mark           string     `db:"mark" json:"mark"`
+	Category       string     `db:"category" json:"category"`
+	PersonalID     int        `db:"personal_id" json:"personal_id"`
+	DriverFullName string     `db:"driver_full_name" json:"driver_full_name"`
+	ArchivedAt     *time.Time `db:"archived_at" json:"archived_at,omitempty"`
 }
 
+// Маршрут. Протяженность, плановая длительность и координаты необязательны;
+// Stops — промежуточные остановки в порядке следования.
 type Route struct {
-	ID         int     `db:"id"`
-	StartPoint string  `db:"start_point"`
-	EndPoint   string  `db:"end_point"`
-	Name       string  `db:"name"`
-	TimeDiff   float64 `json:"time_diff"`
+	ID             int         `db:"id" json:"id"`
+	StartPoint     string      `db:"start_point" json:"start_point"`
+	EndPoint       string      `db:"end_point" json:"end_point"`
+	StartCoords    *GeoPoint   `json:"start_coords"`
+	EndCoords      *GeoPoint   `json:"end_coords"`
+	DistanceKm     *float64    `db:"distance_km" json:"distance_km"`
+	PlannedMinutes *int        `db:"planned_minutes" json:"planned_minutes"`
+	Stops          []RouteStop `json:"stops"`
+	ArchivedAt     *time.Time  `db:"archived_at" json:"archived_at,omitempty"`
+}
+
+// Координаты точки маршрута в градусах
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Промежуточная остановка маршрута; Position — номер по порядку, начиная с 1
+type RouteStop struct {
+	Position int       `db:"position" json:"position"`
+	Name     string    `db:"name" json:"name"`
+	Coords   *GeoPoint `json:"coords"`
+}
+
+// Архивные справочники: записи скрыты из списков, но остаются в истории рейсов
+type Archive struct {
+	Drivers []AutoPersonal `json:"drivers"`
+	Autos   []Auto         `json:"autos"`
+	Routes  []Route        `json:"routes"`
 }
 
 type JournalView struct {
-	JournalID  int        `db:"journal_id"`
-	TimeOut    time.Time  `db:"time_out"`
-	TimeIn     *time.Time `db:"time_in"`
-	StartPoint string     `db:"start_point"`
-	EndPoint   string     `db:"end_point"`
-	AutoNumber string     `db:"auto_number"`
-	AutoMark   string     `db:"auto_mark"`
-	DriverName string     `db:"driver_name"`
+	JournalID  int        `db:"journal_id" json:"journal_id"`
+	TimeOut    time.Time  `db:"time_out" json:"time_out"`
+	TimeIn     *time.Time `db:"time_in" json:"time_in"`
+	StartPoint string     `db:"start_point" json:"start_point"`
+	EndPoint   string     `db:"end_point" json:"end_point"`
+	AutoNumber string     `db:"auto_number" json:"auto_number"`
+	AutoMark   string     `db:"auto_mark" json:"auto_mark"`
+	DriverName string     `db:"driver_name" json:"driver_name"`
+	AutoID     int        `db:"auto_id" json:"auto_id"`
+	RouteID    int        `db:"route_id" json:"route_id"`
+	DriverID   int        `db:"driver_id" json:"driver_id"`
+
+	OdometerOut *int     `db:"odometer_out" json:"odometer_out"`
+	OdometerIn  *int     `db:"odometer_in" json:"odometer_in"`
+	FuelOut     *float64 `db:"fuel_out" json:"fuel_out"`
+	FuelIn      *float64 `db:"fuel_in" json:"fuel_in"`
+	FuelAdded   *float64 `db:"fuel_added" json:"fuel_added"`
+
+	// Плановые протяженность и длительность маршрута для сравнения с фактом
+	PlannedDistance *float64 `db:"planned_distance_km" json:"planned_distance_km"`
+	PlannedMinutes  *int     `db:"planned_minutes" json:"planned_minutes"`
+}
+
+// Пробег за рейс, если известны оба показания одометра
+func (j JournalView) Distance() *int {
+	if j.OdometerOut == nil || j.OdometerIn == nil {
+		return nil
+	}
+	distance := *j.OdometerIn - *j.OdometerOut
+	return &distance
+}
+
+// Израсходованное за рейс топливо: остаток при отправлении плюс заправленное
+// в рейсе минус остаток при возвращении. Заправка в рейсе по умолчанию нулевая.
+func (j JournalView) FuelUsed() *float64 {
+	if j.FuelOut == nil || j.FuelIn == nil {
+		return nil
+	}
+	used := *j.FuelOut - *j.FuelIn
+	if j.FuelAdded != nil {
+		used += *j.FuelAdded
+	}
+	return &used
+}
+
+// Фактическая длительность завершенного рейса в минутах
+func (j JournalView) DurationMinutes() *float64 {
+	if j.TimeIn == nil {
+		return nil
+	}
+	minutes := j.TimeIn.Sub(j.TimeOut).Minutes()
+	return &minutes
+}
+
+// Показания при отправлении в рейс; nil — не указано
+type TripDeparture struct {
+	Odometer *int     `json:"odometer_out"`
+	Fuel     *float64 `json:"fuel_out"`
+}
+
+// Показания при возвращении из рейса; FuelAdded — заправлено в рейсе
+type TripReturn struct {
+	Odometer  *int     `json:"odometer_in"`
+	Fuel      *float64 `json:"fuel_in"`
+	FuelAdded *float64 `json:"fuel_added"`
+}
+
+// Пробег и расход топлива по группе рейсов: автомобилю, водителю или маршруту.
+// Distance учитывает рейсы с обоими показаниями одометра, расход на 100 км —
+// только рейсы, где известны и пробег, и показания топлива.
+type MileageRow struct {
+	ID                int      `json:"id"`
+	Name              string   `json:"name"`
+	Trips             int      `json:"trips"`
+	Distance          int      `json:"distance_km"`
+	FuelUsed          float64  `json:"fuel_used_l"`
+	FuelDistance      int      `json:"fuel_distance_km"`
+	ConsumptionPer100 *float64 `json:"l_per_100km"`
+}
+
+type MileageReport struct {
+	ByAuto   []MileageRow `json:"by_auto"`
+	ByDriver []MileageRow `json:"by_driver"`
+	ByRoute  []MileageRow `json:"by_route"`
+	Total    MileageRow   `json:"total"`
+}
+
+// Статусы рейса для фильтрации журнала
+const (
+	JournalStatusInProgress = "in_progress"
+	JournalStatusCompleted  = "completed"
+)
+
+// Поля сортировки журнала
+const (
+	JournalSortTimeOut = "time_out"
+	JournalSortTimeIn  = "time_in"
+	JournalSortDriver  = "driver"
+	JournalSortAuto    = "auto"
+	JournalSortRoute   = "route"
+)
+
+// Параметры выборки журнала. Интервал по времени отправления полуоткрытый:
+// TimeOutFrom включительно, TimeOutTo не включительно. PageSize = 0 означает
+// выборку без ограничения.
+type JournalFilter struct {
+	TimeOutFrom *time.Time
+	TimeOutTo   *time.Time
+	DriverID    int
+	AutoNumber  string
+	RouteID     int
+	Status      string
+	SortBy      string
+	SortDesc    bool
+	Page        int
+	PageSize    int
+}
+
+type JournalPage struct {
+	Entries  []JournalView `json:"entries"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
 }
 
 type User struct {
@@ -45,12 +190,35 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
-type RouteTime struct {
-	RouteName   string  `json:"route_name"`
-	AverageTime float64 `json:"average_time"`
-}
-
 type RouteVehicleCount struct {
 	RouteName    string `json:"route_name"`
 	VehicleCount int64  `json:"vehicle_count"`
 }
+
+// Роли пользователей автопарка
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+type APIToken struct {
+	ID         int        `db:"id" json:"id"`
+	UserID     int        `db:"user_id" json:"user_id"`
+	Name       string     `db:"name" json:"name"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// Серверная сессия веб-интерфейса
+type UserSession struct {
+	ID         int       `db:"id" json:"id"`
+	UserID     int       `db:"user_id" json:"user_id"`
+	Username   string    `db:"username" json:"username"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time `db:"last_seen_at" json:"last_seen_at"`
+	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
+	UserAgent  string    `db:"user_agent" json:"user_agent"`
+	RemoteAddr string    `db:"remote_addr" json:"remote_addr"`
+}
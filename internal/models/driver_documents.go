@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Категории водительских удостоверений в порядке, в котором они указываются в документе
+var LicenseCategories = []string{
+	"M", "A", "A1", "B", "B1", "BE", "C", "C1", "CE", "C1E", "D", "D1", "DE", "D1E", "Tm", "Tb",
+}
+
+// Категория автомобиля по умолчанию, как в столбце auto.category
+const DefaultAutoCategory = "B"
+
+// Документы водителя. Даты хранятся без времени; nil — не указано.
+type DriverDocuments struct {
+	Phone             string     `db:"phone" json:"phone"`
+	LicenseNumber     string     `db:"license_number" json:"license_number"`
+	LicenseCategories []string   `db:"license_categories" json:"license_categories"`
+	LicenseIssuedAt   *time.Time `db:"license_issued_at" json:"license_issued_at"`
+	LicenseExpiresAt  *time.Time `db:"license_expires_at" json:"license_expires_at"`
+	MedicalExpiresAt  *time.Time `db:"medical_expires_at" json:"medical_expires_at"`
+}
+
+func (d DriverDocuments) HasCategory(category string) bool {
+	for _, c := range d.LicenseCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Виды документов с ограниченным сроком действия
+const (
+	DocumentLicense = "license"
+	DocumentMedical = "medical"
+)
+
+// Документ водителя, срок действия которого истек или скоро истекает
+type ExpiringDocument struct {
+	DriverID   int       `json:"driver_id"`
+	DriverName string    `json:"driver_name"`
+	Document   string    `json:"document"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	DaysLeft   int       `json:"days_left"`
+}
+
+func (d ExpiringDocument) Expired() bool {
+	return d.DaysLeft < 0
+}
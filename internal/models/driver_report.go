@@ -0,0 +1,51 @@
+package models
+
+// Показатели водителя за период. Часы в пути и средняя длительность считаются по
+// завершенным рейсам, пунктуальность — доля рейсов без опоздания среди завершенных
+// рейсов маршрутов с плановой длительностью. Простой — время между возвращением
+// из рейса и следующим отправлением в пределах смены; Utilisation — доля часов
+// в пути от суммы часов в пути и простоя.
+type DriverPerformance struct {
+	Rank               int      `json:"rank"`
+	DriverID           int      `json:"driver_id"`
+	DriverName         string   `json:"driver_name"`
+	Trips              int      `json:"trips"`
+	CompletedTrips     int      `json:"completed_trips"`
+	TotalHours         float64  `json:"total_hours"`
+	AverageMinutes     *float64 `json:"average_minutes"`
+	PlannedTrips       int      `json:"planned_trips"`
+	OnTimeTrips        int      `json:"on_time_trips"`
+	PunctualityPercent *float64 `json:"punctuality_percent"`
+	IdleHours          float64  `json:"idle_hours"`
+	UtilisationPercent *float64 `json:"utilisation_percent"`
+}
+
+// Рейс водителя с рассчитанными показателями: задержка относительно плана
+// маршрута и простой перед отправлением
+type DriverTrip struct {
+	JournalView
+	DurationMinutes   *float64 `json:"duration_minutes"`
+	DelayMinutes      *float64 `json:"delay_minutes"`
+	Late              bool     `json:"late"`
+	IdleBeforeMinutes *float64 `json:"idle_before_minutes"`
+}
+
+// Поля ранжирования водителей, сортировка по убыванию
+const (
+	DriverRankHours       = "hours"
+	DriverRankTrips       = "trips"
+	DriverRankPunctuality = "punctuality"
+	DriverRankIdle        = "idle"
+	DriverRankUtilisation = "utilisation"
+)
+
+type DriverReport struct {
+	RankBy  string              `json:"rank_by"`
+	Drivers []DriverPerformance `json:"drivers"`
+}
+
+// Детализация по одному водителю
+type DriverReportDetail struct {
+	Driver DriverPerformance `json:"driver"`
+	Trips  []DriverTrip      `json:"trips"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// Строки пакетного импорта справочников. Row — номер строки в исходном файле,
+// Errors — ошибки проверки этой строки.
+type ImportDriver struct {
+	Row        int      `json:"row"`
+	FirstName  string   `json:"first_name"`
+	LastName   string   `json:"last_name"`
+	FatherName string   `json:"father_name"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// Водитель автомобиля задается в файле по имени. После проверки
+// PersonalID указывает на существующего водителя, а если водитель
+// добавляется тем же импортом — DriverIndex указывает на него в ImportBatch.Drivers.
+type ImportAuto struct {
+	Row         int      `json:"row"`
+	Num         string   `json:"num"`
+	Color       string   `json:"color"`
+	Mark        string   `json:"mark"`
+	DriverName  string   `json:"driver"`
+	PersonalID  int      `json:"personal_id,omitempty"`
+	DriverIndex int      `json:"-"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+type ImportRoute struct {
+	Row        int      `json:"row"`
+	StartPoint string   `json:"start_point"`
+	EndPoint   string   `json:"end_point"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+type ImportBatch struct {
+	Drivers []ImportDriver `json:"drivers"`
+	Autos   []ImportAuto   `json:"autos"`
+	Routes  []ImportRoute  `json:"routes"`
+}
+
+// Результат импорта или предварительной проверки (DryRun)
+type ImportResult struct {
+	ImportBatch
+	DryRun     bool `json:"dry_run"`
+	Committed  bool `json:"committed"`
+	ErrorCount int  `json:"error_count"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Запись о техническом обслуживании или ремонте автомобиля.
+// PerformedAt — дата работ без времени, Odometer — показания пробега, если известны.
+type MaintenanceRecord struct {
+	ID          int       `db:"id" json:"id"`
+	AutoID      int       `db:"auto_id" json:"auto_id"`
+	AutoNum     string    `db:"auto_num" json:"auto_num"`
+	Type        string    `db:"type" json:"type"`
+	PerformedAt time.Time `db:"performed_at" json:"performed_at"`
+	Odometer    *int      `db:"odometer" json:"odometer"`
+	Cost        float64   `db:"cost" json:"cost"`
+	Notes       string    `db:"notes" json:"notes"`
+}
+
+// Регламент обслуживания автомобиля: работы типа Type повторяются
+// каждые IntervalDays дней и/или IntervalKm километров пробега.
+// Просроченное обязательное обслуживание блокирует отправку в рейс.
+// StartOdometer — пробег автомобиля на момент создания регламента, если известен.
+type MaintenanceSchedule struct {
+	ID            int       `db:"id" json:"id"`
+	AutoID        int       `db:"auto_id" json:"auto_id"`
+	AutoNum       string    `db:"auto_num" json:"auto_num"`
+	Type          string    `db:"type" json:"type"`
+	IntervalDays  *int      `db:"interval_days" json:"interval_days"`
+	IntervalKm    *int      `db:"interval_km" json:"interval_km"`
+	Mandatory     bool      `db:"mandatory" json:"mandatory"`
+	StartOdometer *int      `db:"start_odometer" json:"start_odometer"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// Состояние обслуживания по регламенту
+const (
+	ServiceStatusOK       = "ok"
+	ServiceStatusUpcoming = "upcoming"
+	ServiceStatusOverdue  = "overdue"
+)
+
+// Срок очередного обслуживания по регламенту. Срок по пробегу известен,
+// только если для автомобиля есть показания одометра.
+type ServiceStatus struct {
+	Schedule        MaintenanceSchedule `json:"schedule"`
+	LastPerformedAt *time.Time          `json:"last_performed_at"`
+	LastOdometer    *int                `json:"last_odometer"`
+	CurrentOdometer *int                `json:"current_odometer"`
+	DueDate         *time.Time          `json:"due_date"`
+	DueOdometer     *int                `json:"due_odometer"`
+	Status          string              `json:"status"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Длительность завершенных рейсов по маршруту, в минутах. Опоздание — превышение
+// плановой длительности маршрута больше чем на допуск; без плана не определяется.
+// LatePercent — доля опоздавших среди рейсов с планом, AverageDelay — среднее
+// превышение плана у опоздавших.
+type RouteTime struct {
+	RouteID        int      `json:"route_id"`
+	RouteName      string   `json:"route_name"`
+	Trips          int      `json:"trips"`
+	AverageTime    float64  `json:"average_time"`
+	MedianTime     float64  `json:"median_time"`
+	P90Time        float64  `json:"p90_time"`
+	PlannedMinutes *int     `json:"planned_minutes"`
+	LateTrips      int      `json:"late_trips"`
+	LatePercent    *float64 `json:"late_percent"`
+	AverageDelay   *float64 `json:"average_delay"`
+}
+
+// Интервалы группировки тренда длительности рейсов
+const (
+	TrendDay   = "day"
+	TrendWeek  = "week"
+	TrendMonth = "month"
+)
+
+// Длительность рейсов маршрута за один интервал тренда
+type RouteTrendPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	RouteID     int       `json:"route_id"`
+	RouteName   string    `json:"route_name"`
+	Trips       int       `json:"trips"`
+	AverageTime float64   `json:"average_time"`
+	LateTrips   int       `json:"late_trips"`
+}
+
+type RouteDurationReport struct {
+	Interval string            `json:"interval"`
+	Routes   []RouteTime       `json:"routes"`
+	Trend    []RouteTrendPoint `json:"trend"`
+}
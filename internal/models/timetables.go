@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Расписание маршрута: рейсы в DepartureTime по дням недели Weekdays
+// (1 — понедельник, 7 — воскресенье) с закрепленными автомобилем и водителем.
+// GeneratedUntil — последний день, на который планы уже сформированы.
+type Timetable struct {
+	ID              int        `db:"id" json:"id"`
+	RouteID         int        `db:"route_id" json:"route_id"`
+	RouteName       string     `db:"route_name" json:"route_name"`
+	AutoID          int        `db:"auto_id" json:"auto_id"`
+	AutoNum         string     `db:"auto_num" json:"auto_num"`
+	DriverID        int        `db:"personal_id" json:"driver_id"`
+	DriverName      string     `db:"driver_name" json:"driver_name"`
+	DepartureTime   string     `db:"departure_time" json:"departure_time"`
+	DurationMinutes *int       `db:"duration_minutes" json:"duration_minutes"`
+	Weekdays        []int      `db:"weekdays" json:"weekdays"`
+	ValidFrom       time.Time  `db:"valid_from" json:"valid_from"`
+	ValidTo         *time.Time `db:"valid_to" json:"valid_to"`
+	GeneratedUntil  *time.Time `db:"generated_until" json:"generated_until"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Номер дня недели по ISO: понедельник — 1, воскресенье — 7
+func ISOWeekday(date time.Time) int {
+	return (int(date.Weekday())+6)%7 + 1
+}
+
+// Выполняется ли рейс по расписанию в указанный день без учета исключений
+func (t Timetable) RunsOn(date time.Time) bool {
+	if date.Before(t.ValidFrom) || (t.ValidTo != nil && date.After(*t.ValidTo)) {
+		return false
+	}
+	weekday := ISOWeekday(date)
+	for _, day := range t.Weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Исключение из расписания: в указанный день рейсы не выполняются.
+// TimetableID = nil — праздничный день для всех расписаний.
+type TimetableException struct {
+	ID          int       `db:"id" json:"id"`
+	TimetableID *int      `db:"timetable_id" json:"timetable_id"`
+	Date        time.Time `db:"exception_date" json:"date"`
+	Reason      string    `db:"reason" json:"reason"`
+}
+
+// Относится ли исключение к расписанию
+func (e TimetableException) AppliesTo(timetableID int) bool {
+	return e.TimetableID == nil || *e.TimetableID == timetableID
+}
+
+// Краткие названия дней недели по номеру ISO
+var WeekdayTitles = [...]string{1: "пн", 2: "вт", 3: "ср", 4: "чт", 5: "пт", 6: "сб", 7: "вс"}
+
+// Дни недели расписания для отображения: «пн, ср, пт»
+func (t Timetable) WeekdaysTitle() string {
+	titles := make([]string, 0, len(t.Weekdays))
+	for _, day := range t.Weekdays {
+		if day >= 1 && day <= 7 {
+			titles = append(titles, WeekdayTitles[day])
+		}
+	}
+	return strings.Join(titles, ", ")
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Запланированный рейс. JournalID заполняется при отправке в рейс;
+// PlannedIn — планируемое время возвращения, nil — не указано;
+// TimetableID — расписание, по которому сформирован план.
+type TripPlan struct {
+	ID          int        `db:"id" json:"id"`
+	AutoID      int        `db:"auto_id" json:"auto_id"`
+	AutoNum     string     `db:"auto_num" json:"auto_num"`
+	DriverID    int        `db:"personal_id" json:"driver_id"`
+	DriverName  string     `db:"driver_name" json:"driver_name"`
+	RouteID     int        `db:"route_id" json:"route_id"`
+	RouteName   string     `db:"route_name" json:"route_name"`
+	PlannedOut  time.Time  `db:"planned_out" json:"planned_out"`
+	PlannedIn   *time.Time `db:"planned_in" json:"planned_in"`
+	Notes       string     `db:"notes" json:"notes"`
+	JournalID   *int       `db:"journal_id" json:"journal_id"`
+	TimetableID *int       `db:"timetable_id" json:"timetable_id"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+
+	// Пересечения с другими планами и незавершенными рейсами на момент выборки
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+func (p TripPlan) Dispatched() bool {
+	return p.JournalID != nil
+}
+
+// Параметры выборки планов: интервал по планируемому времени отправления
+// полуоткрытый, как и в фильтре журнала; нулевые поля не ограничивают выборку
+type TripPlanFilter struct {
+	From        *time.Time
+	To          *time.Time
+	AutoID      int
+	DriverID    int
+	TimetableID int
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Методы для работы с закреплением водителей за автомобилями
+func (s *AutoParkService) GetAssignments(ctx context.Context, filter models.AssignmentFilter) ([]models.Assignment, error) {
+	assignments, err := s.db.GetAssignments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+func (s *AutoParkService) GetAssignmentByID(ctx context.Context, assignmentID int) (*models.Assignment, error) {
+	return s.db.GetAssignmentByID(ctx, assignmentID)
+}
+
+// Закрепление водителя за автомобилем; без даты начала — с сегодняшнего дня
+func (s *AutoParkService) AddAssignment(ctx context.Context, assignment models.Assignment) (int, error) {
+	if assignment.AutoID <= 0 {
+		return 0, newValidationError("не выбран автомобиль")
+	}
+	if assignment.DriverID <= 0 {
+		return 0, newValidationError("не выбран водитель")
+	}
+	if assignment.ValidFrom.IsZero() {
+		assignment.ValidFrom = today()
+	}
+	if err := validateAssignmentPeriod(assignment.ValidFrom, assignment.ValidTo); err != nil {
+		return 0, err
+	}
+
+	assignmentID, err := s.db.AddAssignment(ctx, assignment)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось закрепить водителя: %w", err)
+	}
+	return assignmentID, nil
+}
+
+func (s *AutoParkService) UpdateAssignmentPeriod(ctx context.Context, assignmentID int, validFrom time.Time, validTo *time.Time) error {
+	if err := validateAssignmentPeriod(validFrom, validTo); err != nil {
+		return err
+	}
+	assignment, err := s.db.GetAssignmentByID(ctx, assignmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAssignmentTrips(ctx, *assignment, &validFrom, validTo); err != nil {
+		return err
+	}
+	return s.db.UpdateAssignmentPeriod(ctx, assignmentID, validFrom, validTo)
+}
+
+// Завершение закрепления: validTo — последний день, когда водитель работает на автомобиле
+func (s *AutoParkService) EndAssignment(ctx context.Context, assignmentID int, validTo time.Time) error {
+	assignment, err := s.db.GetAssignmentByID(ctx, assignmentID)
+	if err != nil {
+		return err
+	}
+	return s.UpdateAssignmentPeriod(ctx, assignmentID, assignment.ValidFrom, &validTo)
+}
+
+// Закрепление, по которому уже выполнялись рейсы, удалить нельзя — только завершить
+func (s *AutoParkService) DeleteAssignment(ctx context.Context, assignmentID int) error {
+	assignment, err := s.db.GetAssignmentByID(ctx, assignmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAssignmentTrips(ctx, *assignment, nil, nil); err != nil {
+		return err
+	}
+	return s.db.DeleteAssignment(ctx, assignmentID)
+}
+
+func validateAssignmentPeriod(validFrom time.Time, validTo *time.Time) error {
+	if validFrom.IsZero() {
+		return newValidationError("укажите дату начала закрепления")
+	}
+	if validTo != nil && validTo.Before(validFrom) {
+		return newValidationError("дата окончания закрепления не может быть раньше даты начала")
+	}
+	return nil
+}
+
+// Рейсы водителя на автомобиле в пределах закрепления должны остаться в новом
+// периоде [validFrom, validTo]; validFrom = nil — закрепление удаляется целиком
+func (s *AutoParkService) checkAssignmentTrips(ctx context.Context, assignment models.Assignment, validFrom, validTo *time.Time) error {
+	entries, err := s.GetAllJournalEntries(ctx, models.JournalFilter{DriverID: assignment.DriverID})
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.AutoID != assignment.AutoID || !assignment.ActiveOn(entry.TimeOut) {
+			continue
+		}
+		if validFrom == nil {
+			return fmt.Errorf("%w: по закреплению выполнялись рейсы (рейс %d от %s), его можно только завершить",
+				database.ErrConflict, entry.JournalID, entry.TimeOut.Format("02.01.2006"))
+		}
+		period := models.Assignment{ValidFrom: *validFrom, ValidTo: validTo}
+		if !period.ActiveOn(entry.TimeOut) {
+			return fmt.Errorf("%w: рейс %d от %s выходит за новый период закрепления",
+				database.ErrConflict, entry.JournalID, entry.TimeOut.Format("02.01.2006"))
+		}
+	}
+	return nil
+}
+
+// Сегодняшняя дата без времени, как даты закреплений и документов
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Страница журнала аудита по фильтру
+func (s *AutoParkService) GetAuditLog(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, newValidationError("date range start must be before its end")
+	}
+	switch filter.Action {
+	case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete:
+	default:
+		return nil, newValidationError("unknown audit action %q", filter.Action)
+	}
+	switch filter.EntityType {
+	case "", models.AuditEntityDriver, models.AuditEntityAuto, models.AuditEntityRoute, models.AuditEntityJournal,
+		models.AuditEntityAssignment, models.AuditEntityMaintenance, models.AuditEntitySchedule,
+		models.AuditEntityAutoDocument, models.AuditEntityAutoDocumentFile, models.AuditEntityTripPlan,
+		models.AuditEntityTimetable, models.AuditEntityTimetableException, models.AuditEntityRouteStop:
+	default:
+		return nil, newValidationError("unknown audit entity type %q", filter.EntityType)
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = DefaultJournalPageSize
+	}
+	if filter.PageSize > MaxJournalPageSize {
+		return nil, newValidationError("page size must not exceed %d", MaxJournalPageSize)
+	}
+
+	page, err := s.db.GetAuditLog(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return page, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/models"
+)
+
+// Удаление плана рейса и остановок маршрута остается в журнале аудита
+// вместе с пользователем, который его выполнил
+func TestAuditRecordsPlanAndStopChanges(t *testing.T) {
+	f := newFixture(t)
+	ctx := auth.WithUser(context.Background(), &models.User{ID: 7, Username: "dispatcher"})
+
+	planID, err := f.service.AddTripPlan(ctx, f.autoID, f.driverID, f.routeID, formTime(time.Hour), "", "")
+	if err != nil {
+		t.Fatalf("AddTripPlan: %v", err)
+	}
+	if err := f.service.DeleteTripPlan(ctx, planID); err != nil {
+		t.Fatalf("DeleteTripPlan: %v", err)
+	}
+	route, err := f.service.GetRouteByID(ctx, f.routeID)
+	if err != nil {
+		t.Fatalf("GetRouteByID: %v", err)
+	}
+	route.Stops = []models.RouteStop{{Name: "Рынок"}}
+	if err := f.service.UpdateRoute(ctx, route); err != nil {
+		t.Fatalf("UpdateRoute: %v", err)
+	}
+	route.Stops = nil
+	if err := f.service.UpdateRoute(ctx, route); err != nil {
+		t.Fatalf("UpdateRoute: %v", err)
+	}
+
+	for _, tc := range []struct {
+		entityType string
+		actions    []string
+	}{
+		{models.AuditEntityTripPlan, []string{models.AuditActionDelete, models.AuditActionCreate}},
+		{models.AuditEntityRouteStop, []string{models.AuditActionDelete, models.AuditActionCreate}},
+	} {
+		page, err := f.service.GetAuditLog(ctx, models.AuditFilter{EntityType: tc.entityType})
+		if err != nil {
+			t.Fatalf("GetAuditLog(%s): %v", tc.entityType, err)
+		}
+		if len(page.Entries) != len(tc.actions) {
+			t.Fatalf("%s: %d audit entries, want %d", tc.entityType, len(page.Entries), len(tc.actions))
+		}
+		for i, entry := range page.Entries {
+			if entry.Action != tc.actions[i] {
+				t.Errorf("%s entry %d: action %s, want %s", tc.entityType, i, entry.Action, tc.actions[i])
+			}
+			if entry.UserID == nil || *entry.UserID != 7 || entry.Username != "dispatcher" {
+				t.Errorf("%s entry %d: actor %v %q, want dispatcher", tc.entityType, i, entry.UserID, entry.Username)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Максимальный размер скана документа
+const MaxAttachmentSize = 10 << 20
+
+// Допустимые форматы сканов и расширения, под которыми они сохраняются
+var attachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// Документы, без действующего экземпляра которых автомобиль не выпускается в рейс
+var dispatchDocuments = []string{models.AutoDocumentInsurance, models.AutoDocumentInspection}
+
+var autoDocumentNames = map[string]string{
+	models.AutoDocumentInsurance:    "страховой полис",
+	models.AutoDocumentRegistration: "свидетельство о регистрации",
+	models.AutoDocumentInspection:   "техосмотр",
+}
+
+// Методы для работы с документами автомобилей
+func (s *AutoParkService) GetAutoDocuments(ctx context.Context, autoID int) ([]models.AutoDocument, error) {
+	return s.db.GetAutoDocuments(ctx, autoID)
+}
+
+func (s *AutoParkService) GetAutoDocumentByID(ctx context.Context, documentID int) (*models.AutoDocument, error) {
+	return s.db.GetAutoDocumentByID(ctx, documentID)
+}
+
+func (s *AutoParkService) GetAutoDocumentFile(ctx context.Context, fileID int) (*models.AutoDocumentFile, error) {
+	return s.db.GetAutoDocumentFile(ctx, fileID)
+}
+
+func (s *AutoParkService) AddAutoDocument(ctx context.Context, document models.AutoDocument) (int, error) {
+	document.Number = strings.TrimSpace(document.Number)
+	document.Notes = strings.TrimSpace(document.Notes)
+
+	if document.AutoID <= 0 {
+		return 0, newValidationError("не выбран автомобиль")
+	}
+	if _, ok := autoDocumentNames[document.Type]; !ok {
+		return 0, newValidationError("неизвестный вид документа: %s", document.Type)
+	}
+	if utf8.RuneCountInString(document.Number) > 50 {
+		return 0, newValidationError("номер документа не должен превышать 50 символов")
+	}
+	if utf8.RuneCountInString(document.Notes) > 1000 {
+		return 0, newValidationError("примечание не должно превышать 1000 символов")
+	}
+	if document.ExpiresAt == nil && document.Type != models.AutoDocumentRegistration {
+		return 0, newValidationError("укажите срок действия документа")
+	}
+	if document.IssuedAt != nil && document.IssuedAt.After(time.Now()) {
+		return 0, newValidationError("дата выдачи документа не может быть в будущем")
+	}
+	if document.IssuedAt != nil && document.ExpiresAt != nil && !document.IssuedAt.Before(*document.ExpiresAt) {
+		return 0, newValidationError("дата выдачи документа должна быть раньше даты окончания действия")
+	}
+
+	documentID, err := s.db.AddAutoDocument(ctx, document)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось добавить документ: %w", err)
+	}
+	return documentID, nil
+}
+
+// Документ удаляется вместе со сканами; ошибка удаления файла с диска
+// не отменяет удаление записи
+func (s *AutoParkService) DeleteAutoDocument(ctx context.Context, documentID int) error {
+	document, err := s.db.GetAutoDocumentByID(ctx, documentID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.DeleteAutoDocument(ctx, documentID); err != nil {
+		return err
+	}
+	for _, file := range document.Files {
+		_ = s.files.Remove(file.StoredName)
+	}
+	return nil
+}
+
+// Загрузка скана документа: PDF, JPEG или PNG размером до 10 МБ.
+// Формат определяется по содержимому файла, а не по расширению.
+func (s *AutoParkService) AddAutoDocumentFile(ctx context.Context, documentID int, fileName string, data []byte) (*models.AutoDocumentFile, error) {
+	if len(data) == 0 {
+		return nil, newValidationError("файл пуст")
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, newValidationError("размер файла превышает %d МБ", MaxAttachmentSize>>20)
+	}
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	ext, ok := attachmentTypes[contentType]
+	if !ok {
+		return nil, newValidationError("допускаются только файлы PDF, JPEG и PNG")
+	}
+
+	fileName = filepath.Base(strings.TrimSpace(fileName))
+	if fileName == "." || fileName == string(filepath.Separator) {
+		fileName = "document" + ext
+	}
+	if utf8.RuneCountInString(fileName) > 255 {
+		fileName = string([]rune(fileName)[:255])
+	}
+
+	storedName, err := s.files.Save(data, ext)
+	if err != nil {
+		return nil, err
+	}
+	file := models.AutoDocumentFile{
+		DocumentID:  documentID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StoredName:  storedName,
+	}
+	file.ID, err = s.db.AddAutoDocumentFile(ctx, file)
+	if err != nil {
+		_ = s.files.Remove(storedName)
+		return nil, fmt.Errorf("не удалось сохранить файл: %w", err)
+	}
+	return s.db.GetAutoDocumentFile(ctx, file.ID)
+}
+
+// Скан документа и открытый файл с его содержимым; файл закрывает вызывающий
+func (s *AutoParkService) OpenAutoDocumentFile(ctx context.Context, fileID int) (*models.AutoDocumentFile, *os.File, error) {
+	file, err := s.db.GetAutoDocumentFile(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.files.Open(file.StoredName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("auto document file content %w", database.ErrNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+	return file, content, nil
+}
+
+func (s *AutoParkService) DeleteAutoDocumentFile(ctx context.Context, fileID int) error {
+	file, err := s.db.GetAutoDocumentFile(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.DeleteAutoDocumentFile(ctx, fileID); err != nil {
+		return err
+	}
+	_ = s.files.Remove(file.StoredName)
+	return nil
+}
+
+// Автомобиль не выпускается в рейс, если закончился срок действия страхового
+// полиса или техосмотра. Учитывается документ с самым поздним сроком действия;
+// если документы такого вида не внесены, проверка не выполняется.
+func (s *AutoParkService) checkAutoDocumentsBeforeDispatch(ctx context.Context, autoID int, at time.Time) error {
+	documents, err := s.db.GetAutoDocuments(ctx, autoID)
+	if err != nil {
+		return fmt.Errorf("failed to load auto documents: %w", err)
+	}
+
+	var lapsed []string
+	for _, documentType := range dispatchDocuments {
+		var latest *models.AutoDocument
+		for i := range documents {
+			document := &documents[i]
+			if document.Type != documentType {
+				continue
+			}
+			if latest == nil || document.ExpiresAt == nil ||
+				(latest.ExpiresAt != nil && document.ExpiresAt.After(*latest.ExpiresAt)) {
+				latest = document
+			}
+		}
+		if latest != nil && latest.ExpiredAt(at) {
+			lapsed = append(lapsed, fmt.Sprintf("%s (до %s)",
+				autoDocumentNames[documentType], latest.ExpiresAt.Format("02.01.2006")))
+		}
+	}
+	if len(lapsed) > 0 {
+		return fmt.Errorf("%w: автомобиль не может быть отправлен в рейс, истек срок действия: %s",
+			database.ErrConflict, strings.Join(lapsed, ", "))
+	}
+	return nil
+}
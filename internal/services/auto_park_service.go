@@ -4,20 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
+	"AutoParkWeb/internal/attachments"
 	"AutoParkWeb/internal/auth"
 	"AutoParkWeb/internal/database/postgres"
 	"AutoParkWeb/internal/models"
 )
 
 type AutoParkService struct {
-	db database.DBHandler
+	db    database.DBHandler
+	files *attachments.Storage
+
+	// На сколько дней вперед формируются планы по расписаниям
+	timetableHorizon int
+
+	events EventRecorder
+	logger *slog.Logger
+}
+
+func NewAutoParkService(db database.DBHandler, files *attachments.Storage, logger *slog.Logger) *AutoParkService {
+	return &AutoParkService{db: db, files: files, timetableHorizon: DefaultTimetableHorizonDays, events: noopRecorder{}, logger: logger}
 }
 
-func NewAutoParkService(db *database.PostgresDB) *AutoParkService {
-	return &AutoParkService{db: db}
+// Готовность к обслуживанию запросов: хранилище доступно
+func (s *AutoParkService) Ready(ctx context.Context) error {
+	return s.db.Ping(ctx)
 }
 
 // Методы для работы с водителями
@@ -29,22 +43,33 @@ func (s *AutoParkService) GetDriverByID(ctx context.Context, driverID int) (*mod
 	return s.db.GetDriverByID(ctx, driverID)
 }
 
-func (s *AutoParkService) AddDriver(ctx context.Context, firstName, lastName, fatherName string) error {
-	if firstName == "" || lastName == "" {
-		return errors.New("first name and last name are required")
+func (s *AutoParkService) AddDriver(ctx context.Context, firstName, lastName, fatherName string, docs models.DriverDocuments) (int, error) {
+	if err := validateDriver(firstName, lastName, fatherName); err != nil {
+		return 0, err
+	}
+	if err := normalizeDocuments(&docs); err != nil {
+		return 0, err
 	}
-	return s.db.AddDriver(ctx, firstName, lastName, fatherName)
+	return s.db.AddDriver(ctx, firstName, lastName, fatherName, docs)
 }
 
-func (s *AutoParkService) UpdateDriver(ctx context.Context, driverID int, firstName, lastName, fatherName string) error {
-	if firstName == "" || lastName == "" {
-		return errors.New("first name and last name are required")
+func (s *AutoParkService) UpdateDriver(ctx context.Context, driverID int, firstName, lastName, fatherName string, docs models.DriverDocuments) error {
+	if err := validateDriver(firstName, lastName, fatherName); err != nil {
+		return err
+	}
+	if err := normalizeDocuments(&docs); err != nil {
+		return err
 	}
-	return s.db.UpdateDriver(ctx, driverID, firstName, lastName, fatherName)
+	return s.db.UpdateDriver(ctx, driverID, firstName, lastName, fatherName, docs)
 }
 
-func (s *AutoParkService) DeleteDriver(ctx context.Context, driverID int) error {
-	return s.db.DeleteDriver(ctx, driverID)
+// Водитель переносится в архив вместе со своими автомобилями
+func (s *AutoParkService) ArchiveDriver(ctx context.Context, driverID int) error {
+	return s.db.ArchiveDriver(ctx, driverID)
+}
+
+func (s *AutoParkService) RestoreDriver(ctx context.Context, driverID int) error {
+	return s.db.RestoreDriver(ctx, driverID)
 }
 
 // Методы для работы с автомобилями
@@ -57,32 +82,44 @@ func (s *AutoParkService) GetCarByID(ctx context.Context, carID int) (*models.Au
 	return s.db.GetCarByID(ctx, carID)
 }
 
-func (s *AutoParkService) AddCar(ctx context.Context, num, color, mark string, personalID int) error {
-	if num == "" || color == "" || mark == "" {
-		return errors.New("num, color and mark are required")
+func (s *AutoParkService) AddCar(ctx context.Context, num, color, mark, category string, personalID int) (int, error) {
+	if err := validateCar(num, color, mark); err != nil {
+		return 0, err
 	}
-	err := s.db.AddCar(ctx, num, color, mark, personalID)
+	category, err := normalizeAutoCategory(category)
 	if err != nil {
-		return fmt.Errorf("не удалось добавить автомобиль: %v", err)
+		return 0, err
 	}
-	return nil
+	carID, err := s.db.AddCar(ctx, num, color, mark, category, personalID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось добавить автомобиль: %w", err)
+	}
+	return carID, nil
 }
 
-func (s *AutoParkService) UpdateCar(ctx context.Context, carID int, num, color, mark string, personalID int) error {
-	if num == "" || color == "" || mark == "" {
-		return errors.New("num, color and mark are required")
+func (s *AutoParkService) UpdateCar(ctx context.Context, carID int, num, color, mark, category string, personalID int) error {
+	if err := validateCar(num, color, mark); err != nil {
+		return err
+	}
+	category, err := normalizeAutoCategory(category)
+	if err != nil {
+		return err
 	}
 
-	err := s.db.UpdateCar(ctx, carID, num, color, mark, personalID)
+	err = s.db.UpdateCar(ctx, carID, num, color, mark, category, personalID)
 	if err != nil {
-		return fmt.Errorf("не удалось обновить автомобиль с ID %d: %v", carID, err)
+		return fmt.Errorf("не удалось обновить автомобиль с ID %d: %w", carID, err)
 	}
 
 	return nil
 }
 
-func (s *AutoParkService) DeleteCar(ctx context.Context, carID int) error {
-	return s.db.DeleteCar(ctx, carID)
+func (s *AutoParkService) ArchiveCar(ctx context.Context, carID int) error {
+	return s.db.ArchiveCar(ctx, carID)
+}
+
+func (s *AutoParkService) RestoreCar(ctx context.Context, carID int) error {
+	return s.db.RestoreCar(ctx, carID)
 }
 
 // Методы для работы с маршрутами
@@ -94,37 +131,110 @@ func (s *AutoParkService) GetRouteByID(ctx context.Context, routeID int) (*model
 	return s.db.GetRouteByID(ctx, routeID)
 }
 
-func (s *AutoParkService) AddRoute(ctx context.Context, startPoint, endPoint string) error {
-	if startPoint == "" || endPoint == "" {
-		return errors.New("both startPoint and endPoint are required")
+func (s *AutoParkService) AddRoute(ctx context.Context, route models.Route) (int, error) {
+	if err := validateRoute(route.StartPoint, route.EndPoint); err != nil {
+		return 0, err
+	}
+	if err := validateRouteDetails(&route); err != nil {
+		return 0, err
 	}
-	return s.db.AddRoute(ctx, startPoint, endPoint)
+	return s.db.AddRoute(ctx, route)
 }
 
+// Изменение маршрута вместе с плановыми показателями и списком остановок
 func (s *AutoParkService) UpdateRoute(ctx context.Context, route *models.Route) error {
+	if err := validateRoute(route.StartPoint, route.EndPoint); err != nil {
+		return err
+	}
+	if err := validateRouteDetails(route); err != nil {
+		return err
+	}
 	return s.db.UpdateRoute(ctx, route)
 }
 
-func (s *AutoParkService) DeleteRoute(ctx context.Context, routeID int) error {
-	return s.db.DeleteRoute(ctx, routeID)
+func (s *AutoParkService) ArchiveRoute(ctx context.Context, routeID int) error {
+	return s.db.ArchiveRoute(ctx, routeID)
+}
+
+func (s *AutoParkService) RestoreRoute(ctx context.Context, routeID int) error {
+	return s.db.RestoreRoute(ctx, routeID)
+}
+
+// Архивные водители, автомобили и маршруты
+func (s *AutoParkService) GetArchive(ctx context.Context) (*models.Archive, error) {
+	return s.db.GetArchive(ctx)
 }
 
 // Методы для работы с журналом
-func (s *AutoParkService) GetAllJournalEntries(ctx context.Context) ([]models.JournalView, error) {
-	entries, err := s.db.GetAllJournalEntries(ctx)
+const (
+	DefaultJournalPageSize = 50
+	MaxJournalPageSize     = 500
+)
+
+// Страница журнала по фильтру
+func (s *AutoParkService) GetJournalEntries(ctx context.Context, filter models.JournalFilter) (*models.JournalPage, error) {
+	if err := validateJournalFilter(&filter); err != nil {
+		return nil, err
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = DefaultJournalPageSize
+	}
+	if filter.PageSize > MaxJournalPageSize {
+		return nil, newValidationError("page size must not exceed %d", MaxJournalPageSize)
+	}
+
+	page, err := s.db.GetJournalEntries(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all journal_table entries: %v", err)
+		return nil, fmt.Errorf("failed to get journal_table entries: %w", err)
 	}
-	return entries, nil
+	return page, nil
+}
+
+// Все записи журнала по фильтру, без разбиения на страницы (для выгрузки)
+func (s *AutoParkService) GetAllJournalEntries(ctx context.Context, filter models.JournalFilter) ([]models.JournalView, error) {
+	if err := validateJournalFilter(&filter); err != nil {
+		return nil, err
+	}
+	filter.Page, filter.PageSize = 0, 0
+
+	page, err := s.db.GetJournalEntries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all journal_table entries: %w", err)
+	}
+	return page.Entries, nil
+}
+
+func validateJournalFilter(filter *models.JournalFilter) error {
+	if filter.TimeOutFrom != nil && filter.TimeOutTo != nil && !filter.TimeOutFrom.Before(*filter.TimeOutTo) {
+		return newValidationError("date range start must be before its end")
+	}
+	switch filter.Status {
+	case "", models.JournalStatusInProgress, models.JournalStatusCompleted:
+	default:
+		return newValidationError("unknown journal status %q", filter.Status)
+	}
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = models.JournalSortTimeOut
+	case models.JournalSortTimeOut, models.JournalSortTimeIn, models.JournalSortDriver,
+		models.JournalSortAuto, models.JournalSortRoute:
+	default:
+		return newValidationError("unknown sort field %q", filter.SortBy)
+	}
+	filter.AutoNumber = strings.TrimSpace(filter.AutoNumber)
+	return nil
 }
 
 func (s *AutoParkService) GetJournalEntryByID(ctx context.Context, journalID int) (*models.JournalView, error) {
 	if journalID <= 0 {
-		return nil, errors.New("invalid journal_table ID")
+		return nil, newValidationError("invalid journal_table ID")
 	}
 	entry, err := s.db.GetJournalEntryByID(ctx, journalID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get journal_table entry by ID: %v", err)
+		return nil, fmt.Errorf("failed to get journal_table entry by ID: %w", err)
 	}
 	return entry, nil
 }
@@ -132,51 +242,133 @@ func (s *AutoParkService) GetJournalEntryByID(ctx context.Context, journalID int
 func (s *AutoParkService) GetAutosByDriverID(ctx context.Context, driverID int) ([]models.Auto, error) {
 	autos, err := s.db.GetAutosByDriverID(ctx, driverID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting autos for driver with ID %d: %v", driverID, err)
+		return nil, fmt.Errorf("error getting autos for driver with ID %d: %w", driverID, err)
 	}
 
 	return autos, nil
 }
 
-func (s *AutoParkService) AddJournalEntry(ctx context.Context, autoID, routeID int, timeOut string) error {
+// Рейс выполняет водитель driverID, закрепленный за автомобилем на дату
+// отправления; driverID = 0 — основной водитель автомобиля
+func (s *AutoParkService) AddJournalEntry(ctx context.Context, autoID, driverID, routeID int, timeOut string, departure models.TripDeparture) (int, error) {
 	if autoID <= 0 || routeID <= 0 {
-		return errors.New("autoID and routeID must be positive")
+		return 0, newValidationError("autoID and routeID must be positive")
+	}
+	if driverID < 0 {
+		return 0, newValidationError("driverID must be positive")
 	}
 	if timeOut == "" {
-		return errors.New("time out is required")
+		return 0, newValidationError("time out is required")
 	}
 
-	timeOutParsed, err := time.Parse("2006-01-02T15:04", timeOut)
+	timeOutParsed, err := parseTime(timeOut)
 	if err != nil {
-		return fmt.Errorf("invalid timeOut format: %v", err)
+		return 0, newValidationError("invalid timeOut format: %v", err)
+	}
+	if err := validateReadings(departure.Odometer, departure.Fuel, nil); err != nil {
+		return 0, err
+	}
+
+	if driverID == 0 {
+		car, _, err := s.db.GetCarByID(ctx, autoID)
+		if errors.Is(err, database.ErrNotFound) {
+			return 0, fmt.Errorf("%w: автомобиль с ID %d не существует", database.ErrConflict, autoID)
+		}
+		if err != nil {
+			return 0, err
+		}
+		driverID = car.PersonalID
 	}
 
-	return s.db.AddJournalEntry(ctx, autoID, routeID, timeOutParsed)
+	return s.startTrip(ctx, autoID, driverID, routeID, timeOutParsed, func() (int, error) {
+		return s.db.AddJournalEntry(ctx, autoID, driverID, routeID, timeOutParsed, departure)
+	})
 }
 
-func (s *AutoParkService) CompleteJournalEntry(ctx context.Context, entryID int, timeIn string) error {
+// Выпуск в рейс: проверки перед отправкой, затем insert создает запись журнала
+func (s *AutoParkService) startTrip(ctx context.Context, autoID, driverID, routeID int, timeOut time.Time, insert func() (int, error)) (int, error) {
+	if err := s.checkBeforeDispatch(ctx, autoID, driverID, timeOut); err != nil {
+		if errors.Is(err, database.ErrConflict) {
+			s.events.DispatchRejected(DispatchStageCheck)
+			s.logger.InfoContext(ctx, "Dispatch rejected", "auto_id", autoID, "driver_id", driverID, "reason", err)
+		}
+		return 0, err
+	}
+
+	entryID, err := insert()
+	if err != nil {
+		if errors.Is(err, database.ErrConflict) {
+			s.events.DispatchRejected(DispatchStageDatabase)
+			s.logger.InfoContext(ctx, "Dispatch rejected", "auto_id", autoID, "driver_id", driverID, "reason", err)
+		}
+		return 0, err
+	}
+	s.events.TripStarted()
+	s.logger.InfoContext(ctx, "Trip started", "journal_id", entryID, "auto_id", autoID, "driver_id", driverID, "route_id", routeID)
+	return entryID, nil
+}
+
+// Проверки перед отправкой: обслуживание, допуск водителя и документы автомобиля
+func (s *AutoParkService) checkBeforeDispatch(ctx context.Context, autoID, driverID int, timeOut time.Time) error {
+	if err := s.checkServiceBeforeDispatch(ctx, autoID, timeOut); err != nil {
+		return err
+	}
+	if err := s.checkDriverBeforeDispatch(ctx, autoID, driverID, timeOut); err != nil {
+		return err
+	}
+	return s.checkAutoDocumentsBeforeDispatch(ctx, autoID, timeOut)
+}
+
+func (s *AutoParkService) CompleteJournalEntry(ctx context.Context, entryID int, timeIn string, arrival models.TripReturn) error {
 	if entryID <= 0 {
-		return errors.New("entryID must be positive")
+		return newValidationError("entryID must be positive")
 	}
 	if timeIn == "" {
-		return errors.New("time in is required")
+		return newValidationError("time in is required")
 	}
 
-	timeInParsed, err := time.Parse("2006-01-02T15:04", timeIn)
+	timeInParsed, err := parseTime(timeIn)
 	if err != nil {
-		return fmt.Errorf("invalid timeIn format: %v", err)
+		return newValidationError("invalid timeIn format: %v", err)
+	}
+	if err := validateReadings(arrival.Odometer, arrival.Fuel, arrival.FuelAdded); err != nil {
+		return err
+	}
+
+	if arrival.Odometer != nil {
+		entry, err := s.db.GetJournalEntryByID(ctx, entryID)
+		if err != nil {
+			return err
+		}
+		if entry.OdometerOut != nil && *arrival.Odometer < *entry.OdometerOut {
+			return newValidationError("показания одометра при возвращении (%d км) меньше, чем при отправлении (%d км)",
+				*arrival.Odometer, *entry.OdometerOut)
+		}
 	}
 
-	return s.db.CompleteJournalEntry(ctx, entryID, timeInParsed)
+	if err := s.db.CompleteJournalEntry(ctx, entryID, timeInParsed, arrival); err != nil {
+		return err
+	}
+	s.events.TripCompleted()
+	s.logger.InfoContext(ctx, "Trip completed", "journal_id", entryID)
+	return nil
 }
 
 func (s *AutoParkService) DeleteJournalEntry(ctx context.Context, entryID int) error {
 	if entryID <= 0 {
-		return errors.New("invalid entry ID")
+		return newValidationError("invalid entry ID")
 	}
 	return s.db.DeleteJournalEntry(ctx, entryID)
 }
 
+// Время приходит из HTML-форм (datetime-local) или из API в формате RFC 3339
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02T15:04", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // Методы для аналитики
 func (s *AutoParkService) GetRoutesVehicleCount(ctx context.Context) ([]models.RouteVehicleCount, error) {
 	return s.db.GetRoutesVehicleCount(ctx)
@@ -223,3 +415,47 @@ func (s *AutoParkService) RegisterUser(username, password string) error {
 
 	return nil
 }
+
+// Методы для работы с токенами доступа к API
+func (s *AutoParkService) CreateAPIToken(ctx context.Context, userID int, name string, expiresAt *time.Time) (string, *models.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, newValidationError("название токена обязательно")
+	}
+	if len([]rune(name)) > 100 {
+		return "", nil, newValidationError("название токена должно содержать не более 100 символов")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, newValidationError("срок действия токена должен быть в будущем")
+	}
+
+	token, tokenHash, err := auth.GenerateToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка генерации токена: %w", err)
+	}
+
+	apiToken, err := s.db.AddAPIToken(ctx, userID, name, tokenHash, expiresAt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, apiToken, nil
+}
+
+func (s *AutoParkService) GetAPITokens(ctx context.Context, userID int) ([]models.APIToken, error) {
+	return s.db.GetAPITokensByUserID(ctx, userID)
+}
+
+func (s *AutoParkService) RevokeAPIToken(ctx context.Context, userID, tokenID int) error {
+	if tokenID <= 0 {
+		return newValidationError("invalid token ID")
+	}
+	return s.db.RevokeAPIToken(ctx, userID, tokenID)
+}
+
+func (s *AutoParkService) AuthenticateAPIToken(ctx context.Context, token string) (*models.User, error) {
+	if !strings.HasPrefix(token, auth.TokenPrefix) {
+		return nil, fmt.Errorf("invalid api token")
+	}
+	return s.db.GetUserByAPITokenHash(ctx, auth.HashToken(token), time.Now())
+}
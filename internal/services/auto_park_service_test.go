@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/database/memory"
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Сервис поверх хранилища в памяти: водитель, закрепленный за автомобилем
+// с сегодняшнего дня, и маршрут
+type fixture struct {
+	db       *memory.MemoryDB
+	service  *AutoParkService
+	driverID int
+	autoID   int
+	routeID  int
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	ctx := context.Background()
+	db := memory.New()
+	service := NewAutoParkService(db, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	driverID, err := service.AddDriver(ctx, "Иван", "Иванов", "Иванович", models.DriverDocuments{})
+	if err != nil {
+		t.Fatalf("AddDriver: %v", err)
+	}
+	autoID, err := service.AddCar(ctx, "А123ВС77", "Белый", "ГАЗель", "B", driverID)
+	if err != nil {
+		t.Fatalf("AddCar: %v", err)
+	}
+	routeID, err := service.AddRoute(ctx, models.Route{StartPoint: "Депо", EndPoint: "Вокзал"})
+	if err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+	return fixture{db: db, service: service, driverID: driverID, autoID: autoID, routeID: routeID}
+}
+
+// Второй автомобиль с собственным основным водителем
+func (f fixture) addCar(t *testing.T, num string) (autoID, driverID int) {
+	t.Helper()
+	ctx := context.Background()
+	driverID, err := f.service.AddDriver(ctx, "Петр", "Петров", "", models.DriverDocuments{})
+	if err != nil {
+		t.Fatalf("AddDriver: %v", err)
+	}
+	autoID, err = f.service.AddCar(ctx, num, "Синий", "Ford", "B", driverID)
+	if err != nil {
+		t.Fatalf("AddCar: %v", err)
+	}
+	return autoID, driverID
+}
+
+// Время в формате HTML-формы, отсчитанное от полудня текущего дня:
+// закрепление основного водителя действует с сегодняшней даты
+func formTime(offset time.Duration) string {
+	return today().Add(12*time.Hour + offset).Format("2006-01-02T15:04")
+}
+
+func km(value int) *int {
+	return &value
+}
+
+func (f fixture) dispatch(t *testing.T, autoID, driverID int, timeOut string, odometer *int) int {
+	t.Helper()
+	entryID, err := f.service.AddJournalEntry(context.Background(), autoID, driverID, f.routeID, timeOut,
+		models.TripDeparture{Odometer: odometer})
+	if err != nil {
+		t.Fatalf("AddJournalEntry: %v", err)
+	}
+	return entryID
+}
+
+func (f fixture) complete(t *testing.T, entryID int, timeIn string, odometer *int) {
+	t.Helper()
+	err := f.service.CompleteJournalEntry(context.Background(), entryID, timeIn, models.TripReturn{Odometer: odometer})
+	if err != nil {
+		t.Fatalf("CompleteJournalEntry: %v", err)
+	}
+}
+
+func assertConflict(t *testing.T, err error, action string) {
+	t.Helper()
+	if !errors.Is(err, database.ErrConflict) {
+		t.Errorf("%s: got %v, want ErrConflict", action, err)
+	}
+}
+
+func TestDispatchRejectsArchived(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("car", func(t *testing.T) {
+		f := newFixture(t)
+		if err := f.service.ArchiveCar(ctx, f.autoID); err != nil {
+			t.Fatalf("ArchiveCar: %v", err)
+		}
+		_, err := f.service.AddJournalEntry(ctx, f.autoID, f.driverID, f.routeID, formTime(0), models.TripDeparture{})
+		assertConflict(t, err, "dispatch archived car")
+
+		if err := f.service.RestoreCar(ctx, f.autoID); err != nil {
+			t.Fatalf("RestoreCar: %v", err)
+		}
+		f.dispatch(t, f.autoID, f.driverID, formTime(0), nil)
+	})
+
+	t.Run("route", func(t *testing.T) {
+		f := newFixture(t)
+		if err := f.service.ArchiveRoute(ctx, f.routeID); err != nil {
+			t.Fatalf("ArchiveRoute: %v", err)
+		}
+		_, err := f.service.AddJournalEntry(ctx, f.autoID, f.driverID, f.routeID, formTime(0), models.TripDeparture{})
+		assertConflict(t, err, "dispatch on archived route")
+	})
+
+	t.Run("driver with cars", func(t *testing.T) {
+		f := newFixture(t)
+		if err := f.service.ArchiveDriver(ctx, f.driverID); err != nil {
+			t.Fatalf("ArchiveDriver: %v", err)
+		}
+		car, _, err := f.service.GetCarByID(ctx, f.autoID)
+		if err != nil {
+			t.Fatalf("GetCarByID: %v", err)
+		}
+		if car.ArchivedAt == nil {
+			t.Errorf("car of archived driver must be archived too")
+		}
+		_, err = f.service.AddJournalEntry(ctx, f.autoID, f.driverID, f.routeID, formTime(0), models.TripDeparture{})
+		assertConflict(t, err, "dispatch archived driver")
+		_, err = f.service.AddCar(ctx, "Е001КХ77", "Черный", "Lada", "B", f.driverID)
+		assertConflict(t, err, "add car for archived driver")
+	})
+}
+
+// Водителя и автомобиль, находящиеся в рейсе, нельзя перенести в архив
+func TestArchiveRejectsTripInProgress(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	entryID := f.dispatch(t, f.autoID, f.driverID, formTime(0), nil)
+
+	assertConflict(t, f.service.ArchiveDriver(ctx, f.driverID), "archive driver on trip")
+	assertConflict(t, f.service.ArchiveCar(ctx, f.autoID), "archive car on trip")
+
+	f.complete(t, entryID, formTime(time.Hour), nil)
+	if err := f.service.ArchiveDriver(ctx, f.driverID); err != nil {
+		t.Errorf("ArchiveDriver after trip: %v", err)
+	}
+}
+
+func TestDispatchRequiresAssignment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	_, otherDriverID := f.addCar(t, "В456ОР77")
+
+	_, err := f.service.AddJournalEntry(ctx, f.autoID, otherDriverID, f.routeID, formTime(0), models.TripDeparture{})
+	assertConflict(t, err, "dispatch unassigned driver")
+
+	tomorrow := today().AddDate(0, 0, 1)
+	if _, err := f.service.AddAssignment(ctx, models.Assignment{
+		AutoID: f.autoID, DriverID: otherDriverID, ValidFrom: tomorrow,
+	}); err != nil {
+		t.Fatalf("AddAssignment: %v", err)
+	}
+	_, err = f.service.AddJournalEntry(ctx, f.autoID, otherDriverID, f.routeID, formTime(0), models.TripDeparture{})
+	assertConflict(t, err, "dispatch before assignment starts")
+
+	entryID := f.dispatch(t, f.autoID, otherDriverID, tomorrow.Add(8*time.Hour).Format("2006-01-02T15:04"), nil)
+	entry, err := f.service.GetJournalEntryByID(ctx, entryID)
+	if err != nil {
+		t.Fatalf("GetJournalEntryByID: %v", err)
+	}
+	if entry.DriverID != otherDriverID {
+		t.Errorf("trip driver = %d, want %d", entry.DriverID, otherDriverID)
+	}
+}
+
+// Закрепление, по которому выполнен рейс, удалить нельзя, а сократить можно
+// только так, чтобы рейс остался в периоде
+func TestAssignmentWithTrips(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	entryID := f.dispatch(t, f.autoID, f.driverID, formTime(0), nil)
+	f.complete(t, entryID, formTime(time.Hour), nil)
+
+	assignments, err := f.service.GetAssignments(ctx, models.AssignmentFilter{AutoID: f.autoID, DriverID: f.driverID})
+	if err != nil || len(assignments) != 1 {
+		t.Fatalf("GetAssignments: %v, %d assignments", err, len(assignments))
+	}
+	assignmentID := assignments[0].ID
+
+	assertConflict(t, f.service.DeleteAssignment(ctx, assignmentID), "delete assignment with trips")
+	assertConflict(t, f.service.UpdateAssignmentPeriod(ctx, assignmentID, today().AddDate(0, 0, 1), nil),
+		"move assignment start past its trip")
+	if err := f.service.EndAssignment(ctx, assignmentID, today()); err != nil {
+		t.Errorf("EndAssignment: %v", err)
+	}
+}
+
+func TestDispatchRejectsDoubleBooking(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	otherAutoID, otherDriverID := f.addCar(t, "В456ОР77")
+	for _, assignment := range []models.Assignment{
+		{AutoID: otherAutoID, DriverID: f.driverID},
+		{AutoID: f.autoID, DriverID: otherDriverID},
+	} {
+		if _, err := f.service.AddAssignment(ctx, assignment); err != nil {
+			t.Fatalf("AddAssignment: %v", err)
+		}
+	}
+
+	entryID := f.dispatch(t, f.autoID, f.driverID, formTime(0), nil)
+
+	_, err := f.service.AddJournalEntry(ctx, otherAutoID, f.driverID, f.routeID, formTime(time.Hour), models.TripDeparture{})
+	assertConflict(t, err, "dispatch driver on trip")
+	_, err = f.service.AddJournalEntry(ctx, f.autoID, otherDriverID, f.routeID, formTime(time.Hour), models.TripDeparture{})
+	assertConflict(t, err, "dispatch car on trip")
+
+	f.complete(t, entryID, formTime(time.Hour), nil)
+	f.dispatch(t, otherAutoID, f.driverID, formTime(2*time.Hour), nil)
+	f.dispatch(t, f.autoID, otherDriverID, formTime(2*time.Hour), nil)
+}
+
+func TestCompleteRejectsArrivalBeforeDeparture(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	entryID := f.dispatch(t, f.autoID, f.driverID, formTime(0), nil)
+
+	err := f.service.CompleteJournalEntry(ctx, entryID, formTime(-time.Minute), models.TripReturn{})
+	assertConflict(t, err, "arrival before departure")
+
+	entry, err := f.service.GetJournalEntryByID(ctx, entryID)
+	if err != nil {
+		t.Fatalf("GetJournalEntryByID: %v", err)
+	}
+	if entry.TimeIn != nil {
+		t.Errorf("rejected arrival must not complete the trip")
+	}
+	f.complete(t, entryID, formTime(0), nil)
+}
+
+func TestOdometerMustNotDecrease(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	entryID := f.dispatch(t, f.autoID, f.driverID, formTime(0), km(10000))
+
+	var validation *ValidationError
+	err := f.service.CompleteJournalEntry(ctx, entryID, formTime(time.Hour), models.TripReturn{Odometer: km(9990)})
+	if !errors.As(err, &validation) {
+		t.Errorf("arrival odometer below departure: got %v, want ValidationError", err)
+	}
+	f.complete(t, entryID, formTime(time.Hour), km(10120))
+
+	_, err = f.service.AddJournalEntry(ctx, f.autoID, f.driverID, f.routeID, formTime(2*time.Hour),
+		models.TripDeparture{Odometer: km(10100)})
+	assertConflict(t, err, "departure odometer below previous trip")
+
+	// Рейс, внесенный задним числом, не может превышать показания более позднего рейса
+	_, err = f.service.AddJournalEntry(ctx, f.autoID, f.driverID, f.routeID, formTime(-2*time.Hour),
+		models.TripDeparture{Odometer: km(10050)})
+	assertConflict(t, err, "earlier trip odometer above later trip")
+
+	f.dispatch(t, f.autoID, f.driverID, formTime(2*time.Hour), km(10120))
+}
+
+// Смена основного водителя завершает закрепление прежнего текущей датой
+func TestUpdateCarReplacesPrimaryDriver(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	_, newDriverID := f.addCar(t, "В456ОР77")
+
+	if err := f.service.UpdateCar(ctx, f.autoID, "А123ВС77", "Белый", "ГАЗель", "B", newDriverID); err != nil {
+		t.Fatalf("UpdateCar: %v", err)
+	}
+
+	assignments, err := f.service.GetAssignments(ctx, models.AssignmentFilter{AutoID: f.autoID})
+	if err != nil {
+		t.Fatalf("GetAssignments: %v", err)
+	}
+	periods := map[int]models.Assignment{}
+	for _, assignment := range assignments {
+		periods[assignment.DriverID] = assignment
+	}
+	if previous := periods[f.driverID]; previous.ValidTo == nil || !previous.ValidTo.Equal(today()) {
+		t.Errorf("previous primary driver valid to %v, want %v", previous.ValidTo, today())
+	}
+	if current, ok := periods[newDriverID]; !ok || current.ValidTo != nil {
+		t.Errorf("new primary driver assignment = %+v, want open-ended", current)
+	}
+
+	tomorrow := formTime(24 * time.Hour)
+	_, err = f.service.AddJournalEntry(ctx, f.autoID, f.driverID, f.routeID, tomorrow, models.TripDeparture{})
+	assertConflict(t, err, "dispatch previous primary driver after replacement")
+	f.dispatch(t, f.autoID, 0, tomorrow, nil)
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Документы, срок действия которых истекает в ближайшие ExpiringDocumentsDays дней,
+// попадают в предупреждения на главной странице
+const ExpiringDocumentsDays = 30
+
+const maxDocumentFieldLength = 20
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9()\- ]+$`)
+
+// Проверка и нормализация документов водителя: категории приводятся
+// к написанию из models.LicenseCategories и упорядочиваются как в удостоверении
+func normalizeDocuments(docs *models.DriverDocuments) error {
+	docs.Phone = strings.TrimSpace(docs.Phone)
+	docs.LicenseNumber = strings.TrimSpace(docs.LicenseNumber)
+
+	if tooLong(maxDocumentFieldLength, docs.Phone, docs.LicenseNumber) {
+		return newValidationError("телефон и номер удостоверения не должны превышать %d символов", maxDocumentFieldLength)
+	}
+	if docs.Phone != "" && !phonePattern.MatchString(docs.Phone) {
+		return newValidationError("некорректный номер телефона: %s", docs.Phone)
+	}
+
+	categories := make([]string, 0, len(docs.LicenseCategories))
+	for _, value := range docs.LicenseCategories {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		category, ok := licenseCategory(value)
+		if !ok {
+			return newValidationError("неизвестная категория водительского удостоверения: %s", value)
+		}
+		if !containsString(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categoryIndex(categories[i]) < categoryIndex(categories[j])
+	})
+	docs.LicenseCategories = categories
+
+	if len(categories) > 0 && docs.LicenseNumber == "" {
+		return newValidationError("укажите номер водительского удостоверения")
+	}
+	if docs.LicenseIssuedAt != nil && docs.LicenseIssuedAt.After(time.Now()) {
+		return newValidationError("дата выдачи удостоверения не может быть в будущем")
+	}
+	if docs.LicenseIssuedAt != nil && docs.LicenseExpiresAt != nil && !docs.LicenseIssuedAt.Before(*docs.LicenseExpiresAt) {
+		return newValidationError("дата выдачи удостоверения должна быть раньше даты окончания действия")
+	}
+	return nil
+}
+
+// Категория прав, необходимая для управления автомобилем; по умолчанию — B
+func normalizeAutoCategory(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return models.DefaultAutoCategory, nil
+	}
+	category, ok := licenseCategory(value)
+	if !ok {
+		return "", newValidationError("неизвестная категория автомобиля: %s", value)
+	}
+	return category, nil
+}
+
+func licenseCategory(value string) (string, bool) {
+	for _, category := range models.LicenseCategories {
+		if strings.EqualFold(category, value) {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+func categoryIndex(category string) int {
+	for i, c := range models.LicenseCategories {
+		if c == category {
+			return i
+		}
+	}
+	return len(models.LicenseCategories)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Истекшие и истекающие в ближайшие ExpiringDocumentsDays дней документы
+// действующих водителей, начиная с ближайшего срока
+func (s *AutoParkService) GetExpiringDocuments(ctx context.Context, at time.Time) ([]models.ExpiringDocument, error) {
+	drivers, err := s.db.GetDrivers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load drivers: %w", err)
+	}
+
+	today := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	limit := today.AddDate(0, 0, ExpiringDocumentsDays)
+
+	documents := []models.ExpiringDocument{}
+	for _, driver := range drivers {
+		expiring := map[string]*time.Time{
+			models.DocumentLicense: driver.LicenseExpiresAt,
+			models.DocumentMedical: driver.MedicalExpiresAt,
+		}
+		for document, expiresAt := range expiring {
+			if expiresAt == nil || expiresAt.After(limit) {
+				continue
+			}
+			documents = append(documents, models.ExpiringDocument{
+				DriverID:   driver.ID,
+				DriverName: strings.TrimSpace(driver.LastName + " " + driver.FirstName + " " + driver.FatherName),
+				Document:   document,
+				ExpiresAt:  *expiresAt,
+				DaysLeft:   int(expiresAt.Sub(today).Hours() / 24),
+			})
+		}
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		if !documents[i].ExpiresAt.Equal(documents[j].ExpiresAt) {
+			return documents[i].ExpiresAt.Before(documents[j].ExpiresAt)
+		}
+		if documents[i].DriverName != documents[j].DriverName {
+			return documents[i].DriverName < documents[j].DriverName
+		}
+		return documents[i].Document < documents[j].Document
+	})
+	return documents, nil
+}
+
+// Водитель рейса должен иметь категорию, соответствующую автомобилю,
+// и действующие удостоверение и медицинскую справку на дату отправления.
+// Водители, для которых удостоверение еще не внесено, не проверяются.
+func (s *AutoParkService) checkDriverBeforeDispatch(ctx context.Context, autoID, driverID int, at time.Time) error {
+	car, _, err := s.db.GetCarByID(ctx, autoID)
+	if errors.Is(err, database.ErrNotFound) {
+		// Несуществующий автомобиль отклонит внешний ключ журнала
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	driver, err := s.db.GetDriverByID(ctx, driverID)
+	if errors.Is(err, database.ErrNotFound) {
+		// Несуществующего водителя отклонит внешний ключ журнала
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if driver.LicenseNumber == "" && len(driver.LicenseCategories) == 0 {
+		return nil
+	}
+
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	name := strings.TrimSpace(driver.LastName + " " + driver.FirstName)
+	switch {
+	case !driver.HasCategory(car.Category):
+		return fmt.Errorf("%w: у водителя %s нет категории %s, необходимой для автомобиля %s",
+			database.ErrConflict, name, car.Category, car.Num)
+	case driver.LicenseExpiresAt != nil && driver.LicenseExpiresAt.Before(day):
+		return fmt.Errorf("%w: у водителя %s истек срок действия водительского удостоверения (%s)",
+			database.ErrConflict, name, driver.LicenseExpiresAt.Format("02.01.2006"))
+	case driver.MedicalExpiresAt != nil && driver.MedicalExpiresAt.Before(day):
+		return fmt.Errorf("%w: у водителя %s истек срок действия медицинской справки (%s)",
+			database.ErrConflict, name, driver.MedicalExpiresAt.Format("02.01.2006"))
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Перерыв между рейсами длиннее этого считается отдыхом между сменами, а не простоем
+const MaxIdleGap = 8 * time.Hour
+
+// Рейтинг водителей по рейсам, попавшим в фильтр журнала. Без фильтра по водителю
+// в отчет попадают и действующие водители без рейсов за период.
+func (s *AutoParkService) GetDriverReport(ctx context.Context, filter models.JournalFilter, rankBy string) (*models.DriverReport, error) {
+	switch rankBy {
+	case "":
+		rankBy = models.DriverRankHours
+	case models.DriverRankHours, models.DriverRankTrips, models.DriverRankPunctuality,
+		models.DriverRankIdle, models.DriverRankUtilisation:
+	default:
+		return nil, newValidationError("unknown driver ranking %q", rankBy)
+	}
+
+	entries, err := s.driverReportEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	byDriver := map[int][]models.JournalView{}
+	names := map[int]string{}
+	for _, entry := range entries {
+		byDriver[entry.DriverID] = append(byDriver[entry.DriverID], entry)
+		names[entry.DriverID] = entry.DriverName
+	}
+	if filter.DriverID == 0 {
+		drivers, err := s.GetDrivers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, driver := range drivers {
+			if _, ok := names[driver.ID]; !ok {
+				names[driver.ID] = journalDriverName(driver)
+			}
+		}
+	}
+
+	report := &models.DriverReport{RankBy: rankBy, Drivers: make([]models.DriverPerformance, 0, len(names))}
+	for driverID, name := range names {
+		performance, _ := driverPerformance(driverID, name, byDriver[driverID])
+		report.Drivers = append(report.Drivers, performance)
+	}
+	rankDrivers(report.Drivers, rankBy)
+	return report, nil
+}
+
+// Показатели одного водителя и его рейсы за период
+func (s *AutoParkService) GetDriverReportDetail(ctx context.Context, driverID int, filter models.JournalFilter) (*models.DriverReportDetail, error) {
+	driver, err := s.GetDriverByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	filter.DriverID = driverID
+	entries, err := s.driverReportEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	performance, trips := driverPerformance(driverID, journalDriverName(*driver), entries)
+	return &models.DriverReportDetail{Driver: performance, Trips: trips}, nil
+}
+
+// Имя водителя в том виде, в каком его показывает журнал
+func journalDriverName(driver models.AutoPersonal) string {
+	return driver.FirstName + " " + driver.LastName
+}
+
+// Рейсы в хронологическом порядке, чтобы считать простой между соседними рейсами
+func (s *AutoParkService) driverReportEntries(ctx context.Context, filter models.JournalFilter) ([]models.JournalView, error) {
+	filter.SortBy = models.JournalSortTimeOut
+	filter.SortDesc = false
+	return s.GetAllJournalEntries(ctx, filter)
+}
+
+// Показатели по рейсам одного водителя, упорядоченным по времени отправления
+func driverPerformance(driverID int, name string, entries []models.JournalView) (models.DriverPerformance, []models.DriverTrip) {
+	performance := models.DriverPerformance{DriverID: driverID, DriverName: name, Trips: len(entries)}
+	trips := make([]models.DriverTrip, 0, len(entries))
+	var previousIn *time.Time
+	var totalMinutes float64
+
+	for _, entry := range entries {
+		trip := models.DriverTrip{JournalView: entry, DurationMinutes: entry.DurationMinutes()}
+		if previousIn != nil {
+			gap := entry.TimeOut.Sub(*previousIn)
+			if gap >= 0 && gap <= MaxIdleGap {
+				idle := gap.Minutes()
+				trip.IdleBeforeMinutes = &idle
+				performance.IdleHours += gap.Hours()
+			}
+		}
+		previousIn = entry.TimeIn
+
+		if trip.DurationMinutes != nil {
+			performance.CompletedTrips++
+			totalMinutes += *trip.DurationMinutes
+			if delay, late, known := tripDelay(entry); known {
+				trip.DelayMinutes = &delay
+				trip.Late = late
+				performance.PlannedTrips++
+				if !late {
+					performance.OnTimeTrips++
+				}
+			}
+		}
+		trips = append(trips, trip)
+	}
+
+	if performance.CompletedTrips > 0 {
+		average := round1(totalMinutes / float64(performance.CompletedTrips))
+		performance.AverageMinutes = &average
+	}
+	if performance.PlannedTrips > 0 {
+		punctuality := round1(float64(performance.OnTimeTrips) / float64(performance.PlannedTrips) * 100)
+		performance.PunctualityPercent = &punctuality
+	}
+	if busy := totalMinutes/60 + performance.IdleHours; busy > 0 {
+		utilisation := round1(totalMinutes / 60 / busy * 100)
+		performance.UtilisationPercent = &utilisation
+	}
+	performance.TotalHours = round1(totalMinutes / 60)
+	performance.IdleHours = round1(performance.IdleHours)
+	return performance, trips
+}
+
+// Сортировка по убыванию выбранного показателя с присвоением мест; водители без
+// значения показателя идут в конце
+func rankDrivers(drivers []models.DriverPerformance, rankBy string) {
+	value := func(d models.DriverPerformance) (float64, bool) {
+		switch rankBy {
+		case models.DriverRankTrips:
+			return float64(d.Trips), true
+		case models.DriverRankPunctuality:
+			return optionalValue(d.PunctualityPercent)
+		case models.DriverRankIdle:
+			return d.IdleHours, true
+		case models.DriverRankUtilisation:
+			return optionalValue(d.UtilisationPercent)
+		}
+		return d.TotalHours, true
+	}
+	sort.SliceStable(drivers, func(i, j int) bool {
+		vi, oki := value(drivers[i])
+		vj, okj := value(drivers[j])
+		if oki != okj {
+			return oki
+		}
+		if vi != vj {
+			return vi > vj
+		}
+		return drivers[i].DriverName < drivers[j].DriverName
+	})
+	for i := range drivers {
+		drivers[i].Rank = i + 1
+	}
+}
+
+func optionalValue(value *float64) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	return *value, true
+}
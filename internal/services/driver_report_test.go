@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Показатели по рейсам одного водителя: простой считается только между
+// соседними рейсами в пределах смены, пунктуальность — по рейсам с планом
+func TestDriverPerformance(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	planned := 60
+	trip := func(from, to time.Duration, planned *int) models.JournalView {
+		entry := models.JournalView{TimeOut: day.Add(from), PlannedMinutes: planned}
+		if to > 0 {
+			timeIn := day.Add(to)
+			entry.TimeIn = &timeIn
+		}
+		return entry
+	}
+	entries := []models.JournalView{
+		trip(8*time.Hour, 9*time.Hour, &planned),
+		trip(9*time.Hour+30*time.Minute, 11*time.Hour, &planned),
+		// Перерыв больше MaxIdleGap — следующая смена
+		trip(20*time.Hour, 20*time.Hour+30*time.Minute, nil),
+		trip(21*time.Hour, 0, &planned),
+	}
+
+	performance, trips := driverPerformance(7, "Иван Иванов", entries)
+	if performance.DriverID != 7 || performance.Trips != 4 || performance.CompletedTrips != 3 ||
+		performance.PlannedTrips != 2 || performance.OnTimeTrips != 1 {
+		t.Errorf("counters = %+v", performance)
+	}
+	for _, tc := range []struct {
+		name string
+		got  *float64
+		want float64
+	}{
+		{"average minutes", performance.AverageMinutes, 60},
+		{"punctuality", performance.PunctualityPercent, 50},
+		{"utilisation", performance.UtilisationPercent, 75},
+		{"total hours", &performance.TotalHours, 3},
+		{"idle hours", &performance.IdleHours, 1},
+		{"second trip idle", trips[1].IdleBeforeMinutes, 30},
+		{"second trip delay", trips[1].DelayMinutes, 30},
+	} {
+		if tc.got == nil || *tc.got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+	if trips[0].IdleBeforeMinutes != nil || trips[2].IdleBeforeMinutes != nil {
+		t.Errorf("idle before first trip of a shift = %v, %v, want none", trips[0].IdleBeforeMinutes, trips[2].IdleBeforeMinutes)
+	}
+	if !trips[1].Late || trips[0].Late || trips[2].DelayMinutes != nil {
+		t.Errorf("lateness = %v %v %v, want only the second trip late", trips[0].Late, trips[1].Late, trips[2].DelayMinutes)
+	}
+
+	empty, _ := driverPerformance(8, "Петр Петров", nil)
+	if empty.AverageMinutes != nil || empty.PunctualityPercent != nil || empty.UtilisationPercent != nil {
+		t.Errorf("driver without trips = %+v, want no averages", empty)
+	}
+}
+
+// Водители без значения показателя — в конце, при равенстве — по имени
+func TestRankDrivers(t *testing.T) {
+	percent := func(value float64) *float64 { return &value }
+	drivers := []models.DriverPerformance{
+		{DriverName: "Сидоров", Trips: 2, PunctualityPercent: percent(80)},
+		{DriverName: "Петров", Trips: 0},
+		{DriverName: "Иванов", Trips: 2, PunctualityPercent: percent(100)},
+		{DriverName: "Алексеев", Trips: 5, PunctualityPercent: percent(80)},
+	}
+	for _, tc := range []struct {
+		rankBy string
+		want   []string
+	}{
+		{models.DriverRankPunctuality, []string{"Иванов", "Алексеев", "Сидоров", "Петров"}},
+		{models.DriverRankTrips, []string{"Алексеев", "Иванов", "Сидоров", "Петров"}},
+	} {
+		rankDrivers(drivers, tc.rankBy)
+		for i, driver := range drivers {
+			if driver.DriverName != tc.want[i] || driver.Rank != i+1 {
+				t.Errorf("%s: place %d = %s (rank %d), want %s", tc.rankBy, i+1, driver.DriverName, driver.Rank, tc.want[i])
+			}
+		}
+	}
+}
+
+// В отчет без фильтра по водителю попадают и водители без рейсов за период
+func TestGetDriverReport(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	_, idleDriverID := f.addCar(t, "В456ОР77")
+	entryID := f.dispatch(t, f.autoID, f.driverID, formTime(-2*time.Hour), nil)
+	f.complete(t, entryID, formTime(-30*time.Minute), nil)
+
+	report, err := f.service.GetDriverReport(ctx, models.JournalFilter{}, "")
+	if err != nil {
+		t.Fatalf("GetDriverReport: %v", err)
+	}
+	if report.RankBy != models.DriverRankHours || len(report.Drivers) != 2 {
+		t.Fatalf("report = %+v, want two drivers ranked by hours", report)
+	}
+	if first := report.Drivers[0]; first.DriverID != f.driverID || first.TotalHours != 1.5 || first.CompletedTrips != 1 {
+		t.Errorf("first place = %+v, want driver %d with 1.5 hours", first, f.driverID)
+	}
+	if second := report.Drivers[1]; second.DriverID != idleDriverID || second.Trips != 0 {
+		t.Errorf("second place = %+v, want driver %d without trips", second, idleDriverID)
+	}
+
+	report, err = f.service.GetDriverReport(ctx, models.JournalFilter{DriverID: f.driverID}, models.DriverRankTrips)
+	if err != nil {
+		t.Fatalf("GetDriverReport(driver): %v", err)
+	}
+	if len(report.Drivers) != 1 {
+		t.Errorf("report filtered by driver has %d drivers, want 1", len(report.Drivers))
+	}
+
+	var validationErr *ValidationError
+	if _, err := f.service.GetDriverReport(ctx, models.JournalFilter{}, "salary"); !errors.As(err, &validationErr) {
+		t.Errorf("unknown ranking: got %v, want validation error", err)
+	}
+}
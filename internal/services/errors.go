@@ -0,0 +1,16 @@
+package services
+
+import "fmt"
+
+// Ошибка проверки входных данных
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Этапы, на которых отклоняется отправка в рейс
+const (
+	DispatchStageCheck    = "check"
+	DispatchStageDatabase = "database"
+)
+
+// Получатель бизнес-событий сервиса, например счетчиков метрик
+type EventRecorder interface {
+	TripStarted()
+	TripCompleted()
+	DispatchRejected(stage string)
+}
+
+type noopRecorder struct{}
+
+func (noopRecorder) TripStarted()            {}
+func (noopRecorder) TripCompleted()          {}
+func (noopRecorder) DispatchRejected(string) {}
+
+func (s *AutoParkService) SetEventRecorder(recorder EventRecorder) {
+	s.events = recorder
+}
+
+// Автомобили в рейсе: незавершенные записи журнала. Один автомобиль не может
+// быть в двух рейсах одновременно, поэтому число рейсов равно числу автомобилей.
+func (s *AutoParkService) CountCarsOnTrip(ctx context.Context) (float64, error) {
+	page, err := s.db.GetJournalEntries(ctx, models.JournalFilter{Status: models.JournalStatusInProgress, Page: 1, PageSize: 1})
+	if err != nil {
+		return 0, err
+	}
+	return float64(page.Total), nil
+}
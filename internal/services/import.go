@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Ссылка на водителя при разборе имени из файла импорта:
+// существующий водитель (personalID) или строка того же импорта (index)
+type driverRef struct {
+	personalID int
+	index      int
+}
+
+// Проверка и импорт справочников из файла. Строки проверяются по тем же правилам,
+// что и при добавлении через формы. Если найдена хотя бы одна ошибка или dryRun = true,
+// данные не сохраняются; иначе все строки записываются в одной транзакции.
+func (s *AutoParkService) ImportData(ctx context.Context, batch *models.ImportBatch, dryRun bool) (*models.ImportResult, error) {
+	if len(batch.Drivers)+len(batch.Autos)+len(batch.Routes) == 0 {
+		return nil, newValidationError("файл не содержит данных для импорта")
+	}
+
+	drivers, err := s.db.GetDrivers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load drivers: %w", err)
+	}
+	cars, err := s.db.GetCars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cars: %w", err)
+	}
+	archive, err := s.db.GetArchive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archive: %w", err)
+	}
+
+	result := &models.ImportResult{ImportBatch: *batch, DryRun: dryRun}
+	s.validateImport(&result.ImportBatch, drivers, cars, archive.Autos)
+	result.ErrorCount = countImportErrors(&result.ImportBatch)
+
+	if dryRun || result.ErrorCount > 0 {
+		return result, nil
+	}
+
+	if err := s.db.ImportData(ctx, &result.ImportBatch); err != nil {
+		return nil, fmt.Errorf("не удалось выполнить импорт: %w", err)
+	}
+	result.Committed = true
+	return result, nil
+}
+
+func (s *AutoParkService) validateImport(batch *models.ImportBatch, drivers []models.AutoPersonal, cars, archivedCars []models.Auto) {
+	names := make(map[string][]driverRef)
+	addName := func(ref driverRef, firstName, lastName, fatherName string) {
+		keys := []string{lastName + " " + firstName, firstName + " " + lastName}
+		if fatherName != "" {
+			keys = append(keys, lastName+" "+firstName+" "+fatherName, firstName+" "+fatherName+" "+lastName)
+		}
+		seen := make(map[string]bool)
+		for _, key := range keys {
+			key = normalizeName(key)
+			if !seen[key] {
+				seen[key] = true
+				names[key] = append(names[key], ref)
+			}
+		}
+	}
+
+	for _, driver := range drivers {
+		addName(driverRef{personalID: driver.ID}, driver.FirstName, driver.LastName, driver.FatherName)
+	}
+	for i := range batch.Drivers {
+		row := &batch.Drivers[i]
+		row.Errors = appendValidation(row.Errors, validateDriver(row.FirstName, row.LastName, row.FatherName))
+		addName(driverRef{index: i}, row.FirstName, row.LastName, row.FatherName)
+	}
+
+	// 0 — номер занят действующим автомобилем, -1 — архивным, иначе строка файла
+	nums := make(map[string]int)
+	for _, car := range cars {
+		nums[strings.ToUpper(car.Num)] = 0
+	}
+	for _, car := range archivedCars {
+		nums[strings.ToUpper(car.Num)] = -1
+	}
+	for i := range batch.Autos {
+		row := &batch.Autos[i]
+		row.Errors = appendValidation(row.Errors, validateCar(row.Num, row.Color, row.Mark))
+
+		num := strings.ToUpper(row.Num)
+		if previous, ok := nums[num]; ok && row.Num != "" {
+			switch {
+			case previous == 0:
+				row.Errors = append(row.Errors, fmt.Sprintf("автомобиль с номером %s уже существует", row.Num))
+			case previous < 0:
+				row.Errors = append(row.Errors, fmt.Sprintf("автомобиль с номером %s находится в архиве", row.Num))
+			default:
+				row.Errors = append(row.Errors, fmt.Sprintf("номер %s повторяется в строке %d", row.Num, previous))
+			}
+		} else {
+			nums[num] = row.Row
+		}
+
+		refs := names[normalizeName(row.DriverName)]
+		switch {
+		case row.DriverName == "":
+			row.Errors = append(row.Errors, "не указан водитель")
+		case len(refs) == 0:
+			row.Errors = append(row.Errors, fmt.Sprintf("водитель «%s» не найден", row.DriverName))
+		case len(refs) > 1:
+			row.Errors = append(row.Errors, fmt.Sprintf("найдено несколько водителей с именем «%s»", row.DriverName))
+		case refs[0].personalID != 0:
+			row.PersonalID = refs[0].personalID
+		default:
+			row.DriverIndex = refs[0].index
+			if driver := batch.Drivers[row.DriverIndex]; len(driver.Errors) > 0 {
+				row.Errors = append(row.Errors, fmt.Sprintf("водитель в строке %d содержит ошибки", driver.Row))
+			}
+		}
+	}
+
+	for i := range batch.Routes {
+		row := &batch.Routes[i]
+		row.Errors = appendValidation(row.Errors, validateRoute(row.StartPoint, row.EndPoint))
+	}
+}
+
+func appendValidation(messages []string, err error) []string {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return append(messages, validationErr.Message)
+	}
+	return messages
+}
+
+func countImportErrors(batch *models.ImportBatch) int {
+	count := 0
+	for _, row := range batch.Drivers {
+		count += len(row.Errors)
+	}
+	for _, row := range batch.Autos {
+		count += len(row.Errors)
+	}
+	for _, row := range batch.Routes {
+		count += len(row.Errors)
+	}
+	return count
+}
+
+// Имя без учета регистра, лишних пробелов и различия «е»/«ё»
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	return strings.ReplaceAll(name, "ё", "е")
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"AutoParkWeb/internal/models"
+)
+
+func importBatch() *models.ImportBatch {
+	return &models.ImportBatch{
+		Drivers: []models.ImportDriver{{Row: 2, FirstName: "Петр", LastName: "Сидоров"}},
+		Autos: []models.ImportAuto{
+			// Водитель из того же файла и существующий водитель, имя без учета регистра и лишних пробелов
+			{Row: 2, Num: "В456ОР77", Color: "Синий", Mark: "Ford", DriverName: "Сидоров Петр"},
+			{Row: 3, Num: "Е789КХ77", Color: "Серый", Mark: "Lada", DriverName: "иван  иванов"},
+		},
+		Routes: []models.ImportRoute{{Row: 2, StartPoint: "Депо", EndPoint: "Аэропорт"}},
+	}
+}
+
+func importCounts(t *testing.T, f fixture) (drivers, cars, routes int) {
+	t.Helper()
+	ctx := context.Background()
+	driverList, err := f.service.GetDrivers(ctx)
+	if err != nil {
+		t.Fatalf("GetDrivers: %v", err)
+	}
+	carList, err := f.service.GetCars(ctx)
+	if err != nil {
+		t.Fatalf("GetCars: %v", err)
+	}
+	routeList, err := f.service.GetRoutes(ctx)
+	if err != nil {
+		t.Fatalf("GetRoutes: %v", err)
+	}
+	return len(driverList), len(carList), len(routeList)
+}
+
+// Предварительная проверка ничего не сохраняет, импорт добавляет все строки
+// и связывает автомобиль с водителем из того же файла
+func TestImportDataDryRun(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	result, err := f.service.ImportData(ctx, importBatch(), true)
+	if err != nil {
+		t.Fatalf("ImportData(dry run): %v", err)
+	}
+	if !result.DryRun || result.Committed || result.ErrorCount != 0 {
+		t.Errorf("dry run result: dry_run %v committed %v errors %d", result.DryRun, result.Committed, result.ErrorCount)
+	}
+	if drivers, cars, routes := importCounts(t, f); drivers != 1 || cars != 1 || routes != 1 {
+		t.Errorf("after dry run: %d drivers, %d cars, %d routes, want nothing added", drivers, cars, routes)
+	}
+
+	result, err = f.service.ImportData(ctx, importBatch(), false)
+	if err != nil {
+		t.Fatalf("ImportData: %v", err)
+	}
+	if !result.Committed || result.ErrorCount != 0 {
+		t.Errorf("import result: committed %v errors %d", result.Committed, result.ErrorCount)
+	}
+	if drivers, cars, routes := importCounts(t, f); drivers != 2 || cars != 3 || routes != 2 {
+		t.Errorf("after import: %d drivers, %d cars, %d routes, want 2, 3, 2", drivers, cars, routes)
+	}
+
+	cars, err := f.service.GetCars(ctx)
+	if err != nil {
+		t.Fatalf("GetCars: %v", err)
+	}
+	owners := make(map[string]int)
+	for _, car := range cars {
+		owners[car.Num] = car.PersonalID
+	}
+	if owners["Е789КХ77"] != f.driverID {
+		t.Errorf("Е789КХ77 driver = %d, want existing driver %d", owners["Е789КХ77"], f.driverID)
+	}
+	if id := owners["В456ОР77"]; id == 0 || id == f.driverID {
+		t.Errorf("В456ОР77 driver = %d, want the imported driver", id)
+	}
+}
+
+// Ошибки привязываются к строкам файла; при ошибках ничего не сохраняется
+func TestImportDataRowErrors(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	batch := &models.ImportBatch{
+		Drivers: []models.ImportDriver{
+			{Row: 2, FirstName: "Петр", LastName: "Сидоров"},
+			{Row: 3, FirstName: "Олег"},
+		},
+		Autos: []models.ImportAuto{
+			{Row: 2, Num: "а123вс77", Color: "Белый", Mark: "ГАЗель", DriverName: "Петр Сидоров"},
+			{Row: 3, Num: "В456ОР77", Color: "Синий", Mark: "Ford", DriverName: "Семен Семенов"},
+			{Row: 4, Num: "в456ор77", Color: "Синий", Mark: "Ford", DriverName: "Олег"},
+			{Row: 5, Num: "К001КК77", Color: "Синий", Mark: "Ford"},
+		},
+		Routes: []models.ImportRoute{{Row: 2, StartPoint: "Депо"}},
+	}
+
+	result, err := f.service.ImportData(ctx, batch, false)
+	if err != nil {
+		t.Fatalf("ImportData: %v", err)
+	}
+	if result.Committed {
+		t.Errorf("import with row errors must not be committed")
+	}
+
+	for _, tc := range []struct {
+		row    string
+		errors []string
+		want   []string
+	}{
+		{"driver 2", result.Drivers[0].Errors, nil},
+		{"driver 3", result.Drivers[1].Errors, []string{"first name and last name are required"}},
+		{"auto 2", result.Autos[0].Errors, []string{"автомобиль с номером а123вс77 уже существует"}},
+		{"auto 3", result.Autos[1].Errors, []string{"водитель «Семен Семенов» не найден"}},
+		{"auto 4", result.Autos[2].Errors, []string{"номер в456ор77 повторяется в строке 3", "водитель в строке 3 содержит ошибки"}},
+		{"auto 5", result.Autos[3].Errors, []string{"не указан водитель"}},
+		{"route 2", result.Routes[0].Errors, []string{"both startPoint and endPoint are required"}},
+	} {
+		if !reflect.DeepEqual(tc.errors, tc.want) {
+			t.Errorf("%s: errors %q, want %q", tc.row, tc.errors, tc.want)
+		}
+	}
+	if result.ErrorCount != 7 {
+		t.Errorf("error count = %d, want 7", result.ErrorCount)
+	}
+	if drivers, cars, routes := importCounts(t, f); drivers != 1 || cars != 1 || routes != 1 {
+		t.Errorf("after rejected import: %d drivers, %d cars, %d routes, want nothing added", drivers, cars, routes)
+	}
+
+	if _, err := f.service.ImportData(ctx, &models.ImportBatch{}, true); err == nil {
+		t.Errorf("ImportData(empty batch) succeeded, want validation error")
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Страницы журнала с сортировкой и фильтром по статусу; значения по
+// умолчанию подставляются сервисом, недопустимые отклоняются
+func TestGetJournalEntries(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	secondAutoID, secondDriverID := f.addCar(t, "В456ОР77")
+
+	first := f.dispatch(t, f.autoID, f.driverID, formTime(-3*time.Hour), nil)
+	f.complete(t, first, formTime(-2*time.Hour), nil)
+	second := f.dispatch(t, secondAutoID, secondDriverID, formTime(-150*time.Minute), nil)
+	f.complete(t, second, formTime(-100*time.Minute), nil)
+	third := f.dispatch(t, f.autoID, f.driverID, formTime(-time.Hour), nil)
+
+	journalIDs := func(page *models.JournalPage) []int {
+		ids := make([]int, 0, len(page.Entries))
+		for _, entry := range page.Entries {
+			ids = append(ids, entry.JournalID)
+		}
+		return ids
+	}
+
+	for _, tc := range []struct {
+		name     string
+		filter   models.JournalFilter
+		total    int
+		page     int
+		pageSize int
+		ids      []int
+	}{
+		{"defaults", models.JournalFilter{}, 3, 1, DefaultJournalPageSize, []int{first, second, third}},
+		{"newest first", models.JournalFilter{SortDesc: true, PageSize: 2}, 3, 1, 2, []int{third, second}},
+		{"second page", models.JournalFilter{SortDesc: true, Page: 2, PageSize: 2}, 3, 2, 2, []int{first}},
+		{"page past the end", models.JournalFilter{Page: 5, PageSize: 2}, 3, 5, 2, []int{}},
+		{"in progress", models.JournalFilter{Status: models.JournalStatusInProgress}, 1, 1, DefaultJournalPageSize, []int{third}},
+		{"by auto", models.JournalFilter{AutoNumber: " В456 ", SortBy: models.JournalSortTimeIn}, 1, 1, DefaultJournalPageSize, []int{second}},
+	} {
+		page, err := f.service.GetJournalEntries(ctx, tc.filter)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if page.Total != tc.total || page.Page != tc.page || page.PageSize != tc.pageSize {
+			t.Errorf("%s: total %d page %d size %d, want %d %d %d", tc.name, page.Total, page.Page, page.PageSize, tc.total, tc.page, tc.pageSize)
+		}
+		if got := journalIDs(page); !slices.Equal(got, tc.ids) {
+			t.Errorf("%s: entries %v, want %v", tc.name, got, tc.ids)
+		}
+	}
+
+	from := today()
+	for _, tc := range []struct {
+		name   string
+		filter models.JournalFilter
+	}{
+		{"unknown sort", models.JournalFilter{SortBy: "cost"}},
+		{"unknown status", models.JournalFilter{Status: "lost"}},
+		{"page size over limit", models.JournalFilter{PageSize: MaxJournalPageSize + 1}},
+		{"empty interval", models.JournalFilter{TimeOutFrom: &from, TimeOutTo: &from}},
+	} {
+		var validationErr *ValidationError
+		if _, err := f.service.GetJournalEntries(ctx, tc.filter); !errors.As(err, &validationErr) {
+			t.Errorf("%s: got %v, want validation error", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Обслуживание считается предстоящим, если до срока осталось
+// не больше UpcomingServiceDays дней или UpcomingServiceKm километров
+const (
+	UpcomingServiceDays = 14
+	UpcomingServiceKm   = 1000
+)
+
+func validateMaintenanceType(serviceType string) error {
+	if strings.TrimSpace(serviceType) == "" {
+		return newValidationError("не указан вид обслуживания")
+	}
+	if utf8.RuneCountInString(serviceType) > 50 {
+		return newValidationError("вид обслуживания не должен превышать 50 символов")
+	}
+	return nil
+}
+
+// Методы для работы с техническим обслуживанием
+func (s *AutoParkService) GetMaintenanceRecords(ctx context.Context, autoID int) ([]models.MaintenanceRecord, error) {
+	return s.db.GetMaintenanceRecords(ctx, autoID)
+}
+
+func (s *AutoParkService) AddMaintenanceRecord(ctx context.Context, record models.MaintenanceRecord) (int, error) {
+	record.Type = strings.TrimSpace(record.Type)
+	if record.AutoID <= 0 {
+		return 0, newValidationError("не выбран автомобиль")
+	}
+	if err := validateMaintenanceType(record.Type); err != nil {
+		return 0, err
+	}
+	if record.PerformedAt.IsZero() {
+		return 0, newValidationError("не указана дата обслуживания")
+	}
+	if record.PerformedAt.After(time.Now()) {
+		return 0, newValidationError("дата обслуживания не может быть в будущем")
+	}
+	if record.Odometer != nil && *record.Odometer < 0 {
+		return 0, newValidationError("пробег не может быть отрицательным")
+	}
+	if record.Cost < 0 {
+		return 0, newValidationError("стоимость не может быть отрицательной")
+	}
+	if utf8.RuneCountInString(record.Notes) > 1000 {
+		return 0, newValidationError("примечание не должно превышать 1000 символов")
+	}
+
+	recordID, err := s.db.AddMaintenanceRecord(ctx, record)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось добавить запись об обслуживании: %w", err)
+	}
+	return recordID, nil
+}
+
+func (s *AutoParkService) DeleteMaintenanceRecord(ctx context.Context, recordID int) error {
+	return s.db.DeleteMaintenanceRecord(ctx, recordID)
+}
+
+func (s *AutoParkService) GetMaintenanceSchedules(ctx context.Context, autoID int) ([]models.MaintenanceSchedule, error) {
+	return s.db.GetMaintenanceSchedules(ctx, autoID)
+}
+
+func (s *AutoParkService) AddMaintenanceSchedule(ctx context.Context, schedule models.MaintenanceSchedule) (int, error) {
+	schedule.Type = strings.TrimSpace(schedule.Type)
+	if schedule.AutoID <= 0 {
+		return 0, newValidationError("не выбран автомобиль")
+	}
+	if err := validateMaintenanceType(schedule.Type); err != nil {
+		return 0, err
+	}
+	if schedule.IntervalDays == nil && schedule.IntervalKm == nil {
+		return 0, newValidationError("укажите периодичность обслуживания в днях или километрах")
+	}
+	if schedule.IntervalDays != nil && *schedule.IntervalDays <= 0 {
+		return 0, newValidationError("периодичность в днях должна быть положительной")
+	}
+	if schedule.IntervalKm != nil && *schedule.IntervalKm <= 0 {
+		return 0, newValidationError("периодичность в километрах должна быть положительной")
+	}
+
+	// Первое обслуживание по пробегу отсчитывается от текущих показаний одометра
+	records, err := s.db.GetMaintenanceRecords(ctx, schedule.AutoID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load maintenance records: %w", err)
+	}
+	readings, err := s.db.GetOdometerReadings(ctx, schedule.AutoID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load odometer readings: %w", err)
+	}
+	schedule.StartOdometer = currentOdometer(schedule.AutoID, records, readings)
+
+	scheduleID, err := s.db.AddMaintenanceSchedule(ctx, schedule)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось добавить регламент обслуживания: %w", err)
+	}
+	return scheduleID, nil
+}
+
+func (s *AutoParkService) DeleteMaintenanceSchedule(ctx context.Context, scheduleID int) error {
+	return s.db.DeleteMaintenanceSchedule(ctx, scheduleID)
+}
+
+// Сроки обслуживания по всем регламентам действующих автомобилей на момент at:
+// сначала просроченные, затем предстоящие, внутри — по ближайшему сроку
+func (s *AutoParkService) GetServiceStatuses(ctx context.Context, at time.Time) ([]models.ServiceStatus, error) {
+	cars, err := s.db.GetCars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cars: %w", err)
+	}
+	active := make(map[int]bool, len(cars))
+	for _, car := range cars {
+		active[car.ID] = true
+	}
+
+	statuses, err := s.serviceStatuses(ctx, 0, at)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.ServiceStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if active[status.Schedule.AutoID] {
+			result = append(result, status)
+		}
+	}
+
+	rank := map[string]int{models.ServiceStatusOverdue: 0, models.ServiceStatusUpcoming: 1, models.ServiceStatusOK: 2}
+	sort.SliceStable(result, func(i, j int) bool {
+		if rank[result[i].Status] != rank[result[j].Status] {
+			return rank[result[i].Status] < rank[result[j].Status]
+		}
+		return dueBefore(result[i], result[j])
+	})
+	return result, nil
+}
+
+// Отправка в рейс запрещена, пока просрочено обязательное обслуживание автомобиля
+func (s *AutoParkService) checkServiceBeforeDispatch(ctx context.Context, autoID int, at time.Time) error {
+	statuses, err := s.serviceStatuses(ctx, autoID, at)
+	if err != nil {
+		return err
+	}
+
+	var overdue []string
+	for _, status := range statuses {
+		if status.Schedule.Mandatory && status.Status == models.ServiceStatusOverdue {
+			overdue = append(overdue, status.Schedule.Type)
+		}
+	}
+	if len(overdue) > 0 {
+		return fmt.Errorf("%w: автомобиль не может быть отправлен в рейс, просрочено обязательное обслуживание: %s",
+			database.ErrConflict, strings.Join(overdue, ", "))
+	}
+	return nil
+}
+
+func (s *AutoParkService) serviceStatuses(ctx context.Context, autoID int, at time.Time) ([]models.ServiceStatus, error) {
+	schedules, err := s.db.GetMaintenanceSchedules(ctx, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load maintenance schedules: %w", err)
+	}
+	if len(schedules) == 0 {
+		return nil, nil
+	}
+	records, err := s.db.GetMaintenanceRecords(ctx, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load maintenance records: %w", err)
+	}
+	readings, err := s.db.GetOdometerReadings(ctx, autoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load odometer readings: %w", err)
+	}
+
+	statuses := make([]models.ServiceStatus, 0, len(schedules))
+	for _, schedule := range schedules {
+		statuses = append(statuses, serviceStatus(schedule, records, readings, at))
+	}
+	return statuses, nil
+}
+
+// Текущий пробег автомобиля — наибольшие показания одометра в журнале рейсов
+// и в записях об обслуживании; nil, если показаний нет
+func currentOdometer(autoID int, records []models.MaintenanceRecord, readings map[int]int) *int {
+	var current *int
+	if odometer, ok := readings[autoID]; ok {
+		current = &odometer
+	}
+	for _, record := range records {
+		if record.AutoID == autoID && record.Odometer != nil && (current == nil || *record.Odometer > *current) {
+			odometer := *record.Odometer
+			current = &odometer
+		}
+	}
+	return current
+}
+
+// Срок обслуживания по регламенту. Отсчет ведется от последней записи того же вида
+// работ, а если работ еще не было — от даты создания регламента и пробега на тот момент.
+// Если пробег при создании регламента неизвестен, срок по пробегу не определяется.
+func serviceStatus(schedule models.MaintenanceSchedule, records []models.MaintenanceRecord,
+	readings map[int]int, at time.Time) models.ServiceStatus {
+	status := models.ServiceStatus{Schedule: schedule, Status: models.ServiceStatusOK}
+	status.CurrentOdometer = currentOdometer(schedule.AutoID, records, readings)
+
+	var last *models.MaintenanceRecord
+	for i := range records {
+		record := &records[i]
+		if record.AutoID != schedule.AutoID {
+			continue
+		}
+		if strings.EqualFold(record.Type, schedule.Type) && (last == nil || record.PerformedAt.After(last.PerformedAt)) {
+			last = record
+		}
+	}
+
+	baseDate := schedule.CreatedAt
+	baseOdometer := 0
+	knownOdometer := schedule.StartOdometer != nil
+	if knownOdometer {
+		baseOdometer = *schedule.StartOdometer
+	}
+	if last != nil {
+		performedAt := last.PerformedAt
+		status.LastPerformedAt = &performedAt
+		status.LastOdometer = last.Odometer
+		baseDate = last.PerformedAt
+		knownOdometer = last.Odometer != nil
+		if knownOdometer {
+			baseOdometer = *last.Odometer
+		}
+	}
+
+	if schedule.IntervalDays != nil {
+		dueDate := baseDate.AddDate(0, 0, *schedule.IntervalDays)
+		status.DueDate = &dueDate
+		switch {
+		case at.After(dueDate):
+			status.Status = models.ServiceStatusOverdue
+		case at.AddDate(0, 0, UpcomingServiceDays).After(dueDate):
+			status.Status = models.ServiceStatusUpcoming
+		}
+	}
+
+	if schedule.IntervalKm != nil && knownOdometer {
+		dueOdometer := baseOdometer + *schedule.IntervalKm
+		status.DueOdometer = &dueOdometer
+		if status.CurrentOdometer != nil && status.Status != models.ServiceStatusOverdue {
+			switch {
+			case *status.CurrentOdometer >= dueOdometer:
+				status.Status = models.ServiceStatusOverdue
+			case *status.CurrentOdometer+UpcomingServiceKm >= dueOdometer:
+				status.Status = models.ServiceStatusUpcoming
+			}
+		}
+	}
+
+	return status
+}
+
+func dueBefore(a, b models.ServiceStatus) bool {
+	switch {
+	case a.DueDate != nil && b.DueDate != nil:
+		return a.DueDate.Before(*b.DueDate)
+	case a.DueDate != nil:
+		return true
+	case b.DueDate != nil:
+		return false
+	default:
+		return a.Schedule.AutoNum < b.Schedule.AutoNum
+	}
+}
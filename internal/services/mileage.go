@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Отчет о пробеге и расходе топлива по завершенным рейсам, попавшим в фильтр
+// журнала, в разрезе автомобилей, водителей и маршрутов
+func (s *AutoParkService) GetMileageReport(ctx context.Context, filter models.JournalFilter) (*models.MileageReport, error) {
+	filter.Status = models.JournalStatusCompleted
+	entries, err := s.GetAllJournalEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byAuto := map[int]*models.MileageRow{}
+	byDriver := map[int]*models.MileageRow{}
+	byRoute := map[int]*models.MileageRow{}
+	report := &models.MileageReport{Total: models.MileageRow{Name: "Итого"}}
+
+	for _, entry := range entries {
+		rows := []*models.MileageRow{
+			mileageRow(byAuto, entry.AutoID, entry.AutoNumber+" ("+entry.AutoMark+")"),
+			mileageRow(byDriver, entry.DriverID, entry.DriverName),
+			mileageRow(byRoute, entry.RouteID, entry.StartPoint+" - "+entry.EndPoint),
+			&report.Total,
+		}
+		distance, fuel := entry.Distance(), entry.FuelUsed()
+		for _, row := range rows {
+			row.Trips++
+			if distance != nil {
+				row.Distance += *distance
+				if fuel != nil {
+					row.FuelUsed += *fuel
+					row.FuelDistance += *distance
+				}
+			}
+		}
+	}
+
+	report.ByAuto = mileageRows(byAuto)
+	report.ByDriver = mileageRows(byDriver)
+	report.ByRoute = mileageRows(byRoute)
+	setConsumption(&report.Total)
+	return report, nil
+}
+
+func mileageRow(rows map[int]*models.MileageRow, id int, name string) *models.MileageRow {
+	row, ok := rows[id]
+	if !ok {
+		row = &models.MileageRow{ID: id, Name: name}
+		rows[id] = row
+	}
+	return row
+}
+
+// Строки отчета по убыванию пробега
+func mileageRows(rows map[int]*models.MileageRow) []models.MileageRow {
+	result := make([]models.MileageRow, 0, len(rows))
+	for _, row := range rows {
+		setConsumption(row)
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Distance != result[j].Distance {
+			return result[i].Distance > result[j].Distance
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func setConsumption(row *models.MileageRow) {
+	if row.FuelDistance > 0 {
+		consumption := row.FuelUsed / float64(row.FuelDistance) * 100
+		row.ConsumptionPer100 = &consumption
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Допуск, в пределах которого превышение плановой длительности не считается опозданием
+const LateToleranceMinutes = 5
+
+// Рейс опоздал, если длился дольше плановой длительности маршрута с учетом допуска;
+// delay — превышение плана в минутах
+func tripDelay(entry models.JournalView) (delay float64, late, known bool) {
+	duration := entry.DurationMinutes()
+	if duration == nil || entry.PlannedMinutes == nil {
+		return 0, false, false
+	}
+	delay = *duration - float64(*entry.PlannedMinutes)
+	return delay, delay > LateToleranceMinutes, true
+}
+
+// Аналитика длительности завершенных рейсов по маршрутам: среднее, медиана,
+// 90-й перцентиль, опоздания относительно плана и тренд по интервалам
+func (s *AutoParkService) GetRouteDurationReport(ctx context.Context, filter models.JournalFilter, interval string) (*models.RouteDurationReport, error) {
+	switch interval {
+	case "":
+		interval = models.TrendWeek
+	case models.TrendDay, models.TrendWeek, models.TrendMonth:
+	default:
+		return nil, newValidationError("unknown trend interval %q", interval)
+	}
+	filter.Status = models.JournalStatusCompleted
+	entries, err := s.GetAllJournalEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type routeStats struct {
+		row       models.RouteTime
+		durations []float64
+		delays    float64
+		planned   int
+	}
+	type trendKey struct {
+		period  time.Time
+		routeID int
+	}
+	routes := map[int]*routeStats{}
+	trend := map[trendKey]*models.RouteTrendPoint{}
+
+	for _, entry := range entries {
+		duration := entry.DurationMinutes()
+		if duration == nil || *duration < 0 {
+			continue
+		}
+		name := entry.StartPoint + " - " + entry.EndPoint
+		stats, ok := routes[entry.RouteID]
+		if !ok {
+			stats = &routeStats{row: models.RouteTime{RouteID: entry.RouteID, RouteName: name, PlannedMinutes: entry.PlannedMinutes}}
+			routes[entry.RouteID] = stats
+		}
+		stats.row.Trips++
+		stats.durations = append(stats.durations, *duration)
+
+		delay, late, known := tripDelay(entry)
+		if known {
+			stats.planned++
+		}
+		if late {
+			stats.row.LateTrips++
+			stats.delays += delay
+		}
+
+		key := trendKey{period: trendPeriod(entry.TimeOut, interval), routeID: entry.RouteID}
+		point, ok := trend[key]
+		if !ok {
+			point = &models.RouteTrendPoint{PeriodStart: key.period, RouteID: entry.RouteID, RouteName: name}
+			trend[key] = point
+		}
+		// Среднее накапливается суммой и делится после обхода
+		point.Trips++
+		point.AverageTime += *duration
+		if late {
+			point.LateTrips++
+		}
+	}
+
+	report := &models.RouteDurationReport{Interval: interval, Routes: []models.RouteTime{}, Trend: []models.RouteTrendPoint{}}
+	for _, stats := range routes {
+		row := stats.row
+		sort.Float64s(stats.durations)
+		sum := 0.0
+		for _, duration := range stats.durations {
+			sum += duration
+		}
+		row.AverageTime = round1(sum / float64(len(stats.durations)))
+		row.MedianTime = round1(percentile(stats.durations, 0.5))
+		row.P90Time = round1(percentile(stats.durations, 0.9))
+		if stats.planned > 0 {
+			percent := round1(float64(row.LateTrips) / float64(stats.planned) * 100)
+			row.LatePercent = &percent
+		}
+		if row.LateTrips > 0 {
+			delay := round1(stats.delays / float64(row.LateTrips))
+			row.AverageDelay = &delay
+		}
+		report.Routes = append(report.Routes, row)
+	}
+	sort.Slice(report.Routes, func(i, j int) bool {
+		if report.Routes[i].Trips != report.Routes[j].Trips {
+			return report.Routes[i].Trips > report.Routes[j].Trips
+		}
+		return report.Routes[i].RouteName < report.Routes[j].RouteName
+	})
+
+	for _, point := range trend {
+		point.AverageTime = round1(point.AverageTime / float64(point.Trips))
+		report.Trend = append(report.Trend, *point)
+	}
+	sort.Slice(report.Trend, func(i, j int) bool {
+		if !report.Trend[i].PeriodStart.Equal(report.Trend[j].PeriodStart) {
+			return report.Trend[i].PeriodStart.Before(report.Trend[j].PeriodStart)
+		}
+		return report.Trend[i].RouteName < report.Trend[j].RouteName
+	})
+	return report, nil
+}
+
+// Начало интервала тренда: день, неделя с понедельника или месяц
+func trendPeriod(at time.Time, interval string) time.Time {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case models.TrendDay:
+		return day
+	case models.TrendMonth:
+		return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+}
+
+// Перцентиль отсортированной выборки с линейной интерполяцией, как percentile_cont в PostgreSQL
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+func round1(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	for _, tc := range []struct {
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{nil, 0.5, 0},
+		{[]float64{42}, 0.9, 42},
+		{sorted, 0, 10},
+		{sorted, 1, 40},
+		{sorted, 0.5, 25},
+		{sorted, 0.9, 37},
+		{[]float64{10, 20, 30}, 0.5, 20},
+	} {
+		if got := percentile(tc.values, tc.p); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("percentile(%v, %v) = %v, want %v", tc.values, tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestTrendPeriod(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	for _, tc := range []struct {
+		at       time.Time
+		interval string
+		want     string
+	}{
+		{time.Date(2024, 3, 13, 18, 45, 0, 0, time.UTC), models.TrendDay, "2024-03-13"},
+		// Среда, воскресенье и понедельник — неделя начинается с понедельника
+		{time.Date(2024, 3, 13, 18, 45, 0, 0, time.UTC), models.TrendWeek, "2024-03-11"},
+		{time.Date(2024, 3, 17, 23, 0, 0, 0, time.UTC), models.TrendWeek, "2024-03-11"},
+		{time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), models.TrendWeek, "2024-03-11"},
+		// Неделя через границу года
+		{time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), models.TrendWeek, "2024-12-30"},
+		{time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC), models.TrendMonth, "2024-02-01"},
+		// Календарная дата берется в часовом поясе времени отправления
+		{time.Date(2024, 3, 1, 1, 30, 0, 0, moscow), models.TrendDay, "2024-03-01"},
+	} {
+		got := trendPeriod(tc.at, tc.interval)
+		if got.Format("2006-01-02") != tc.want || got.Location() != time.UTC || got.Hour() != 0 {
+			t.Errorf("trendPeriod(%v, %s) = %v, want %s 00:00 UTC", tc.at, tc.interval, got, tc.want)
+		}
+	}
+}
+
+func TestTripDelay(t *testing.T) {
+	timeOut := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	trip := func(minutes int, planned *int) models.JournalView {
+		timeIn := timeOut.Add(time.Duration(minutes) * time.Minute)
+		return models.JournalView{TimeOut: timeOut, TimeIn: &timeIn, PlannedMinutes: planned}
+	}
+	planned := 60
+
+	for _, tc := range []struct {
+		name  string
+		entry models.JournalView
+		delay float64
+		late  bool
+		known bool
+	}{
+		{"ahead of plan", trip(50, &planned), -10, false, true},
+		{"within tolerance", trip(60+LateToleranceMinutes, &planned), LateToleranceMinutes, false, true},
+		{"late", trip(61+LateToleranceMinutes, &planned), LateToleranceMinutes + 1, true, true},
+		{"no planned duration", trip(90, nil), 0, false, false},
+		{"trip in progress", models.JournalView{TimeOut: timeOut, PlannedMinutes: &planned}, 0, false, false},
+	} {
+		delay, late, known := tripDelay(tc.entry)
+		if delay != tc.delay || late != tc.late || known != tc.known {
+			t.Errorf("%s: tripDelay = %v, %v, %v, want %v, %v, %v", tc.name, delay, late, known, tc.delay, tc.late, tc.known)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+// Горизонт формирования планов по расписаниям по умолчанию, дней
+const DefaultTimetableHorizonDays = 7
+
+// Ключ advisory-блокировки, под которой планы по расписаниям формирует
+// только один экземпляр приложения
+const timetableLockKey = 7_140_512_002
+
+func (s *AutoParkService) GetTimetables(ctx context.Context, routeID int) ([]models.Timetable, error) {
+	timetables, err := s.db.GetTimetables(ctx, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timetables: %w", err)
+	}
+	return timetables, nil
+}
+
+func (s *AutoParkService) GetTimetableByID(ctx context.Context, timetableID int) (*models.Timetable, error) {
+	return s.db.GetTimetableByID(ctx, timetableID)
+}
+
+// Новое расписание; планы по нему появятся при следующем запуске формирования
+func (s *AutoParkService) AddTimetable(ctx context.Context, timetable models.Timetable) (int, error) {
+	if timetable.RouteID <= 0 || timetable.AutoID <= 0 || timetable.DriverID <= 0 {
+		return 0, newValidationError("routeID, autoID and driverID must be positive")
+	}
+	departure, err := time.Parse("15:04", strings.TrimSpace(timetable.DepartureTime))
+	if err != nil {
+		return 0, newValidationError("некорректное время отправления %q, ожидается ЧЧ:ММ", timetable.DepartureTime)
+	}
+	timetable.DepartureTime = departure.Format("15:04")
+	if d := timetable.DurationMinutes; d != nil && (*d < 1 || *d > int(MaxTripPlanDuration.Minutes())) {
+		return 0, newValidationError("длительность рейса должна быть от 1 до %d минут", int(MaxTripPlanDuration.Minutes()))
+	}
+
+	weekdays := map[int]bool{}
+	for _, day := range timetable.Weekdays {
+		if day < 1 || day > 7 {
+			return 0, newValidationError("некорректный день недели %d, ожидается от 1 (пн) до 7 (вс)", day)
+		}
+		weekdays[day] = true
+	}
+	if len(weekdays) == 0 {
+		return 0, newValidationError("укажите хотя бы один день недели")
+	}
+	timetable.Weekdays = timetable.Weekdays[:0]
+	for day := range weekdays {
+		timetable.Weekdays = append(timetable.Weekdays, day)
+	}
+	sort.Ints(timetable.Weekdays)
+
+	if timetable.ValidFrom.IsZero() {
+		timetable.ValidFrom = today()
+	}
+	if timetable.ValidTo != nil && timetable.ValidTo.Before(timetable.ValidFrom) {
+		return 0, newValidationError("дата окончания расписания не может быть раньше даты начала")
+	}
+
+	timetableID, err := s.db.AddTimetable(ctx, timetable)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось добавить расписание: %w", err)
+	}
+	return timetableID, nil
+}
+
+// Удаление расписания вместе с его еще не отправленными будущими планами
+func (s *AutoParkService) DeleteTimetable(ctx context.Context, timetableID int) error {
+	return s.db.DeleteTimetable(ctx, timetableID, wallClockNow())
+}
+
+func (s *AutoParkService) GetTimetableExceptions(ctx context.Context) ([]models.TimetableException, error) {
+	exceptions, err := s.db.GetTimetableExceptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timetable exceptions: %w", err)
+	}
+	return exceptions, nil
+}
+
+// Изменения исключений выполняются под блокировкой формирования планов,
+// чтобы генератор не работал по устаревшему списку исключений
+func (s *AutoParkService) withTimetableLock(ctx context.Context, fn func() error) error {
+	locked, err := s.db.TryAdvisoryLock(ctx, timetableLockKey, fn)
+	if err == nil && !locked {
+		return fmt.Errorf("%w: идет формирование планов по расписаниям, повторите позже", database.ErrConflict)
+	}
+	return err
+}
+
+// Исключение из расписания; уже сформированные на этот день и еще не
+// отправленные планы по затронутым расписаниям отменяются
+func (s *AutoParkService) AddTimetableException(ctx context.Context, exception models.TimetableException) (int, error) {
+	if exception.Date.IsZero() {
+		return 0, newValidationError("укажите дату исключения")
+	}
+	if exception.TimetableID != nil && *exception.TimetableID <= 0 {
+		exception.TimetableID = nil
+	}
+	exception.Reason = strings.TrimSpace(exception.Reason)
+	if utf8.RuneCountInString(exception.Reason) > 200 {
+		return 0, newValidationError("причина не должна превышать 200 символов")
+	}
+
+	var exceptionID int
+	err := s.withTimetableLock(ctx, func() error {
+		var err error
+		exceptionID, err = s.db.AddTimetableException(ctx, exception)
+		if err != nil {
+			return fmt.Errorf("не удалось добавить исключение: %w", err)
+		}
+
+		from, to := exception.Date, exception.Date.AddDate(0, 0, 1)
+		filter := models.TripPlanFilter{From: &from, To: &to}
+		if exception.TimetableID != nil {
+			filter.TimetableID = *exception.TimetableID
+		}
+		return s.cancelTimetablePlans(ctx, filter, &exception)
+	})
+	return exceptionID, err
+}
+
+// Удаление исключения; если планы на этот день уже формировались,
+// рейсы по расписанию восстанавливаются сразу
+func (s *AutoParkService) DeleteTimetableException(ctx context.Context, exceptionID int) error {
+	return s.withTimetableLock(ctx, func() error {
+		return s.deleteTimetableException(ctx, exceptionID)
+	})
+}
+
+func (s *AutoParkService) deleteTimetableException(ctx context.Context, exceptionID int) error {
+	exceptions, err := s.db.GetTimetableExceptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get timetable exceptions: %w", err)
+	}
+	var removed *models.TimetableException
+	rest := exceptions[:0]
+	for i := range exceptions {
+		if exceptions[i].ID == exceptionID {
+			exception := exceptions[i]
+			removed = &exception
+			continue
+		}
+		rest = append(rest, exceptions[i])
+	}
+	if removed == nil {
+		return fmt.Errorf("timetable exception %w", database.ErrNotFound)
+	}
+	if err := s.db.DeleteTimetableException(ctx, exceptionID); err != nil {
+		return err
+	}
+
+	timetables, err := s.db.GetTimetables(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get timetables: %w", err)
+	}
+	run, err := s.newTimetableRun(ctx, rest)
+	if err != nil {
+		return err
+	}
+	for _, timetable := range timetables {
+		if !removed.AppliesTo(timetable.ID) || timetable.GeneratedUntil == nil || timetable.GeneratedUntil.Before(removed.Date) {
+			continue
+		}
+		if _, err := s.generateTimetablePlan(ctx, run, timetable, removed.Date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AutoParkService) SetTimetableHorizon(days int) {
+	s.timetableHorizon = days
+}
+
+// Формирование планов по всем расписаниям до дня today+horizon включительно.
+// Каждый день обрабатывается один раз: отмененные вручную планы не появляются снова.
+// Расписания с архивными маршрутом, автомобилем или водителем пропускаются.
+// Планы формирует один экземпляр приложения: если advisory-блокировку
+// держит другой экземпляр, формирование пропускается.
+func (s *AutoParkService) GenerateTimetablePlans(ctx context.Context) (int, error) {
+	created := 0
+	locked, err := s.db.TryAdvisoryLock(ctx, timetableLockKey, func() error {
+		var err error
+		created, err = s.generateTimetablePlans(ctx)
+		return err
+	})
+	if err == nil && !locked {
+		s.logger.DebugContext(ctx, "Timetable generator: lock is held by another instance")
+	}
+	return created, err
+}
+
+func (s *AutoParkService) generateTimetablePlans(ctx context.Context) (int, error) {
+	timetables, err := s.db.GetTimetables(ctx, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get timetables: %w", err)
+	}
+	exceptions, err := s.db.GetTimetableExceptions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get timetable exceptions: %w", err)
+	}
+	run, err := s.newTimetableRun(ctx, exceptions)
+	if err != nil {
+		return 0, err
+	}
+	active, err := s.activeReferences(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	first := today()
+	until := first.AddDate(0, 0, s.timetableHorizon)
+	created := 0
+	for _, timetable := range timetables {
+		if !active.routes[timetable.RouteID] || !active.autos[timetable.AutoID] || !active.drivers[timetable.DriverID] {
+			continue
+		}
+		start, end := first, until
+		if timetable.ValidFrom.After(start) {
+			start = timetable.ValidFrom
+		}
+		if timetable.GeneratedUntil != nil && !timetable.GeneratedUntil.Before(start) {
+			start = timetable.GeneratedUntil.AddDate(0, 0, 1)
+		}
+		if timetable.ValidTo != nil && timetable.ValidTo.Before(end) {
+			end = *timetable.ValidTo
+		}
+		if start.After(end) {
+			continue
+		}
+
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			ok, err := s.generateTimetablePlan(ctx, run, timetable, date)
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+		if err := s.db.SetTimetableGeneratedUntil(ctx, timetable.ID, end); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// Фоновое формирование планов: сразу при запуске и затем с периодом interval
+// до отмены контекста
+func (s *AutoParkService) RunTimetableGenerator(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		created, err := s.GenerateTimetablePlans(ctx)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Timetable generator failed", "error", err)
+		} else if created > 0 {
+			s.logger.InfoContext(ctx, "Timetable generator: trip plans created", "count", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Исключения и закрепления, общие для всех планов одного запуска формирования
+type timetableRun struct {
+	exceptions  []models.TimetableException
+	assignments []models.Assignment
+}
+
+func (s *AutoParkService) newTimetableRun(ctx context.Context, exceptions []models.TimetableException) (*timetableRun, error) {
+	assignments, err := s.db.GetAssignments(ctx, models.AssignmentFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	return &timetableRun{exceptions: exceptions, assignments: assignments}, nil
+}
+
+// План рейса по расписанию на день date; false — рейса в этот день нет,
+// время отправления уже прошло, план уже сформирован или не прошел проверки
+// планирования — тогда причина пишется в лог, а день считается обработанным
+func (s *AutoParkService) generateTimetablePlan(ctx context.Context, run *timetableRun, timetable models.Timetable, date time.Time) (bool, error) {
+	if !timetable.RunsOn(date) {
+		return false, nil
+	}
+	for _, exception := range run.exceptions {
+		if exception.Date.Equal(date) && exception.AppliesTo(timetable.ID) {
+			return false, nil
+		}
+	}
+
+	departure, err := time.Parse("15:04", timetable.DepartureTime)
+	if err != nil {
+		return false, fmt.Errorf("timetable %d: invalid departure time %q", timetable.ID, timetable.DepartureTime)
+	}
+	plannedOut := date.Add(time.Duration(departure.Hour())*time.Hour + time.Duration(departure.Minute())*time.Minute)
+	if plannedOut.Before(wallClockNow()) {
+		return false, nil
+	}
+
+	timetableID := timetable.ID
+	plan := models.TripPlan{AutoID: timetable.AutoID, DriverID: timetable.DriverID, RouteID: timetable.RouteID,
+		PlannedOut: plannedOut, TimetableID: &timetableID}
+	if timetable.DurationMinutes != nil {
+		plannedIn := plannedOut.Add(time.Duration(*timetable.DurationMinutes) * time.Minute)
+		plan.PlannedIn = &plannedIn
+	}
+	_, err = s.addTripPlan(ctx, plan, run.assignments)
+	var validationErr *ValidationError
+	switch {
+	case errors.Is(err, database.ErrTimetablePlanExists):
+		return false, nil
+	case errors.Is(err, database.ErrConflict) || errors.As(err, &validationErr):
+		s.logger.WarnContext(ctx, "Timetable plan skipped", "timetable_id", timetable.ID,
+			"planned_out", plannedOut.Format("2006-01-02 15:04"), "reason", err)
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("timetable %d: %w", timetable.ID, err)
+	}
+	return true, nil
+}
+
+// Отмена неотправленных планов по расписаниям; exception ограничивает
+// отмену расписаниями, к которым относится исключение
+func (s *AutoParkService) cancelTimetablePlans(ctx context.Context, filter models.TripPlanFilter, exception *models.TimetableException) error {
+	plans, err := s.db.GetTripPlans(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to get trip plans: %w", err)
+	}
+	for _, plan := range plans {
+		if plan.Dispatched() || plan.TimetableID == nil {
+			continue
+		}
+		if exception != nil && !exception.AppliesTo(*plan.TimetableID) {
+			continue
+		}
+		if err := s.db.DeleteTripPlan(ctx, plan.ID); err != nil && !errors.Is(err, database.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Действующие (неархивные) маршруты, автомобили и водители
+type activeReferences struct {
+	routes, autos, drivers map[int]bool
+}
+
+func (s *AutoParkService) activeReferences(ctx context.Context) (*activeReferences, error) {
+	active := &activeReferences{routes: map[int]bool{}, autos: map[int]bool{}, drivers: map[int]bool{}}
+	routes, err := s.db.GetRoutes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get routes: %w", err)
+	}
+	for _, route := range routes {
+		active.routes[route.ID] = true
+	}
+	cars, err := s.db.GetCars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cars: %w", err)
+	}
+	for _, car := range cars {
+		active.autos[car.ID] = true
+	}
+	drivers, err := s.db.GetDrivers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drivers: %w", err)
+	}
+	for _, driver := range drivers {
+		active.drivers[driver.ID] = true
+	}
+	return active, nil
+}
+
+// Текущее время в представлении журнала: местное время без часового пояса
+func wallClockNow() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Ежедневное расписание с завтрашнего дня: по горизонту по умолчанию — 7 планов
+func (f fixture) addTimetable(t *testing.T, autoID, driverID int, departure string) int {
+	t.Helper()
+	timetableID, err := f.service.AddTimetable(context.Background(), models.Timetable{
+		RouteID: f.routeID, AutoID: autoID, DriverID: driverID, DepartureTime: departure,
+		Weekdays: []int{1, 2, 3, 4, 5, 6, 7}, ValidFrom: today().AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("AddTimetable: %v", err)
+	}
+	return timetableID
+}
+
+func (f fixture) timetablePlans(t *testing.T, timetableID int) []models.TripPlan {
+	t.Helper()
+	plans, err := f.service.GetTripPlans(context.Background(), models.TripPlanFilter{TimetableID: timetableID})
+	if err != nil {
+		t.Fatalf("GetTripPlans: %v", err)
+	}
+	return plans
+}
+
+func TestGenerateTimetablePlans(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	timetableID := f.addTimetable(t, f.autoID, f.driverID, "10:00")
+
+	// План на первый день уже есть: повтор пропускается без ошибки
+	first := today().AddDate(0, 0, 1).Add(10 * time.Hour)
+	if _, err := f.db.AddTripPlan(ctx, models.TripPlan{
+		AutoID: f.autoID, DriverID: f.driverID, RouteID: f.routeID, PlannedOut: first, TimetableID: &timetableID,
+	}); err != nil {
+		t.Fatalf("AddTripPlan: %v", err)
+	}
+
+	created, err := f.service.GenerateTimetablePlans(ctx)
+	if err != nil {
+		t.Fatalf("GenerateTimetablePlans: %v", err)
+	}
+	if created != DefaultTimetableHorizonDays-1 {
+		t.Errorf("created = %d, want %d", created, DefaultTimetableHorizonDays-1)
+	}
+	if plans := f.timetablePlans(t, timetableID); len(plans) != DefaultTimetableHorizonDays {
+		t.Errorf("timetable plans = %d, want %d", len(plans), DefaultTimetableHorizonDays)
+	}
+
+	created, err = f.service.GenerateTimetablePlans(ctx)
+	if err != nil || created != 0 {
+		t.Errorf("second run: created %d, err %v; want nothing new", created, err)
+	}
+}
+
+// Планы, не прошедшие проверки планирования, не создаются, остальные расписания формируются
+func TestGenerateTimetablePlansSkipsConflicts(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	_, otherDriverID := f.addCar(t, "В456ОР77")
+	unassigned := f.addTimetable(t, f.autoID, otherDriverID, "08:00")
+	assigned := f.addTimetable(t, f.autoID, f.driverID, "10:00")
+
+	created, err := f.service.GenerateTimetablePlans(ctx)
+	if err != nil {
+		t.Fatalf("GenerateTimetablePlans: %v", err)
+	}
+	if created != DefaultTimetableHorizonDays {
+		t.Errorf("created = %d, want %d", created, DefaultTimetableHorizonDays)
+	}
+	if plans := f.timetablePlans(t, unassigned); len(plans) != 0 {
+		t.Errorf("plans for unassigned driver = %d, want 0", len(plans))
+	}
+	if plans := f.timetablePlans(t, assigned); len(plans) != DefaultTimetableHorizonDays {
+		t.Errorf("plans for assigned driver = %d, want %d", len(plans), DefaultTimetableHorizonDays)
+	}
+}
+
+func TestGenerateTimetablePlansUnderLock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	timetableID := f.addTimetable(t, f.autoID, f.driverID, "10:00")
+
+	locked, err := f.db.TryAdvisoryLock(ctx, timetableLockKey, func() error {
+		created, err := f.service.GenerateTimetablePlans(ctx)
+		if err != nil || created != 0 {
+			t.Errorf("generation while another instance holds the lock: created %d, err %v", created, err)
+		}
+		return nil
+	})
+	if err != nil || !locked {
+		t.Fatalf("TryAdvisoryLock: locked %v, err %v", locked, err)
+	}
+	if plans := f.timetablePlans(t, timetableID); len(plans) != 0 {
+		t.Errorf("plans generated under foreign lock = %d, want 0", len(plans))
+	}
+
+	if created, err := f.service.GenerateTimetablePlans(ctx); err != nil || created != DefaultTimetableHorizonDays {
+		t.Errorf("after lock release: created %d, err %v", created, err)
+	}
+}
+
+// Исключения меняются только под блокировкой генератора; после удаления
+// исключения план на этот день восстанавливается
+func TestDeleteTimetableExceptionUnderLock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	timetableID := f.addTimetable(t, f.autoID, f.driverID, "10:00")
+	date := today().AddDate(0, 0, 2)
+	exceptionID, err := f.service.AddTimetableException(ctx, models.TimetableException{TimetableID: &timetableID, Date: date})
+	if err != nil {
+		t.Fatalf("AddTimetableException: %v", err)
+	}
+	if _, err := f.service.GenerateTimetablePlans(ctx); err != nil {
+		t.Fatalf("GenerateTimetablePlans: %v", err)
+	}
+	if plans := f.timetablePlans(t, timetableID); len(plans) != DefaultTimetableHorizonDays-1 {
+		t.Fatalf("timetable plans = %d, want %d", len(plans), DefaultTimetableHorizonDays-1)
+	}
+
+	locked, err := f.db.TryAdvisoryLock(ctx, timetableLockKey, func() error {
+		assertConflict(t, f.service.DeleteTimetableException(ctx, exceptionID), "delete exception while generator runs")
+		return nil
+	})
+	if err != nil || !locked {
+		t.Fatalf("TryAdvisoryLock: locked %v, err %v", locked, err)
+	}
+
+	if err := f.service.DeleteTimetableException(ctx, exceptionID); err != nil {
+		t.Fatalf("DeleteTimetableException: %v", err)
+	}
+	if plans := f.timetablePlans(t, timetableID); len(plans) != DefaultTimetableHorizonDays {
+		t.Errorf("timetable plans after exception removal = %d, want %d", len(plans), DefaultTimetableHorizonDays)
+	}
+}
+
+func TestDeleteTimetableCancelsPlans(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	timetableID := f.addTimetable(t, f.autoID, f.driverID, "10:00")
+	if _, err := f.service.GenerateTimetablePlans(ctx); err != nil {
+		t.Fatalf("GenerateTimetablePlans: %v", err)
+	}
+
+	if err := f.service.DeleteTimetable(ctx, timetableID); err != nil {
+		t.Fatalf("DeleteTimetable: %v", err)
+	}
+	plans, err := f.service.GetTripPlans(ctx, models.TripPlanFilter{})
+	if err != nil {
+		t.Fatalf("GetTripPlans: %v", err)
+	}
+	if len(plans) != 0 {
+		t.Errorf("plans after timetable removal = %d, want 0", len(plans))
+	}
+	if _, err := f.service.GetTimetableByID(ctx, timetableID); err == nil {
+		t.Errorf("timetable must be deleted")
+	}
+}
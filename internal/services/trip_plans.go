@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/models"
+)
+
+const (
+	// Длительность рейса для проверки пересечений, если время возвращения не запланировано
+	DefaultTripPlanDuration = 2 * time.Hour
+	// Наибольшая длительность запланированного рейса
+	MaxTripPlanDuration = 24 * time.Hour
+)
+
+// Планы рейсов с пересечениями для еще не отправленных в рейс
+func (s *AutoParkService) GetTripPlans(ctx context.Context, filter models.TripPlanFilter) ([]models.TripPlan, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, newValidationError("date range start must be before its end")
+	}
+	plans, err := s.db.GetTripPlans(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip plans: %w", err)
+	}
+	if len(plans) == 0 {
+		return plans, nil
+	}
+
+	from, to := plans[0].PlannedOut, plans[len(plans)-1].PlannedOut.Add(MaxTripPlanDuration)
+	schedule, err := s.loadPlanSchedule(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plans {
+		if !plans[i].Dispatched() {
+			plans[i].Conflicts = schedule.conflicts(plans[i], true)
+		}
+	}
+	return plans, nil
+}
+
+func (s *AutoParkService) GetTripPlanByID(ctx context.Context, planID int) (*models.TripPlan, error) {
+	plan, err := s.db.GetTripPlanByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Dispatched() {
+		schedule, err := s.loadPlanSchedule(ctx, plan.PlannedOut, planEnd(*plan))
+		if err != nil {
+			return nil, err
+		}
+		plan.Conflicts = schedule.conflicts(*plan, true)
+	}
+	return plan, nil
+}
+
+// Новый план не должен пересекаться с другими планами водителя и автомобиля,
+// а водитель должен быть закреплен за автомобилем на дату рейса. Незавершенные
+// рейсы только отмечаются в плане: к отправке водитель может успеть вернуться.
+func (s *AutoParkService) AddTripPlan(ctx context.Context, autoID, driverID, routeID int, plannedOut, plannedIn, notes string) (int, error) {
+	if autoID <= 0 || driverID <= 0 || routeID <= 0 {
+		return 0, newValidationError("autoID, driverID and routeID must be positive")
+	}
+	if plannedOut == "" {
+		return 0, newValidationError("planned departure time is required")
+	}
+
+	plan := models.TripPlan{AutoID: autoID, DriverID: driverID, RouteID: routeID, Notes: strings.TrimSpace(notes)}
+	var err error
+	if plan.PlannedOut, err = parseTime(plannedOut); err != nil {
+		return 0, newValidationError("invalid plannedOut format: %v", err)
+	}
+	if plannedIn != "" {
+		in, err := parseTime(plannedIn)
+		if err != nil {
+			return 0, newValidationError("invalid plannedIn format: %v", err)
+		}
+		plan.PlannedIn = &in
+	}
+	assignments, err := s.db.GetAssignments(ctx, models.AssignmentFilter{AutoID: autoID, DriverID: driverID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	return s.addTripPlan(ctx, plan, assignments)
+}
+
+// Проверки плана, общие для ручного планирования и формирования по расписаниям;
+// закрепление водителя за автомобилем ищется среди assignments
+func (s *AutoParkService) addTripPlan(ctx context.Context, plan models.TripPlan, assignments []models.Assignment) (int, error) {
+	if plan.PlannedIn != nil && !plan.PlannedIn.After(plan.PlannedOut) {
+		return 0, newValidationError("планируемое время возвращения должно быть позже времени отправления")
+	}
+	if plan.PlannedIn != nil && plan.PlannedIn.Sub(plan.PlannedOut) > MaxTripPlanDuration {
+		return 0, newValidationError("рейс не может планироваться дольше чем на %d ч", int(MaxTripPlanDuration.Hours()))
+	}
+	if utf8.RuneCountInString(plan.Notes) > 1000 {
+		return 0, newValidationError("примечание не должно превышать 1000 символов")
+	}
+
+	schedule, err := s.planSchedule(ctx, plan.PlannedOut, planEnd(plan), nil, assignments)
+	if err != nil {
+		return 0, err
+	}
+	if conflicts := schedule.conflicts(plan, false); len(conflicts) > 0 {
+		return 0, fmt.Errorf("%w: %s", database.ErrConflict, strings.Join(conflicts, "; "))
+	}
+
+	planID, err := s.db.AddTripPlan(ctx, plan)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось запланировать рейс: %w", err)
+	}
+	return planID, nil
+}
+
+// Отмена плана; план, уже отправленный в рейс, остается в истории
+func (s *AutoParkService) DeleteTripPlan(ctx context.Context, planID int) error {
+	plan, err := s.db.GetTripPlanByID(ctx, planID)
+	if err != nil {
+		return err
+	}
+	if plan.Dispatched() {
+		return fmt.Errorf("%w: план уже отправлен в рейс (запись журнала %d)", database.ErrConflict, *plan.JournalID)
+	}
+	return s.db.DeleteTripPlan(ctx, planID)
+}
+
+// Отправка запланированного рейса со всеми проверками перед выпуском в рейс.
+// Временем отправления считается текущее — как и время из формы, местное
+// с точностью до минуты. Запись журнала и связь с планом создаются в одной транзакции.
+func (s *AutoParkService) DispatchTripPlan(ctx context.Context, planID int, departure models.TripDeparture) (int, error) {
+	plan, err := s.db.GetTripPlanByID(ctx, planID)
+	if err != nil {
+		return 0, err
+	}
+	if plan.Dispatched() {
+		return 0, fmt.Errorf("%w: план уже отправлен в рейс (запись журнала %d)", database.ErrConflict, *plan.JournalID)
+	}
+	if err := validateReadings(departure.Odometer, departure.Fuel, nil); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	timeOut := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+	return s.startTrip(ctx, plan.AutoID, plan.DriverID, plan.RouteID, timeOut, func() (int, error) {
+		return s.db.DispatchTripPlan(ctx, *plan, timeOut, departure)
+	})
+}
+
+func sameTimetableSlot(a, b models.TripPlan) bool {
+	return a.TimetableID != nil && b.TimetableID != nil && *a.TimetableID == *b.TimetableID && a.PlannedOut.Equal(b.PlannedOut)
+}
+
+// Окончание рейса по плану для проверки пересечений
+func planEnd(plan models.TripPlan) time.Time {
+	if plan.PlannedIn != nil {
+		return *plan.PlannedIn
+	}
+	return plan.PlannedOut.Add(DefaultTripPlanDuration)
+}
+
+// Данные для проверки пересечений планов в интервале
+type planSchedule struct {
+	plans       []models.TripPlan
+	open        []models.JournalView
+	assignments []models.Assignment
+}
+
+func (s *AutoParkService) loadPlanSchedule(ctx context.Context, from, to time.Time) (*planSchedule, error) {
+	open, err := s.GetAllJournalEntries(ctx, models.JournalFilter{Status: models.JournalStatusInProgress})
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.db.GetAssignments(ctx, models.AssignmentFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	return s.planSchedule(ctx, from, to, open, assignments)
+}
+
+// Планы в интервале с уже загруженными незавершенными рейсами и закреплениями
+func (s *AutoParkService) planSchedule(ctx context.Context, from, to time.Time, open []models.JournalView, assignments []models.Assignment) (*planSchedule, error) {
+	from = from.Add(-MaxTripPlanDuration)
+	plans, err := s.db.GetTripPlans(ctx, models.TripPlanFilter{From: &from, To: &to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip plans: %w", err)
+	}
+	return &planSchedule{plans: plans, open: open, assignments: assignments}, nil
+}
+
+// Пересечения плана с другими неотправленными планами того же водителя или
+// автомобиля, отсутствие закрепления и, если withOpen, незавершенные рейсы
+func (sc *planSchedule) conflicts(plan models.TripPlan, withOpen bool) []string {
+	var conflicts []string
+	start, end := plan.PlannedOut, planEnd(plan)
+
+	assigned := false
+	for _, assignment := range sc.assignments {
+		if assignment.AutoID == plan.AutoID && assignment.DriverID == plan.DriverID && assignment.ActiveOn(start) {
+			assigned = true
+			break
+		}
+	}
+	if !assigned {
+		conflicts = append(conflicts, fmt.Sprintf("водитель не закреплен за автомобилем на %s", start.Format("02.01.2006")))
+	}
+
+	for _, other := range sc.plans {
+		if other.ID == plan.ID || other.Dispatched() || !other.PlannedOut.Before(end) || !planEnd(other).After(start) {
+			continue
+		}
+		// Повтор уже сформированного плана отклонит trip_plans_timetable_unique
+		if sameTimetableSlot(other, plan) {
+			continue
+		}
+		at := other.PlannedOut.Format("02.01 15:04")
+		if other.DriverID == plan.DriverID {
+			conflicts = append(conflicts, fmt.Sprintf("водитель %s уже запланирован на %s (план %d)", other.DriverName, at, other.ID))
+		}
+		if other.AutoID == plan.AutoID {
+			conflicts = append(conflicts, fmt.Sprintf("автомобиль %s уже запланирован на %s (план %d)", other.AutoNum, at, other.ID))
+		}
+	}
+
+	if withOpen {
+		for _, entry := range sc.open {
+			if !entry.TimeOut.Before(end) {
+				continue
+			}
+			if entry.DriverID == plan.DriverID {
+				conflicts = append(conflicts, fmt.Sprintf("водитель %s еще не вернулся из рейса %d", entry.DriverName, entry.JournalID))
+			}
+			if entry.AutoID == plan.AutoID {
+				conflicts = append(conflicts, fmt.Sprintf("автомобиль %s еще не вернулся из рейса %d", entry.AutoNumber, entry.JournalID))
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+func TestDispatchTripPlan(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	planID, err := f.service.AddTripPlan(ctx, f.autoID, f.driverID, f.routeID, formTime(time.Hour), "", "")
+	if err != nil {
+		t.Fatalf("AddTripPlan: %v", err)
+	}
+
+	// Автомобиль еще в рейсе: отказ базы не должен оставлять записи журнала или связи с планом
+	entryID := f.dispatch(t, f.autoID, f.driverID, formTime(-time.Hour), nil)
+	_, err = f.service.DispatchTripPlan(ctx, planID, models.TripDeparture{})
+	assertConflict(t, err, "dispatch plan while car is on trip")
+	entries, err := f.service.GetAllJournalEntries(ctx, models.JournalFilter{})
+	if err != nil {
+		t.Fatalf("GetAllJournalEntries: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("journal entries = %d after rejected dispatch, want 1", len(entries))
+	}
+	plan, err := f.service.GetTripPlanByID(ctx, planID)
+	if err != nil {
+		t.Fatalf("GetTripPlanByID: %v", err)
+	}
+	if plan.Dispatched() {
+		t.Errorf("rejected dispatch must not link the plan")
+	}
+
+	f.complete(t, entryID, formTime(0), nil)
+	planEntryID, err := f.service.DispatchTripPlan(ctx, planID, models.TripDeparture{})
+	if err != nil {
+		t.Fatalf("DispatchTripPlan: %v", err)
+	}
+	plan, err = f.service.GetTripPlanByID(ctx, planID)
+	if err != nil {
+		t.Fatalf("GetTripPlanByID: %v", err)
+	}
+	if plan.JournalID == nil || *plan.JournalID != planEntryID {
+		t.Errorf("plan journal entry = %v, want %d", plan.JournalID, planEntryID)
+	}
+
+	_, err = f.service.DispatchTripPlan(ctx, planID, models.TripDeparture{})
+	assertConflict(t, err, "dispatch plan twice")
+	assertConflict(t, f.service.DeleteTripPlan(ctx, planID), "delete dispatched plan")
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/models"
+)
+
+// Время жизни сессии веб-интерфейса
+const UserSessionTTL = time.Hour
+
+// Ограничения длины полей user_sessions
+const (
+	maxSessionUserAgent  = 255
+	maxSessionRemoteAddr = 64
+)
+
+// Создание серверной сессии; возвращается токен для cookie. Заодно удаляются
+// истекшие сессии, чтобы таблица не росла.
+func (s *AutoParkService) CreateUserSession(ctx context.Context, userID int, userAgent, remoteAddr string) (string, error) {
+	now := time.Now()
+	if _, err := s.db.DeleteExpiredUserSessions(ctx, now); err != nil {
+		return "", err
+	}
+
+	token, tokenHash, err := auth.GenerateSessionToken()
+	if err != nil {
+		return "", fmt.Errorf("ошибка генерации токена сессии: %w", err)
+	}
+	session := models.UserSession{
+		UserID:     userID,
+		ExpiresAt:  now.Add(UserSessionTTL),
+		UserAgent:  truncateRunes(userAgent, maxSessionUserAgent),
+		RemoteAddr: truncateRunes(remoteAddr, maxSessionRemoteAddr),
+	}
+	if _, err := s.db.AddUserSession(ctx, session, tokenHash); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *AutoParkService) AuthenticateUserSession(ctx context.Context, token string) (*models.User, error) {
+	if !strings.HasPrefix(token, auth.SessionTokenPrefix) {
+		return nil, fmt.Errorf("invalid session token")
+	}
+	return s.db.GetUserBySessionHash(ctx, auth.HashToken(token), time.Now())
+}
+
+// Завершение сессии при выходе
+func (s *AutoParkService) EndUserSession(ctx context.Context, token string) error {
+	return s.db.DeleteUserSessionByHash(ctx, auth.HashToken(token))
+}
+
+// Действующие сессии пользователя; userID = 0 — сессии всех пользователей
+func (s *AutoParkService) GetUserSessions(ctx context.Context, userID int) ([]models.UserSession, error) {
+	return s.db.GetUserSessions(ctx, userID, time.Now())
+}
+
+// Отзыв сессии пользователя; userID = 0 — сессии любого пользователя
+func (s *AutoParkService) RevokeUserSession(ctx context.Context, userID, sessionID int) error {
+	if sessionID <= 0 {
+		return newValidationError("invalid session ID")
+	}
+	return s.db.DeleteUserSession(ctx, userID, sessionID)
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return value
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Ограничения длины полей из схемы базы данных
+const (
+	maxDriverNameLength = 20
+	maxCarFieldLength   = 20
+	maxRoutePointLength = 50
+	maxRouteStops       = 50
+	maxRouteDistanceKm  = 100000
+)
+
+// Правила проверки справочников, общие для форм, API и импорта
+func validateDriver(firstName, lastName, fatherName string) error {
+	if firstName == "" || lastName == "" {
+		return newValidationError("first name and last name are required")
+	}
+	if tooLong(maxDriverNameLength, firstName, lastName, fatherName) {
+		return newValidationError("driver names must not exceed %d characters", maxDriverNameLength)
+	}
+	return nil
+}
+
+func validateCar(num, color, mark string) error {
+	if num == "" || color == "" || mark == "" {
+		return newValidationError("num, color and mark are required")
+	}
+	if tooLong(maxCarFieldLength, num, color, mark) {
+		return newValidationError("num, color and mark must not exceed %d characters", maxCarFieldLength)
+	}
+	return nil
+}
+
+func validateRoute(startPoint, endPoint string) error {
+	if startPoint == "" || endPoint == "" {
+		return newValidationError("both startPoint and endPoint are required")
+	}
+	if tooLong(maxRoutePointLength, startPoint, endPoint) {
+		return newValidationError("route points must not exceed %d characters", maxRoutePointLength)
+	}
+	return nil
+}
+
+// Протяженность, плановая длительность, координаты и остановки маршрута
+func validateRouteDetails(route *models.Route) error {
+	if route.DistanceKm != nil && *route.DistanceKm <= 0 {
+		return newValidationError("протяженность маршрута должна быть положительной")
+	}
+	if route.DistanceKm != nil && *route.DistanceKm > maxRouteDistanceKm {
+		return newValidationError("протяженность маршрута не может превышать %d км", maxRouteDistanceKm)
+	}
+	if route.PlannedMinutes != nil && *route.PlannedMinutes <= 0 {
+		return newValidationError("плановая длительность маршрута должна быть положительной")
+	}
+	if err := validateGeoPoint(route.StartCoords, route.StartPoint); err != nil {
+		return err
+	}
+	if err := validateGeoPoint(route.EndCoords, route.EndPoint); err != nil {
+		return err
+	}
+	if len(route.Stops) > maxRouteStops {
+		return newValidationError("маршрут не может содержать больше %d остановок", maxRouteStops)
+	}
+	for i := range route.Stops {
+		stop := &route.Stops[i]
+		stop.Name = strings.TrimSpace(stop.Name)
+		if stop.Name == "" {
+			return newValidationError("укажите название остановки %d", i+1)
+		}
+		if tooLong(maxRoutePointLength, stop.Name) {
+			return newValidationError("route points must not exceed %d characters", maxRoutePointLength)
+		}
+		if err := validateGeoPoint(stop.Coords, stop.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateGeoPoint(point *models.GeoPoint, name string) error {
+	if point == nil {
+		return nil
+	}
+	if point.Lat < -90 || point.Lat > 90 || point.Lon < -180 || point.Lon > 180 {
+		return newValidationError("некорректные координаты точки %q: широта от -90 до 90, долгота от -180 до 180", name)
+	}
+	return nil
+}
+
+func tooLong(limit int, values ...string) bool {
+	for _, value := range values {
+		if utf8.RuneCountInString(value) > limit {
+			return true
+		}
+	}
+	return false
+}
+
+// Показания одометра и топлива в рейсе не могут быть отрицательными
+func validateReadings(odometer *int, fuel, fuelAdded *float64) error {
+	if odometer != nil && *odometer < 0 {
+		return newValidationError("показания одометра не могут быть отрицательными")
+	}
+	if fuel != nil && *fuel < 0 {
+		return newValidationError("остаток топлива не может быть отрицательным")
+	}
+	if fuelAdded != nil && *fuelAdded < 0 {
+		return newValidationError("объем заправки не может быть отрицательным")
+	}
+	return nil
+}
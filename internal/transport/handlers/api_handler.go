@@ -0,0 +1,803 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"AutoParkWeb/internal/database/postgres"
+	"AutoParkWeb/internal/importer"
+	"AutoParkWeb/internal/logging"
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// JSON API поверх тех же методов сервиса, что и HTML-страницы
+type APIHandler struct {
+	service *services.AutoParkService
+	logger  *slog.Logger
+}
+
+func NewAPIHandler(service *services.AutoParkService, logger *slog.Logger) *APIHandler {
+	return &APIHandler{service: service, logger: logger}
+}
+
+// RequestID совпадает с заголовком X-Request-ID и полем request_id в логах
+type apiError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type apiErrorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type driverRequest struct {
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	FatherName string `json:"father_name"`
+
+	Phone             string   `json:"phone"`
+	LicenseNumber     string   `json:"license_number"`
+	LicenseCategories []string `json:"license_categories"`
+	LicenseIssuedAt   string   `json:"license_issued_at"`
+	LicenseExpiresAt  string   `json:"license_expires_at"`
+	MedicalExpiresAt  string   `json:"medical_expires_at"`
+}
+
+func (req driverRequest) documents() (models.DriverDocuments, error) {
+	return parseDriverDocuments(req.Phone, req.LicenseNumber, req.LicenseCategories,
+		req.LicenseIssuedAt, req.LicenseExpiresAt, req.MedicalExpiresAt)
+}
+
+type autoRequest struct {
+	Num        string `json:"num"`
+	Color      string `json:"color"`
+	Mark       string `json:"mark"`
+	Category   string `json:"category"`
+	PersonalID int    `json:"personal_id"`
+}
+
+// Плановые показатели, координаты и остановки необязательны;
+// при изменении маршрута список остановок заменяется целиком
+type routeRequest struct {
+	StartPoint     string             `json:"start_point"`
+	EndPoint       string             `json:"end_point"`
+	StartCoords    *models.GeoPoint   `json:"start_coords"`
+	EndCoords      *models.GeoPoint   `json:"end_coords"`
+	DistanceKm     *float64           `json:"distance_km"`
+	PlannedMinutes *int               `json:"planned_minutes"`
+	Stops          []models.RouteStop `json:"stops"`
+}
+
+func (req routeRequest) route(id int) models.Route {
+	return models.Route{ID: id, StartPoint: req.StartPoint, EndPoint: req.EndPoint, StartCoords: req.StartCoords,
+		EndCoords: req.EndCoords, DistanceKm: req.DistanceKm, PlannedMinutes: req.PlannedMinutes, Stops: req.Stops}
+}
+
+// DriverID можно не указывать: рейс выполняет основной водитель автомобиля
+type journalRequest struct {
+	AutoID   int    `json:"auto_id"`
+	DriverID int    `json:"driver_id"`
+	RouteID  int    `json:"route_id"`
+	TimeOut  string `json:"time_out"`
+	models.TripDeparture
+}
+
+type completeJournalRequest struct {
+	TimeIn string `json:"time_in"`
+	models.TripReturn
+}
+
+type maintenanceRecordRequest struct {
+	AutoID      int     `json:"auto_id"`
+	Type        string  `json:"type"`
+	PerformedAt string  `json:"performed_at"`
+	Odometer    *int    `json:"odometer"`
+	Cost        float64 `json:"cost"`
+	Notes       string  `json:"notes"`
+}
+
+type maintenanceScheduleRequest struct {
+	AutoID       int    `json:"auto_id"`
+	Type         string `json:"type"`
+	IntervalDays *int   `json:"interval_days"`
+	IntervalKm   *int   `json:"interval_km"`
+	Mandatory    *bool  `json:"mandatory"`
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, logger *slog.Logger, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			logger.ErrorContext(r.Context(), "Ошибка кодирования JSON-ответа", "error", err)
+		}
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, status int, code, message string) {
+	requestID := w.Header().Get(logging.RequestIDHeader)
+	writeJSON(w, r, logger, status, apiErrorResponse{Error: apiError{Code: code, Message: message, RequestID: requestID}})
+}
+
+func (h *APIHandler) writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	writeJSON(w, r, h.logger, status, v)
+}
+
+func (h *APIHandler) writeJSONError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeJSONError(w, r, h.logger, status, code, message)
+}
+
+// HTTP-статус для ошибки сервиса или базы данных
+func statusForError(err error) int {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.Is(err, database.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Отображение ошибок сервиса и базы данных в HTTP-статусы
+func (h *APIHandler) writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", validationErr.Message)
+	case errors.Is(err, database.ErrNotFound):
+		h.writeJSONError(w, r, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, database.ErrConflict):
+		h.writeJSONError(w, r, http.StatusConflict, "conflict", err.Error())
+	default:
+		h.logger.ErrorContext(r.Context(), "Ошибка обработки API-запроса", "error", err)
+		h.writeJSONError(w, r, http.StatusInternalServerError, "internal_error", "внутренняя ошибка сервера")
+	}
+}
+
+func (h *APIHandler) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_body", "некорректное тело запроса: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func (h *APIHandler) pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_id", "некорректный ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// Водители
+func (h *APIHandler) ListDrivers(w http.ResponseWriter, r *http.Request) {
+	drivers, err := h.service.GetDrivers(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if drivers == nil {
+		drivers = []models.AutoPersonal{}
+	}
+	h.writeJSON(w, r, http.StatusOK, drivers)
+}
+
+func (h *APIHandler) GetDriver(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	driver, err := h.service.GetDriverByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, driver)
+}
+
+func (h *APIHandler) CreateDriver(w http.ResponseWriter, r *http.Request) {
+	var req driverRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	docs, err := req.documents()
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	id, err := h.service.AddDriver(r.Context(), req.FirstName, req.LastName, req.FatherName, docs)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondDriver(w, r, id, http.StatusCreated)
+}
+
+func (h *APIHandler) UpdateDriver(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var req driverRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	docs, err := req.documents()
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	if err := h.service.UpdateDriver(r.Context(), id, req.FirstName, req.LastName, req.FatherName, docs); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondDriver(w, r, id, http.StatusOK)
+}
+
+// DELETE не удаляет водителя, а переносит его в архив вместе с автомобилями;
+// история рейсов сохраняется
+func (h *APIHandler) DeleteDriver(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.ArchiveDriver(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Восстановление водителя из архива
+func (h *APIHandler) RestoreDriver(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.RestoreDriver(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondDriver(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) respondDriver(w http.ResponseWriter, r *http.Request, id, status int) {
+	driver, err := h.service.GetDriverByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, status, driver)
+}
+
+// Автомобили
+func (h *APIHandler) ListAutos(w http.ResponseWriter, r *http.Request) {
+	cars, err := h.service.GetCars(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if cars == nil {
+		cars = []models.Auto{}
+	}
+	h.writeJSON(w, r, http.StatusOK, cars)
+}
+
+func (h *APIHandler) GetAuto(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	h.respondAuto(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) CreateAuto(w http.ResponseWriter, r *http.Request) {
+	var req autoRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	id, err := h.service.AddCar(r.Context(), req.Num, req.Color, req.Mark, req.Category, req.PersonalID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondAuto(w, r, id, http.StatusCreated)
+}
+
+func (h *APIHandler) UpdateAuto(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var req autoRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.service.UpdateCar(r.Context(), id, req.Num, req.Color, req.Mark, req.Category, req.PersonalID); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondAuto(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) DeleteAuto(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.ArchiveCar(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) RestoreAuto(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.RestoreCar(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondAuto(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) respondAuto(w http.ResponseWriter, r *http.Request, id, status int) {
+	car, driverName, err := h.service.GetCarByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	car.DriverFullName = driverName
+	h.writeJSON(w, r, status, car)
+}
+
+// Маршруты
+func (h *APIHandler) ListRoutes(w http.ResponseWriter, r *http.Request) {
+	routes, err := h.service.GetRoutes(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if routes == nil {
+		routes = []models.Route{}
+	}
+	h.writeJSON(w, r, http.StatusOK, routes)
+}
+
+func (h *APIHandler) GetRoute(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	h.respondRoute(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) CreateRoute(w http.ResponseWriter, r *http.Request) {
+	var req routeRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	id, err := h.service.AddRoute(r.Context(), req.route(0))
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondRoute(w, r, id, http.StatusCreated)
+}
+
+func (h *APIHandler) UpdateRoute(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var req routeRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	route := req.route(id)
+	if err := h.service.UpdateRoute(r.Context(), &route); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondRoute(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) DeleteRoute(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.ArchiveRoute(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) RestoreRoute(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.RestoreRoute(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondRoute(w, r, id, http.StatusOK)
+}
+
+// Архивные водители, автомобили и маршруты
+func (h *APIHandler) ListArchive(w http.ResponseWriter, r *http.Request) {
+	archive, err := h.service.GetArchive(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, archive)
+}
+
+func (h *APIHandler) respondRoute(w http.ResponseWriter, r *http.Request, id, status int) {
+	route, err := h.service.GetRouteByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, status, route)
+}
+
+// Журнал
+func (h *APIHandler) ListJournal(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	page, err := h.service.GetJournalEntries(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if page.Entries == nil {
+		page.Entries = []models.JournalView{}
+	}
+	h.writeJSON(w, r, http.StatusOK, page)
+}
+
+func (h *APIHandler) GetJournalEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	h.respondJournalEntry(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) CreateJournalEntry(w http.ResponseWriter, r *http.Request) {
+	var req journalRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	id, err := h.service.AddJournalEntry(r.Context(), req.AutoID, req.DriverID, req.RouteID, req.TimeOut, req.TripDeparture)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondJournalEntry(w, r, id, http.StatusCreated)
+}
+
+func (h *APIHandler) CompleteJournalEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var req completeJournalRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.service.CompleteJournalEntry(r.Context(), id, req.TimeIn, req.TripReturn); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondJournalEntry(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) DeleteJournalEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteJournalEntry(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) respondJournalEntry(w http.ResponseWriter, r *http.Request, id, status int) {
+	entry, err := h.service.GetJournalEntryByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, status, entry)
+}
+
+// Аналитика
+func (h *APIHandler) Statistics(w http.ResponseWriter, r *http.Request) {
+	routesVehicleCount, err := h.service.GetRoutesVehicleCount(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if routesVehicleCount == nil {
+		routesVehicleCount = []models.RouteVehicleCount{}
+	}
+	h.writeJSON(w, r, http.StatusOK, struct {
+		RoutesVehicleCount []models.RouteVehicleCount `json:"routes_vehicle_count"`
+	}{
+		RoutesVehicleCount: routesVehicleCount,
+	})
+}
+
+// Пробег и расход топлива; фильтры те же, что у журнала
+func (h *APIHandler) Mileage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	report, err := h.service.GetMileageReport(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, report)
+}
+
+// Журнал аудита изменений
+func (h *APIHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	page, err := h.service.GetAuditLog(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if page.Entries == nil {
+		page.Entries = []models.AuditEntry{}
+	}
+	h.writeJSON(w, r, http.StatusOK, page)
+}
+
+// История обслуживания; auto_id в строке запроса ограничивает выборку одним автомобилем
+func (h *APIHandler) ListMaintenanceRecords(w http.ResponseWriter, r *http.Request) {
+	autoID, err := parseOptionalInt(r.URL.Query(), "auto_id")
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	records, err := h.service.GetMaintenanceRecords(r.Context(), autoID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, records)
+}
+
+func (h *APIHandler) CreateMaintenanceRecord(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceRecordRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	record := models.MaintenanceRecord{
+		AutoID:   req.AutoID,
+		Type:     req.Type,
+		Odometer: req.Odometer,
+		Cost:     req.Cost,
+		Notes:    req.Notes,
+	}
+	if req.PerformedAt != "" {
+		performedAt, err := time.Parse("2006-01-02", req.PerformedAt)
+		if err != nil {
+			h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", "некорректная дата обслуживания: "+req.PerformedAt)
+			return
+		}
+		record.PerformedAt = performedAt
+	}
+	id, err := h.service.AddMaintenanceRecord(r.Context(), record)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	records, err := h.service.GetMaintenanceRecords(r.Context(), record.AutoID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	for _, created := range records {
+		if created.ID == id {
+			h.writeJSON(w, r, http.StatusCreated, created)
+			return
+		}
+	}
+	h.writeJSONError(w, r, http.StatusNotFound, "not_found", "maintenance record not found")
+}
+
+func (h *APIHandler) DeleteMaintenanceRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteMaintenanceRecord(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) ListMaintenanceSchedules(w http.ResponseWriter, r *http.Request) {
+	autoID, err := parseOptionalInt(r.URL.Query(), "auto_id")
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	schedules, err := h.service.GetMaintenanceSchedules(r.Context(), autoID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, schedules)
+}
+
+// Регламент по умолчанию обязательный, как и в форме на странице обслуживания
+func (h *APIHandler) CreateMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceScheduleRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	schedule := models.MaintenanceSchedule{
+		AutoID:       req.AutoID,
+		Type:         req.Type,
+		IntervalDays: req.IntervalDays,
+		IntervalKm:   req.IntervalKm,
+		Mandatory:    req.Mandatory == nil || *req.Mandatory,
+	}
+	id, err := h.service.AddMaintenanceSchedule(r.Context(), schedule)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	schedules, err := h.service.GetMaintenanceSchedules(r.Context(), schedule.AutoID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	for _, created := range schedules {
+		if created.ID == id {
+			h.writeJSON(w, r, http.StatusCreated, created)
+			return
+		}
+	}
+	h.writeJSONError(w, r, http.StatusNotFound, "not_found", "maintenance schedule not found")
+}
+
+func (h *APIHandler) DeleteMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteMaintenanceSchedule(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Сроки обслуживания по регламентам: просроченные, предстоящие и в норме
+func (h *APIHandler) MaintenanceStatus(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.service.GetServiceStatuses(r.Context(), time.Now())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, statuses)
+}
+
+// Импорт справочников из файла (multipart, поле file). При dry_run=true
+// возвращается только результат проверки; если в строках есть ошибки,
+// данные не сохраняются и ответ приходит со статусом 422.
+func (h *APIHandler) Import(w http.ResponseWriter, r *http.Request) {
+	fileName, data, err := readImportUpload(w, r)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_file", err.Error())
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	result, err := importFile(r, h.service, fileName, data, dryRun)
+	if err != nil {
+		var fileErr *importer.FileError
+		if errors.As(err, &fileErr) {
+			h.writeJSONError(w, r, http.StatusBadRequest, "invalid_file", fileErr.Message)
+			return
+		}
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Committed {
+		status = http.StatusCreated
+	} else if !result.DryRun && result.ErrorCount > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	h.writeJSON(w, r, status, result)
+}
+
+// Токены доступа текущего пользователя
+type createTokenRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type createTokenResponse struct {
+	Token string `json:"token"`
+	models.APIToken
+}
+
+func (h *APIHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.service.GetAPITokens(r.Context(), currentUser(r).ID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if tokens == nil {
+		tokens = []models.APIToken{}
+	}
+	h.writeJSON(w, r, http.StatusOK, tokens)
+}
+
+func (h *APIHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var req createTokenRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	token, apiToken, err := h.service.CreateAPIToken(r.Context(), currentUser(r).ID, req.Name, req.ExpiresAt)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusCreated, createTokenResponse{Token: token, APIToken: *apiToken})
+}
+
+func (h *APIHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.RevokeAPIToken(r.Context(), currentUser(r).ID, id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Перенос водителя в архив вместе с его автомобилями
+func (h *AutoParkHandler) ArchiveDriver(w http.ResponseWriter, r *http.Request) {
+	h.archiveEntity(w, r, "Некорректный ID водителя", "Не удалось перенести водителя в архив", h.service.ArchiveDriver)
+}
+
+// Перенос автомобиля в архив
+func (h *AutoParkHandler) ArchiveCar(w http.ResponseWriter, r *http.Request) {
+	h.archiveEntity(w, r, "Некорректный ID автомобиля", "Не удалось перенести автомобиль в архив", h.service.ArchiveCar)
+}
+
+// Перенос маршрута в архив
+func (h *AutoParkHandler) ArchiveRoute(w http.ResponseWriter, r *http.Request) {
+	h.archiveEntity(w, r, "Некорректный ID маршрута", "Не удалось перенести маршрут в архив", h.service.ArchiveRoute)
+}
+
+func (h *AutoParkHandler) archiveEntity(w http.ResponseWriter, r *http.Request, invalidID, failed string, archive func(context.Context, int) error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, invalidID, http.StatusBadRequest)
+		return
+	}
+
+	if err := archive(r.Context(), id); err != nil {
+		h.serviceError(w, r, failed, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Восстановление водителя из архива
+func (h *AutoParkHandler) RestoreDriver(w http.ResponseWriter, r *http.Request) {
+	h.restoreEntity(w, r, "Некорректный ID водителя", "Не удалось восстановить водителя", h.service.RestoreDriver)
+}
+
+// Восстановление автомобиля из архива
+func (h *AutoParkHandler) RestoreCar(w http.ResponseWriter, r *http.Request) {
+	h.restoreEntity(w, r, "Некорректный ID автомобиля", "Не удалось восстановить автомобиль", h.service.RestoreCar)
+}
+
+// Восстановление маршрута из архива
+func (h *AutoParkHandler) RestoreRoute(w http.ResponseWriter, r *http.Request) {
+	h.restoreEntity(w, r, "Некорректный ID маршрута", "Не удалось восстановить маршрут", h.service.RestoreRoute)
+}
+
+func (h *AutoParkHandler) restoreEntity(w http.ResponseWriter, r *http.Request, invalidID, failed string, restore func(context.Context, int) error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, invalidID, http.StatusBadRequest)
+		return
+	}
+
+	if err := restore(r.Context(), id); err != nil {
+		h.serviceError(w, r, failed, err)
+		return
+	}
+
+	http.Redirect(w, r, "/archive", http.StatusSeeOther)
+}
+
+// Страница архива водителей, автомобилей и маршрутов
+func (h *AutoParkHandler) ArchivePage(w http.ResponseWriter, r *http.Request) {
+	archive, err := h.service.GetArchive(r.Context())
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить архив", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/archive.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title    string
+		Archive  *models.Archive
+		UserRole string
+		Username string
+	}{
+		Title:    "Архив",
+		Archive:  archive,
+		UserRole: user.Role,
+		Username: user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
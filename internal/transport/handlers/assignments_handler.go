@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Фильтр закреплений из строки запроса: auto_id, driver_id и active_on (дата)
+func parseAssignmentFilter(query url.Values) (models.AssignmentFilter, error) {
+	var filter models.AssignmentFilter
+	var err error
+	if filter.AutoID, err = parseOptionalInt(query, "auto_id"); err != nil {
+		return filter, err
+	}
+	if filter.DriverID, err = parseOptionalInt(query, "driver_id"); err != nil {
+		return filter, err
+	}
+	if filter.ActiveOn, err = parseOptionalDate(query.Get("active_on"), "active_on"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// Страница закрепления водителей за автомобилями
+func (h *AutoParkHandler) AssignmentsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := parseAssignmentFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	assignments, err := h.service.GetAssignments(ctx, filter)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить закрепления", err)
+		return
+	}
+	cars, err := h.service.GetCars(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список автомобилей", err)
+		return
+	}
+	drivers, err := h.service.GetDrivers(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/assignments.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	now := time.Now()
+	err = tmpl.Execute(w, struct {
+		Title       string
+		Assignments []models.Assignment
+		Cars        []models.Auto
+		Drivers     []models.AutoPersonal
+		Filter      models.AssignmentFilter
+		Now         time.Time
+		Today       string
+		UserRole    string
+		Username    string
+	}{
+		Title:       "Закрепление водителей за автомобилями",
+		Assignments: assignments,
+		Cars:        cars,
+		Drivers:     drivers,
+		Filter:      filter,
+		Now:         now,
+		Today:       now.Format("2006-01-02"),
+		UserRole:    user.Role,
+		Username:    user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
+
+func (h *AutoParkHandler) AddAssignment(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+
+	var assignment models.Assignment
+	assignment.AutoID, _ = strconv.Atoi(r.FormValue("auto_id"))
+	assignment.DriverID, _ = strconv.Atoi(r.FormValue("driver_id"))
+	validFrom, err := parseOptionalDate(r.FormValue("valid_from"), "начала закрепления")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if validFrom != nil {
+		assignment.ValidFrom = *validFrom
+	}
+	if assignment.ValidTo, err = parseOptionalDate(r.FormValue("valid_to"), "окончания закрепления"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.AddAssignment(r.Context(), assignment); err != nil {
+		h.serviceError(w, r, "Не удалось закрепить водителя", err)
+		return
+	}
+	http.Redirect(w, r, "/assignments", http.StatusSeeOther)
+}
+
+// Завершение закрепления датой из формы; по умолчанию — сегодняшним днем
+func (h *AutoParkHandler) EndAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID закрепления", http.StatusBadRequest)
+		return
+	}
+	validTo, err := parseOptionalDate(r.FormValue("valid_to"), "окончания закрепления")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if validTo == nil {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		validTo = &today
+	}
+
+	if err := h.service.EndAssignment(r.Context(), id, *validTo); err != nil {
+		h.serviceError(w, r, "Не удалось завершить закрепление", err)
+		return
+	}
+	http.Redirect(w, r, "/assignments", http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID закрепления", http.StatusBadRequest)
+		return
+	}
+	if err := h.service.DeleteAssignment(r.Context(), id); err != nil {
+		h.serviceError(w, r, "Не удалось удалить закрепление", err)
+		return
+	}
+	http.Redirect(w, r, "/assignments", http.StatusSeeOther)
+}
+
+type assignmentRequest struct {
+	AutoID    int    `json:"auto_id"`
+	DriverID  int    `json:"driver_id"`
+	ValidFrom string `json:"valid_from"`
+	ValidTo   string `json:"valid_to"`
+}
+
+type assignmentPeriodRequest struct {
+	ValidFrom string `json:"valid_from"`
+	ValidTo   string `json:"valid_to"`
+}
+
+// Закрепления по фильтру: auto_id, driver_id, active_on=YYYY-MM-DD
+func (h *APIHandler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAssignmentFilter(r.URL.Query())
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	assignments, err := h.service.GetAssignments(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, assignments)
+}
+
+func (h *APIHandler) GetAssignment(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	h.respondAssignment(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) CreateAssignment(w http.ResponseWriter, r *http.Request) {
+	var req assignmentRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	assignment := models.Assignment{AutoID: req.AutoID, DriverID: req.DriverID}
+	validFrom, validTo, ok := h.parseAssignmentPeriod(w, r, req.ValidFrom, req.ValidTo)
+	if !ok {
+		return
+	}
+	if validFrom != nil {
+		assignment.ValidFrom = *validFrom
+	}
+	assignment.ValidTo = validTo
+
+	id, err := h.service.AddAssignment(r.Context(), assignment)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondAssignment(w, r, id, http.StatusCreated)
+}
+
+// Изменение периода закрепления; пустой valid_to — бессрочно
+func (h *APIHandler) UpdateAssignment(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var req assignmentPeriodRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	validFrom, validTo, ok := h.parseAssignmentPeriod(w, r, req.ValidFrom, req.ValidTo)
+	if !ok {
+		return
+	}
+	if validFrom == nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", "укажите дату начала закрепления")
+		return
+	}
+
+	if err := h.service.UpdateAssignmentPeriod(r.Context(), id, *validFrom, validTo); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondAssignment(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteAssignment(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) respondAssignment(w http.ResponseWriter, r *http.Request, id, status int) {
+	assignment, err := h.service.GetAssignmentByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, status, assignment)
+}
+
+func (h *APIHandler) parseAssignmentPeriod(w http.ResponseWriter, r *http.Request, from, to string) (*time.Time, *time.Time, bool) {
+	validFrom, err := parseOptionalDate(from, "valid_from")
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return nil, nil, false
+	}
+	validTo, err := parseOptionalDate(to, "valid_to")
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return nil, nil, false
+	}
+	return validFrom, validTo, true
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Разбор параметров фильтрации журнала аудита из строки запроса
+func parseAuditFilter(r *http.Request) (models.AuditFilter, error) {
+	query := r.URL.Query()
+	var filter models.AuditFilter
+
+	if value := query.Get("from"); value != "" {
+		from, err := parseFilterDate(value)
+		if err != nil {
+			return filter, fmt.Errorf("некорректная дата from: %s", value)
+		}
+		filter.From = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := parseFilterDate(value)
+		if err != nil {
+			return filter, fmt.Errorf("некорректная дата to: %s", value)
+		}
+		if len(value) == len("2006-01-02") {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	var err error
+	if filter.UserID, err = parseOptionalInt(query, "user_id"); err != nil {
+		return filter, err
+	}
+	if filter.EntityID, err = parseOptionalInt(query, "entity_id"); err != nil {
+		return filter, err
+	}
+	if filter.Page, err = parseOptionalInt(query, "page"); err != nil {
+		return filter, err
+	}
+	if filter.PageSize, err = parseOptionalInt(query, "page_size"); err != nil {
+		return filter, err
+	}
+	filter.Action = query.Get("action")
+	filter.EntityType = query.Get("entity_type")
+
+	return filter, nil
+}
+
+// Изменение одного поля для отображения записи аудита
+type auditChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+type auditRow struct {
+	models.AuditEntry
+	Changes []auditChange
+}
+
+// Поля, которые отличаются в снимках до и после изменения
+func auditChanges(entry models.AuditEntry) []auditChange {
+	var before, after map[string]interface{}
+	json.Unmarshal(entry.Before, &before)
+	json.Unmarshal(entry.After, &after)
+
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	var changes []auditChange
+	for field := range fields {
+		oldValue, hadOld := before[field]
+		newValue, hasNew := after[field]
+		if hadOld && hasNew && fmt.Sprint(oldValue) == fmt.Sprint(newValue) {
+			continue
+		}
+		change := auditChange{Field: field}
+		if hadOld {
+			change.Before = formatAuditValue(oldValue)
+		}
+		if hasNew {
+			change.After = formatAuditValue(newValue)
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func formatAuditValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "—"
+	case float64:
+		return fmt.Sprintf("%g", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Страница журнала аудита
+func (h *AutoParkHandler) AuditPage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetAuditLog(r.Context(), filter)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить журнал аудита", err)
+		return
+	}
+
+	rows := make([]auditRow, 0, len(page.Entries))
+	for _, entry := range page.Entries {
+		rows = append(rows, auditRow{AuditEntry: entry, Changes: auditChanges(entry)})
+	}
+
+	query := r.URL.Query()
+	totalPages := (page.Total + page.PageSize - 1) / page.PageSize
+	var prevQuery, nextQuery template.URL
+	if page.Page > 1 {
+		prevQuery = pageQuery(query, page.Page-1)
+	}
+	if page.Page < totalPages {
+		nextQuery = pageQuery(query, page.Page+1)
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/audit.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title      string
+		Entries    []auditRow
+		Query      url.Values
+		Total      int
+		Page       int
+		TotalPages int
+		PrevQuery  template.URL
+		NextQuery  template.URL
+		UserRole   string
+		Username   string
+	}{
+		Title:      "Журнал аудита",
+		Entries:    rows,
+		Query:      query,
+		Total:      page.Total,
+		Page:       page.Page,
+		TotalPages: totalPages,
+		PrevQuery:  prevQuery,
+		NextQuery:  nextQuery,
+		UserRole:   user.Role,
+		Username:   user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/logging"
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type AuthMiddleware struct {
+	service  *services.AutoParkService
+	sessions SessionManager
+	logger   *slog.Logger
+}
+
+func NewAuthMiddleware(service *services.AutoParkService, sessions SessionManager, logger *slog.Logger) *AuthMiddleware {
+	return &AuthMiddleware{service: service, sessions: sessions, logger: logger}
+}
+
+// Middleware проверки аутентификации и роли пользователя.
+// Пользователь берется из токена Authorization: Bearer или из сессии
+// и кладется в контекст запроса, при пустом списке ролей достаточно
+// любой аутентификации.
+func (m *AuthMiddleware) RequireRole(roles ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := m.authenticate(r)
+			if !ok {
+				if wantsJSON(r) {
+					writeJSONError(w, r, m.logger, http.StatusUnauthorized, "unauthorized", "требуется аутентификация")
+					return
+				}
+				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				return
+			}
+			logging.SetUser(r.Context(), user.Username)
+
+			if !hasRole(user.Role, roles) {
+				if wantsJSON(r) {
+					writeJSONError(w, r, m.logger, http.StatusForbidden, "forbidden", "недостаточно прав")
+					return
+				}
+				http.Error(w, "Недостаточно прав для выполнения операции", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+		})
+	}
+}
+
+func (m *AuthMiddleware) authenticate(r *http.Request) (*models.User, bool) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return m.sessions.User(r)
+	}
+
+	// Предъявленный, но недействительный токен не подменяется сессией
+	user, err := m.service.AuthenticateAPIToken(r.Context(), token)
+	if err != nil {
+		return nil, false
+	}
+	return user, true
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
+
+// Текущий пользователь запроса, установленный RequireRole
+func currentUser(r *http.Request) *models.User {
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		return user
+	}
+	return &models.User{}
+}
+
+func hasRole(role string, roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// API-клиенты получают JSON вместо HTML-страниц и редиректов
+func wantsJSON(r *http.Request) bool {
+	_, hasToken := bearerToken(r)
+	return hasToken || strings.HasPrefix(r.URL.Path, "/api/") ||
+		strings.Contains(r.Header.Get("Accept"), "application/json") ||
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+}
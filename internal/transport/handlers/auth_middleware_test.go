@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/database/memory"
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+)
+
+// Пользователь берется из токена или из сессии; недействительный токен не
+// подменяется сессией, API-клиенты получают JSON, браузер — редирект или текст
+func TestRequireRole(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := services.NewAutoParkService(memory.New(), nil, logger)
+	user := testUser(t, service, "driver")
+	token, _, err := service.CreateAPIToken(context.Background(), user.ID, "ci", nil)
+	if err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+
+	sessions := NewCookieSessions([][]byte{bytes.Repeat([]byte("k"), 32)}, false)
+	login := httptest.NewRecorder()
+	if err := sessions.Login(login, httptest.NewRequest(http.MethodPost, "/login", nil), user); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	cookies := login.Result().Cookies()
+
+	middleware := NewAuthMiddleware(service, sessions, logger)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current, ok := auth.UserFromContext(r.Context())
+		if !ok || current.ID != user.ID {
+			t.Errorf("%s: user in context = %v, want %d", r.URL.Path, current, user.ID)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	authenticated := middleware.RequireRole()(next)
+	adminOnly := middleware.RequireRole(models.RoleAdmin)(next)
+
+	for _, tc := range []struct {
+		name     string
+		handler  http.Handler
+		path     string
+		session  bool
+		bearer   string
+		status   int
+		code     string
+		location string
+	}{
+		{"no credentials, page", authenticated, "/journal", false, "", http.StatusSeeOther, "", "/login"},
+		{"no credentials, api", authenticated, "/api/v1/drivers", false, "", http.StatusUnauthorized, "unauthorized", ""},
+		{"session", authenticated, "/journal", true, "", http.StatusNoContent, "", ""},
+		{"bearer token", authenticated, "/api/v1/drivers", false, token, http.StatusNoContent, "", ""},
+		{"invalid bearer with session", authenticated, "/journal", true, "invalid", http.StatusUnauthorized, "unauthorized", ""},
+		{"wrong role, page", adminOnly, "/journal/new", true, "", http.StatusForbidden, "", ""},
+		{"wrong role, api", adminOnly, "/api/v1/drivers", false, token, http.StatusForbidden, "forbidden", ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.session {
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+		}
+		if tc.bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.bearer)
+		}
+		rec := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: status %d, want %d (%s)", tc.name, rec.Code, tc.status, rec.Body.String())
+			continue
+		}
+		if location := rec.Header().Get("Location"); location != tc.location {
+			t.Errorf("%s: Location %q, want %q", tc.name, location, tc.location)
+		}
+		isJSON := rec.Header().Get("Content-Type") == "application/json"
+		if tc.code == "" {
+			if isJSON {
+				t.Errorf("%s: unexpected JSON response %s", tc.name, rec.Body.String())
+			}
+			continue
+		}
+		var body apiErrorResponse
+		if !isJSON || json.Unmarshal(rec.Body.Bytes(), &body) != nil || body.Error.Code != tc.code {
+			t.Errorf("%s: body %s, want JSON error %q", tc.name, rec.Body.String(), tc.code)
+		}
+	}
+}
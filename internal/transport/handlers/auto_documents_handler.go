@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+)
+
+// Названия видов документов автомобиля в порядке вывода в формах
+type autoDocumentType struct {
+	Value string
+	Title string
+}
+
+var autoDocumentTypes = []autoDocumentType{
+	{models.AutoDocumentInsurance, "Страховой полис (ОСАГО)"},
+	{models.AutoDocumentRegistration, "Свидетельство о регистрации"},
+	{models.AutoDocumentInspection, "Диагностическая карта (техосмотр)"},
+}
+
+// Скан из поля file формы; пустое поле — nil без ошибки
+func readAttachmentUpload(w http.ResponseWriter, r *http.Request) (string, []byte, error) {
+	// ParseMultipartForm ограничивает только память, остальное уходит во
+	// временные файлы; запас в 1 МБ — на остальные поля формы
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxAttachmentSize+1<<20)
+	if err := r.ParseMultipartForm(services.MaxAttachmentSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", nil, fmt.Errorf("размер файла превышает %d МБ", services.MaxAttachmentSize>>20)
+		}
+		return "", nil, errors.New("не удалось прочитать форму")
+	}
+	file, header, err := r.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, errors.New("не удалось прочитать файл")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAttachmentSize+1))
+	if err != nil {
+		return "", nil, errors.New("не удалось прочитать файл")
+	}
+	if len(data) > services.MaxAttachmentSize {
+		return "", nil, fmt.Errorf("размер файла превышает %d МБ", services.MaxAttachmentSize>>20)
+	}
+	return header.Filename, data, nil
+}
+
+// Отдача скана документа; PDF и изображения открываются в браузере
+func serveAttachment(w http.ResponseWriter, r *http.Request, service *services.AutoParkService, fileID int) error {
+	file, content, err := service.OpenAutoDocumentFile(r.Context(), fileID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": file.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", file.UploadedAt, content)
+	return nil
+}
+
+// Страница документов автомобиля
+func (h *AutoParkHandler) AutoDocumentsPage(w http.ResponseWriter, r *http.Request) {
+	carID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID автомобиля", http.StatusBadRequest)
+		return
+	}
+
+	car, _, err := h.service.GetCarByID(r.Context(), carID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить данные автомобиля", err)
+		return
+	}
+	documents, err := h.service.GetAutoDocuments(r.Context(), carID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить документы", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/autos_table/documents.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	titles := make(map[string]string, len(autoDocumentTypes))
+	for _, documentType := range autoDocumentTypes {
+		titles[documentType.Value] = documentType.Title
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title     string
+		Car       *models.Auto
+		Documents []models.AutoDocument
+		Types     []autoDocumentType
+		Titles    map[string]string
+		Now       time.Time
+		Today     string
+		UserRole  string
+		Username  string
+	}{
+		Title:     "Документы автомобиля " + car.Num,
+		Car:       car,
+		Documents: documents,
+		Types:     autoDocumentTypes,
+		Titles:    titles,
+		Now:       time.Now(),
+		Today:     time.Now().Format("2006-01-02"),
+		UserRole:  user.Role,
+		Username:  user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
+
+// Добавление документа из формы; скан можно приложить сразу
+func (h *AutoParkHandler) AddAutoDocument(w http.ResponseWriter, r *http.Request) {
+	carID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID автомобиля", http.StatusBadRequest)
+		return
+	}
+	fileName, data, err := readAttachmentUpload(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	document := models.AutoDocument{
+		AutoID: carID,
+		Type:   r.FormValue("type"),
+		Number: r.FormValue("number"),
+		Notes:  r.FormValue("notes"),
+	}
+	if document.IssuedAt, err = parseOptionalDate(r.FormValue("issued_at"), "выдачи"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if document.ExpiresAt, err = parseOptionalDate(r.FormValue("expires_at"), "окончания действия"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	documentID, err := h.service.AddAutoDocument(r.Context(), document)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось добавить документ", err)
+		return
+	}
+	if data != nil {
+		if _, err := h.service.AddAutoDocumentFile(r.Context(), documentID, fileName, data); err != nil {
+			h.serviceError(w, r, "Документ добавлен, но файл не сохранен", err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/autos/%d/documents", carID), http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) DeleteAutoDocument(w http.ResponseWriter, r *http.Request) {
+	documentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID документа", http.StatusBadRequest)
+		return
+	}
+	document, err := h.service.GetAutoDocumentByID(r.Context(), documentID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось удалить документ", err)
+		return
+	}
+
+	if err := h.service.DeleteAutoDocument(r.Context(), documentID); err != nil {
+		h.serviceError(w, r, "Не удалось удалить документ", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/autos/%d/documents", document.AutoID), http.StatusSeeOther)
+}
+
+// Загрузка скана к существующему документу
+func (h *AutoParkHandler) UploadAutoDocumentFile(w http.ResponseWriter, r *http.Request) {
+	documentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID документа", http.StatusBadRequest)
+		return
+	}
+	document, err := h.service.GetAutoDocumentByID(r.Context(), documentID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить файл", err)
+		return
+	}
+
+	fileName, data, err := readAttachmentUpload(w, r)
+	if err == nil && data == nil {
+		err = errors.New("файл не выбран")
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := h.service.AddAutoDocumentFile(r.Context(), documentID, fileName, data); err != nil {
+		h.serviceError(w, r, "Не удалось загрузить файл", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/autos/%d/documents", document.AutoID), http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) DownloadAutoDocumentFile(w http.ResponseWriter, r *http.Request) {
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID файла", http.StatusBadRequest)
+		return
+	}
+	if err := serveAttachment(w, r, h.service, fileID); err != nil {
+		h.serviceError(w, r, "Не удалось открыть файл", err)
+	}
+}
+
+func (h *AutoParkHandler) DeleteAutoDocumentFile(w http.ResponseWriter, r *http.Request) {
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID файла", http.StatusBadRequest)
+		return
+	}
+	file, err := h.service.GetAutoDocumentFile(r.Context(), fileID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось удалить файл", err)
+		return
+	}
+	document, err := h.service.GetAutoDocumentByID(r.Context(), file.DocumentID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось удалить файл", err)
+		return
+	}
+
+	if err := h.service.DeleteAutoDocumentFile(r.Context(), fileID); err != nil {
+		h.serviceError(w, r, "Не удалось удалить файл", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/autos/%d/documents", document.AutoID), http.StatusSeeOther)
+}
+
+type autoDocumentRequest struct {
+	Type      string `json:"type"`
+	Number    string `json:"number"`
+	IssuedAt  string `json:"issued_at"`
+	ExpiresAt string `json:"expires_at"`
+	Notes     string `json:"notes"`
+}
+
+// Документы автомобиля вместе со списками сканов
+func (h *APIHandler) ListAutoDocuments(w http.ResponseWriter, r *http.Request) {
+	autoID, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if _, _, err := h.service.GetCarByID(r.Context(), autoID); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	documents, err := h.service.GetAutoDocuments(r.Context(), autoID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if documents == nil {
+		documents = []models.AutoDocument{}
+	}
+	h.writeJSON(w, r, http.StatusOK, documents)
+}
+
+func (h *APIHandler) CreateAutoDocument(w http.ResponseWriter, r *http.Request) {
+	autoID, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var req autoDocumentRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	document := models.AutoDocument{AutoID: autoID, Type: req.Type, Number: req.Number, Notes: req.Notes}
+	var err error
+	if document.IssuedAt, err = parseOptionalDate(req.IssuedAt, "выдачи"); err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	if document.ExpiresAt, err = parseOptionalDate(req.ExpiresAt, "окончания действия"); err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	id, err := h.service.AddAutoDocument(r.Context(), document)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondAutoDocument(w, r, id, http.StatusCreated)
+}
+
+func (h *APIHandler) GetAutoDocument(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	h.respondAutoDocument(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) DeleteAutoDocument(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteAutoDocument(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) respondAutoDocument(w http.ResponseWriter, r *http.Request, id, status int) {
+	document, err := h.service.GetAutoDocumentByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	if document.Files == nil {
+		document.Files = []models.AutoDocumentFile{}
+	}
+	h.writeJSON(w, r, status, document)
+}
+
+// Загрузка скана документа (multipart, поле file)
+func (h *APIHandler) UploadAutoDocumentFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	fileName, data, err := readAttachmentUpload(w, r)
+	if err == nil && data == nil {
+		err = errors.New("файл не передан")
+	}
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_file", err.Error())
+		return
+	}
+	if _, err := h.service.GetAutoDocumentByID(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	file, err := h.service.AddAutoDocumentFile(r.Context(), id, fileName, data)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusCreated, file)
+}
+
+func (h *APIHandler) DownloadAutoDocumentFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := serveAttachment(w, r, h.service, id); err != nil {
+		h.writeServiceError(w, r, err)
+	}
+}
+
+func (h *APIHandler) DeleteAutoDocumentFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteAutoDocumentFile(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
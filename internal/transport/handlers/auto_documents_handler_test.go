@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"AutoParkWeb/internal/services"
+)
+
+// Тело запроса больше лимита отклоняется целиком, а не пишется во временные файлы
+func TestReadAttachmentUploadLimitsBody(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		size    int
+		wantErr string
+	}{
+		{"small file", 1 << 10, ""},
+		{"oversized body", services.MaxAttachmentSize + 2<<20, "превышает"},
+	} {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, err := mw.CreateFormFile("file", "scan.pdf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(bytes.Repeat([]byte("x"), tc.size))
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/documents", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		fileName, data, err := readAttachmentUpload(httptest.NewRecorder(), req)
+		if tc.wantErr == "" {
+			if err != nil || fileName != "scan.pdf" || len(data) != tc.size {
+				t.Errorf("%s: got %q, %d bytes, %v", tc.name, fileName, len(data), err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: error %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
@@ -1,74 +1,50 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
-	"fmt"
-	"github.com/xuri/excelize/v2"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"time"
 
+	"AutoParkWeb/internal/export"
 	"AutoParkWeb/internal/models"
 	"AutoParkWeb/internal/services"
 	"github.com/gorilla/mux"
 )
 
 type AutoParkHandler struct {
-	service *services.AutoParkService
+	service  *services.AutoParkService
+	exporter *export.JournalExporter
+	logger   *slog.Logger
 }
 
-func NewAutoParkHandler(service *services.AutoParkService) *AutoParkHandler {
-	return &AutoParkHandler{service: service}
+func NewAutoParkHandler(service *services.AutoParkService, exporter *export.JournalExporter, logger *slog.Logger) *AutoParkHandler {
+	return &AutoParkHandler{service: service, exporter: exporter, logger: logger}
 }
 
-// Получение роли пользователя
-func (h *AutoParkHandler) getUserRole(r *http.Request) (string, error) {
-	session, err := store.Get(r, "session-name")
-	if err != nil {
-		return "", fmt.Errorf("ошибка при получении сессии: %w", err)
-	}
-
-	userRole, ok := session.Values["user_role"].(string)
-	if !ok {
-		return "", fmt.Errorf("не удалось получить роль пользователя")
-	}
-
-	return userRole, nil
-}
-
-func (h *AutoParkHandler) getUserName(r *http.Request) (string, error) {
-	session, err := store.Get(r, "session-name")
-	if err != nil {
-		return "", fmt.Errorf("ошибка при получении сессии: %w", err)
-	}
-
-	username, ok := session.Values["username"].(string)
-	if !ok {
-		return "", fmt.Errorf("не удалось получить имя пользователя")
+// Страница ошибки сервиса. Причина ошибок валидации, отсутствующих записей
+// и конфликтов показывается пользователю, а внутренние ошибки пишутся в лог
+// и заменяются общим сообщением.
+func (h *AutoParkHandler) serviceError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	status := statusForError(err)
+	if status >= http.StatusInternalServerError {
+		h.logger.ErrorContext(r.Context(), message, "error", err)
+		http.Error(w, message, status)
+		return
 	}
-
-	return username, nil
+	http.Error(w, message+": "+err.Error(), status)
 }
 
 // Метод для получения списка водителей
 func (h *AutoParkHandler) GetDrivers(w http.ResponseWriter, r *http.Request) {
 	drivers, err := h.service.GetDrivers(r.Context())
 	if err != nil {
-		http.Error(w, "Не удалось загрузить список водителей", http.StatusInternalServerError)
-		return
-	}
-
-	userRole, err := h.getUserRole(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-		return
-	}
-
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
 		return
 	}
 
@@ -76,7 +52,7 @@ func (h *AutoParkHandler) GetDrivers(w http.ResponseWriter, r *http.Request) {
 		"./ui/template/layout.html", "./ui/template/drivers_table/drivers.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
@@ -88,12 +64,12 @@ func (h *AutoParkHandler) GetDrivers(w http.ResponseWriter, r *http.Request) {
 	}{
 		Title:    "Водители",
 		Drivers:  drivers,
-		UserRole: userRole,
-		Username: userName,
+		UserRole: currentUser(r).Role,
+		Username: currentUser(r).Username,
 	})
 
 	if err != nil {
-		http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -105,22 +81,18 @@ func (h *AutoParkHandler) AddDriverPage(w http.ResponseWriter, r *http.Request)
 		"./ui/template/layout.html", "./ui/template/drivers_table/add_driver.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
 	tmpl.Execute(w, struct {
-		Title    string
-		Username string
+		Title      string
+		Categories []string
+		Username   string
 	}{
-		Title:    "Добавление водителя",
-		Username: userName,
+		Title:      "Добавление водителя",
+		Categories: models.LicenseCategories,
+		Username:   currentUser(r).Username,
 	})
 }
 
@@ -130,10 +102,15 @@ func (h *AutoParkHandler) AddDriver(w http.ResponseWriter, r *http.Request) {
 		firstName := r.FormValue("first_name")
 		lastName := r.FormValue("last_name")
 		fatherName := r.FormValue("father_name")
+		docs, err := formDriverDocuments(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-		err := h.service.AddDriver(r.Context(), firstName, lastName, fatherName)
+		_, err = h.service.AddDriver(r.Context(), firstName, lastName, fatherName, docs)
 		if err != nil {
-			http.Error(w, "Не удалось добавить водителя", http.StatusInternalServerError)
+			h.serviceError(w, r, "Не удалось добавить водителя", err)
 			return
 		}
 
@@ -143,21 +120,18 @@ func (h *AutoParkHandler) AddDriver(w http.ResponseWriter, r *http.Request) {
 			"./ui/template/layout.html", "./ui/template/drivers_table/add_driver.html",
 		)
 		if err != nil {
-			http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-			return
-		}
-		userName, err := h.getUserName(r)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+			h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 			return
 		}
 
 		tmpl.Execute(w, struct {
-			Title    string
-			Username string
+			Title      string
+			Categories []string
+			Username   string
 		}{
-			Title:    "Добавить водителя",
-			Username: userName,
+			Title:      "Добавить водителя",
+			Categories: models.LicenseCategories,
+			Username:   currentUser(r).Username,
 		})
 	}
 }
@@ -173,7 +147,7 @@ func (h *AutoParkHandler) EditDriverPage(w http.ResponseWriter, r *http.Request)
 
 	driver, err := h.service.GetDriverByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Не удалось загрузить данные водителя", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить данные водителя", err)
 		return
 	}
 
@@ -181,23 +155,20 @@ func (h *AutoParkHandler) EditDriverPage(w http.ResponseWriter, r *http.Request)
 		"./ui/template/layout.html", "./ui/template/drivers_table/edit_driver.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
 	tmpl.Execute(w, struct {
-		Title    string
-		Driver   models.AutoPersonal
-		Username string
+		Title      string
+		Driver     models.AutoPersonal
+		Categories []string
+		Username   string
 	}{
-		Title:    "Редактирование водителя",
-		Driver:   *driver,
-		Username: userName,
+		Title:      "Редактирование водителя",
+		Driver:     *driver,
+		Categories: models.LicenseCategories,
+		Username:   currentUser(r).Username,
 	})
 }
 
@@ -218,10 +189,15 @@ func (h *AutoParkHandler) UpdateDriver(w http.ResponseWriter, r *http.Request) {
 		firstName := r.FormValue("first_name")
 		lastName := r.FormValue("last_name")
 		fatherName := r.FormValue("father_name")
+		docs, err := formDriverDocuments(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-		err = h.service.UpdateDriver(r.Context(), driverID, firstName, lastName, fatherName)
+		err = h.service.UpdateDriver(r.Context(), driverID, firstName, lastName, fatherName, docs)
 		if err != nil {
-			http.Error(w, "Не удалось обновить данные водителя", http.StatusInternalServerError)
+			h.serviceError(w, r, "Не удалось обновить данные водителя", err)
 			return
 		}
 
@@ -229,37 +205,11 @@ func (h *AutoParkHandler) UpdateDriver(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Удаление водителя
-func (h *AutoParkHandler) DeleteDriver(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	driverID, err := strconv.Atoi(id)
-	if err != nil {
-		http.Error(w, "Invalid driver ID", http.StatusBadRequest)
-		return
-	}
-
-	err = h.service.DeleteDriver(r.Context(), driverID)
-	if err != nil {
-		http.Error(w, "Failed to delete driver: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusNoContent)
-}
-
 // Метод для получения списка автомобилей
 func (h *AutoParkHandler) GetCars(w http.ResponseWriter, r *http.Request) {
 	cars, err := h.service.GetCars(r.Context())
 	if err != nil {
-		http.Error(w, "Не удалось загрузить список автомобилей", http.StatusInternalServerError)
-		return
-	}
-
-	userRole, err := h.getUserRole(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Не удалось загрузить список автомобилей", err)
 		return
 	}
 
@@ -267,12 +217,7 @@ func (h *AutoParkHandler) GetCars(w http.ResponseWriter, r *http.Request) {
 		"./ui/template/layout.html", "./ui/template/autos_table/autos.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
@@ -284,11 +229,11 @@ func (h *AutoParkHandler) GetCars(w http.ResponseWriter, r *http.Request) {
 	}{
 		Title:    "Автомобили",
 		Autos:    cars,
-		UserRole: userRole,
-		Username: userName,
+		UserRole: currentUser(r).Role,
+		Username: currentUser(r).Username,
 	})
 	if err != nil {
-		http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -297,7 +242,7 @@ func (h *AutoParkHandler) GetCars(w http.ResponseWriter, r *http.Request) {
 func (h *AutoParkHandler) AddCarPage(w http.ResponseWriter, r *http.Request) {
 	drivers, err := h.service.GetDrivers(r.Context())
 	if err != nil {
-		http.Error(w, "Не удалось загрузить список водителей", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
 		return
 	}
 
@@ -305,26 +250,23 @@ func (h *AutoParkHandler) AddCarPage(w http.ResponseWriter, r *http.Request) {
 		"./ui/template/layout.html", "./ui/template/autos_table/add_auto.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
 	err = tmpl.Execute(w, struct {
-		Title    string
-		Drivers  []models.AutoPersonal
-		Username string
+		Title      string
+		Drivers    []models.AutoPersonal
+		Categories []string
+		Username   string
 	}{
-		Title:    "Добавить автомобиль",
-		Drivers:  drivers,
-		Username: userName,
+		Title:      "Добавить автомобиль",
+		Drivers:    drivers,
+		Categories: models.LicenseCategories,
+		Username:   currentUser(r).Username,
 	})
 	if err != nil {
-		http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -336,15 +278,16 @@ func (h *AutoParkHandler) AddCar(w http.ResponseWriter, r *http.Request) {
 		num := r.FormValue("num")
 		color := r.FormValue("color")
 		mark := r.FormValue("mark")
+		category := r.FormValue("category")
 		driverID, err := strconv.Atoi(r.FormValue("driver_id"))
 		if err != nil {
 			http.Error(w, "Некорректный driver_id", http.StatusBadRequest)
 			return
 		}
 
-		err = h.service.AddCar(r.Context(), num, color, mark, driverID)
+		_, err = h.service.AddCar(r.Context(), num, color, mark, category, driverID)
 		if err != nil {
-			http.Error(w, "Не удалось добавить автомобиль", http.StatusInternalServerError)
+			h.serviceError(w, r, "Не удалось добавить автомобиль", err)
 			return
 		}
 
@@ -363,21 +306,15 @@ func (h *AutoParkHandler) EditCarPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userRole, err := h.getUserRole(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-		return
-	}
-
 	car, driverName, err := h.service.GetCarByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Не удалось загрузить данные автомобиля", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить данные автомобиля", err)
 		return
 	}
 
 	drivers, err := h.service.GetDrivers(r.Context())
 	if err != nil {
-		http.Error(w, "Не удалось загрузить список водителей", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
 		return
 	}
 
@@ -385,12 +322,7 @@ func (h *AutoParkHandler) EditCarPage(w http.ResponseWriter, r *http.Request) {
 		"./ui/template/layout.html", "./ui/template/autos_table/edit_auto.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
@@ -399,6 +331,7 @@ func (h *AutoParkHandler) EditCarPage(w http.ResponseWriter, r *http.Request) {
 		Car        *models.Auto
 		DriverName string
 		Drivers    []models.AutoPersonal
+		Categories []string
 		UserRole   string
 		Username   string
 	}{
@@ -406,11 +339,12 @@ func (h *AutoParkHandler) EditCarPage(w http.ResponseWriter, r *http.Request) {
 		Car:        car,
 		DriverName: driverName,
 		Drivers:    drivers,
-		UserRole:   userRole,
-		Username:   userName,
+		Categories: models.LicenseCategories,
+		UserRole:   currentUser(r).Role,
+		Username:   currentUser(r).Username,
 	})
 	if err != nil {
-		http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -432,6 +366,7 @@ func (h *AutoParkHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 		num := r.FormValue("num")
 		color := r.FormValue("color")
 		mark := r.FormValue("mark")
+		category := r.FormValue("category")
 		personalID, err := strconv.Atoi(r.FormValue("personal_id"))
 		if err != nil {
 			http.Error(w, "Некорректный personal_id", http.StatusBadRequest)
@@ -443,9 +378,9 @@ func (h *AutoParkHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		err = h.service.UpdateCar(r.Context(), carID, num, color, mark, personalID)
+		err = h.service.UpdateCar(r.Context(), carID, num, color, mark, category, personalID)
 		if err != nil {
-			http.Error(w, "Не удалось обновить данные автомобиля", http.StatusInternalServerError)
+			h.serviceError(w, r, "Не удалось обновить данные автомобиля", err)
 			return
 		}
 
@@ -453,37 +388,11 @@ func (h *AutoParkHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Удаление автомобиля
-func (h *AutoParkHandler) DeleteCar(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	carID, err := strconv.Atoi(id)
-	if err != nil {
-		http.Error(w, "Некорректный ID автомобиля", http.StatusBadRequest)
-		return
-	}
-
-	err = h.service.DeleteCar(r.Context(), carID)
-	if err != nil {
-		http.Error(w, "Не удалось удалить автомобиль: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusNoContent)
-}
-
 // Метод для получения списка маршрутов
 func (h *AutoParkHandler) GetRoutes(w http.ResponseWriter, r *http.Request) {
 	routes, err := h.service.GetRoutes(r.Context())
 	if err != nil {
-		http.Error(w, "Не удалось загрузить список маршрутов", http.StatusInternalServerError)
-		return
-	}
-
-	userRole, err := h.getUserRole(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Не удалось загрузить список маршрутов", err)
 		return
 	}
 
@@ -491,12 +400,7 @@ func (h *AutoParkHandler) GetRoutes(w http.ResponseWriter, r *http.Request) {
 		"./ui/template/layout.html", "./ui/template/routes_table/routes.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
@@ -508,12 +412,12 @@ func (h *AutoParkHandler) GetRoutes(w http.ResponseWriter, r *http.Request) {
 	}{
 		Title:    "Маршруты",
 		Routes:   routes,
-		UserRole: userRole,
-		Username: userName,
+		UserRole: currentUser(r).Role,
+		Username: currentUser(r).Username,
 	})
 
 	if err != nil {
-		http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -524,12 +428,7 @@ func (h *AutoParkHandler) AddRoutePage(w http.ResponseWriter, r *http.Request) {
 		"./ui/template/layout.html", "./ui/template/routes_table/add_route.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
@@ -538,7 +437,7 @@ func (h *AutoParkHandler) AddRoutePage(w http.ResponseWriter, r *http.Request) {
 		Username string
 	}{
 		Title:    "Добавление маршрута",
-		Username: userName,
+		Username: currentUser(r).Username,
 	})
 }
 
@@ -548,9 +447,9 @@ func (h *AutoParkHandler) AddRoute(w http.ResponseWriter, r *http.Request) {
 		startPoint := r.FormValue("start_point")
 		endPoint := r.FormValue("end_point")
 
-		err := h.service.AddRoute(r.Context(), startPoint, endPoint)
+		_, err := h.service.AddRoute(r.Context(), models.Route{StartPoint: startPoint, EndPoint: endPoint})
 		if err != nil {
-			http.Error(w, "Не удалось добавить маршрут", http.StatusInternalServerError)
+			h.serviceError(w, r, "Не удалось добавить маршрут", err)
 			return
 		}
 
@@ -569,7 +468,7 @@ func (h *AutoParkHandler) EditRoutePage(w http.ResponseWriter, r *http.Request)
 
 	route, err := h.service.GetRouteByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Не удалось загрузить данные маршрута", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить данные маршрута", err)
 		return
 	}
 
@@ -577,12 +476,7 @@ func (h *AutoParkHandler) EditRoutePage(w http.ResponseWriter, r *http.Request)
 		"./ui/template/layout.html", "./ui/template/routes_table/edit_route.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
@@ -593,7 +487,7 @@ func (h *AutoParkHandler) EditRoutePage(w http.ResponseWriter, r *http.Request)
 	}{
 		Title:    "Редактирование маршрута",
 		Route:    *route,
-		Username: userName,
+		Username: currentUser(r).Username,
 	})
 }
 
@@ -611,18 +505,20 @@ func (h *AutoParkHandler) UpdateRoute(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		startPoint := r.FormValue("start_point")
-		endPoint := r.FormValue("end_point")
-
-		route := &models.Route{
-			ID:         routeID,
-			StartPoint: startPoint,
-			EndPoint:   endPoint,
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+			return
 		}
+		route, err := parseRouteForm(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		route.ID = routeID
 
-		err = h.service.UpdateRoute(r.Context(), route)
+		err = h.service.UpdateRoute(r.Context(), &route)
 		if err != nil {
-			http.Error(w, "Не удалось обновить данные маршрута", http.StatusInternalServerError)
+			h.serviceError(w, r, "Не удалось обновить данные маршрута", err)
 			return
 		}
 
@@ -630,111 +526,115 @@ func (h *AutoParkHandler) UpdateRoute(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Удаление маршрута
-func (h *AutoParkHandler) DeleteRoute(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	routeID, err := strconv.Atoi(id)
+// Обработчик для скачивания журнала в Excel, CSV или PDF с учетом фильтров
+func (h *AutoParkHandler) DownloadJournal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := parseJournalFilter(r)
 	if err != nil {
-		http.Error(w, "Invalid route ID", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = h.service.DeleteRoute(r.Context(), routeID)
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
 	if err != nil {
-		http.Error(w, "Failed to delete route: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
-}
-
-// Обработчик для скачивания Excel-файла журнала
-func (h *AutoParkHandler) DownloadJournal(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	entries, err := h.service.GetAllJournalEntries(ctx)
+	entries, err := h.service.GetAllJournalEntries(ctx, filter)
 	if err != nil {
-		http.Error(w, "Ошибка при получении записей журнала", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка при получении записей журнала", err)
 		return
 	}
 
-	f := excelize.NewFile()
-	sheetName := "Журнал"
-	f.NewSheet(sheetName)
-
-	f.SetCellValue(sheetName, "A1", "Маршрут")
-	f.SetCellValue(sheetName, "B1", "Автомобиль")
-	f.SetCellValue(sheetName, "C1", "Водитель")
-	f.SetCellValue(sheetName, "D1", "Время отправления")
-	f.SetCellValue(sheetName, "E1", "Время прибытия")
-
-	for i, entry := range entries {
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", i+2), fmt.Sprintf("%s - %s", entry.StartPoint, entry.EndPoint))
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", i+2), fmt.Sprintf("%s (%s)", entry.AutoNumber, entry.AutoMark))
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", i+2), entry.DriverName)
+	var buf bytes.Buffer
+	if err := h.exporter.Write(&buf, format, entries); err != nil {
+		h.serviceError(w, r, "Ошибка при формировании файла выгрузки", err)
+		return
+	}
 
-		timeOutStr := entry.TimeOut.Format("02.01.2006 15:04")
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", i+2), timeOutStr)
+	w.Header().Set("Content-Disposition", "attachment; filename="+format.FileName())
+	w.Header().Set("Content-Type", format.ContentType())
+	buf.WriteTo(w)
+}
 
-		if entry.TimeIn != nil {
-			timeInStr := entry.TimeIn.Format("02.01.2006 15:04")
-			f.SetCellValue(sheetName, fmt.Sprintf("E%d", i+2), timeInStr)
-		} else {
-			f.SetCellValue(sheetName, fmt.Sprintf("E%d", i+2), "В пути")
-		}
+// Получение всех записей журнала
+func (h *AutoParkHandler) GetAllJournalEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	w.Header().Set("Content-Disposition", "attachment; filename=journal.xlsx")
-	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-
-	if err := f.Write(w); err != nil {
-		http.Error(w, "Ошибка при создании файла Excel", http.StatusInternalServerError)
+	page, err := h.service.GetJournalEntries(ctx, filter)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить записи журнала", err)
 		return
 	}
-}
 
-// Получение всех записей журнала
-func (h *AutoParkHandler) GetAllJournalEntries(w http.ResponseWriter, r *http.Request) {
-	entries, err := h.service.GetAllJournalEntries(r.Context())
+	drivers, err := h.service.GetDrivers(ctx)
 	if err != nil {
-		http.Error(w, "Не удалось загрузить записи журнала", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
 		return
 	}
 
-	userRole, err := h.getUserRole(r)
+	routes, err := h.service.GetRoutes(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Не удалось загрузить список маршрутов", err)
 		return
 	}
 
+	query := r.URL.Query()
+	totalPages := (page.Total + page.PageSize - 1) / page.PageSize
+	var prevQuery, nextQuery template.URL
+	if page.Page > 1 {
+		prevQuery = pageQuery(query, page.Page-1)
+	}
+	if page.Page < totalPages {
+		nextQuery = pageQuery(query, page.Page+1)
+	}
+
 	tmpl, err := template.ParseFiles(
 		"./ui/template/layout.html", "./ui/template/journal_table/journal.html",
 	)
 	if err != nil {
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
 	err = tmpl.Execute(w, struct {
-		Title    string
-		Entries  []models.JournalView
-		UserRole string
-		Username string
+		Title       string
+		Entries     []models.JournalView
+		Drivers     []models.AutoPersonal
+		Routes      []models.Route
+		Query       url.Values
+		Total       int
+		Page        int
+		TotalPages  int
+		PrevQuery   template.URL
+		NextQuery   template.URL
+		FilterQuery template.URL
+		UserRole    string
+		Username    string
 	}{
-		Title:    "Записи журнала",
-		Entries:  entries,
-		UserRole: userRole,
-		Username: userName,
+		Title:       "Записи журнала",
+		Entries:     page.Entries,
+		Drivers:     drivers,
+		Routes:      routes,
+		Query:       query,
+		Total:       page.Total,
+		Page:        page.Page,
+		TotalPages:  totalPages,
+		PrevQuery:   prevQuery,
+		NextQuery:   nextQuery,
+		FilterQuery: journalFilterQuery(query),
+		UserRole:    currentUser(r).Role,
+		Username:    currentUser(r).Username,
 	})
 
 	if err != nil {
-		http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -755,7 +655,7 @@ func (h *AutoParkHandler) GetAutosByDriver(w http.ResponseWriter, r *http.Reques
 
 	autos, err := h.service.GetAutosByDriverID(r.Context(), driverIDInt)
 	if err != nil {
-		http.Error(w, "Не удалось загрузить автомобили", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить автомобили", err)
 		return
 	}
 
@@ -768,15 +668,13 @@ func (h *AutoParkHandler) AddJournalEntryPage(w http.ResponseWriter, r *http.Req
 
 	drivers, err := h.service.GetDrivers(ctx)
 	if err != nil {
-		log.Printf("Ошибка получения водителей: %v", err)
-		http.Error(w, "Не удалось загрузить список водителей", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
 		return
 	}
 
 	routes, err := h.service.GetRoutes(ctx)
 	if err != nil {
-		log.Printf("Ошибка получения маршрутов: %v", err)
-		http.Error(w, "Не удалось загрузить список маршрутов", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить список маршрутов", err)
 		return
 	}
 
@@ -784,23 +682,33 @@ func (h *AutoParkHandler) AddJournalEntryPage(w http.ResponseWriter, r *http.Req
 	for _, driver := range drivers {
 		driverAutos, err := h.service.GetAutosByDriverID(ctx, driver.ID)
 		if err != nil {
-			log.Printf("Ошибка получения авто для водителя %d: %v", driver.ID, err)
+			h.logger.ErrorContext(ctx, "Ошибка получения автомобилей водителя", "driver_id", driver.ID, "error", err)
 			continue
 		}
 		driversAutos[driver.ID] = driverAutos
 	}
 
+	// Предупреждения о просроченном и предстоящем обслуживании автомобилей
+	var serviceWarnings []models.ServiceStatus
+	statuses, err := h.service.GetServiceStatuses(ctx, time.Now())
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения сроков обслуживания", "error", err)
+	}
+	for _, status := range statuses {
+		if status.Status != models.ServiceStatusOK {
+			serviceWarnings = append(serviceWarnings, status)
+		}
+	}
+
 	layoutBytes, err := os.ReadFile("./ui/template/layout.html")
 	if err != nil {
-		log.Printf("Ошибка чтения layout: %v", err)
-		http.Error(w, "Ошибка чтения шаблона", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка чтения шаблона", err)
 		return
 	}
 
 	addJournalBytes, err := os.ReadFile("./ui/template/journal_table/add_journal.html")
 	if err != nil {
-		log.Printf("Ошибка чтения add_journal: %v", err)
-		http.Error(w, "Ошибка чтения шаблона", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка чтения шаблона", err)
 		return
 	}
 
@@ -815,41 +723,35 @@ func (h *AutoParkHandler) AddJournalEntryPage(w http.ResponseWriter, r *http.Req
 
 	tmpl, err = tmpl.Parse(string(layoutBytes))
 	if err != nil {
-		log.Printf("Ошибка парсинга layout: %v", err)
-		http.Error(w, "Ошибка парсинга шаблона layout", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка парсинга шаблона layout", err)
 		return
 	}
 
 	tmpl, err = tmpl.Parse(string(addJournalBytes))
 	if err != nil {
-		log.Printf("Ошибка парсинга add_journal: %v", err)
-		http.Error(w, "Ошибка парсинга шаблона add_journal", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка парсинга шаблона add_journal", err)
 		return
 	}
 
 	data := struct {
-		Title        string
-		Drivers      []models.AutoPersonal
-		DriversAutos map[int][]models.Auto
-		Routes       []models.Route
-		Username     string
+		Title           string
+		Drivers         []models.AutoPersonal
+		DriversAutos    map[int][]models.Auto
+		Routes          []models.Route
+		ServiceWarnings []models.ServiceStatus
+		Username        string
 	}{
-		Title:        "Добавление записи в журнал",
-		Drivers:      drivers,
-		DriversAutos: driversAutos,
-		Routes:       routes,
-		Username:     userName,
+		Title:           "Добавление записи в журнал",
+		Drivers:         drivers,
+		DriversAutos:    driversAutos,
+		Routes:          routes,
+		ServiceWarnings: serviceWarnings,
+		Username:        currentUser(r).Username,
 	}
 
 	err = tmpl.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		log.Printf("Ошибка выполнения шаблона: %v", err)
-		http.Error(w, "Ошибка отображения страницы: "+err.Error(), http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -870,10 +772,20 @@ func (h *AutoParkHandler) AddJournalEntry(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err := h.service.AddJournalEntry(r.Context(), autoID, routeID, timeOut)
+	var departure models.TripDeparture
+	var err error
+	if departure.Odometer, err = parseOptionalFormInt(r.Form.Get("odometer_out"), "пробега"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if departure.Fuel, err = parseOptionalFormFloat(r.Form.Get("fuel_out"), "остатка топлива"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.service.AddJournalEntry(r.Context(), autoID, driverID, routeID, timeOut, departure)
 	if err != nil {
-		log.Printf("Ошибка добавления записи в журнал: %v", err)
-		http.Error(w, "Не удалось добавить запись", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось добавить запись", err)
 		return
 	}
 
@@ -892,22 +804,19 @@ func (h *AutoParkHandler) EditJournalEntryPage(w http.ResponseWriter, r *http.Re
 
 	entry, err := h.service.GetJournalEntryByID(ctx, id)
 	if err != nil {
-		log.Printf("Ошибка получения записи журнала: %v", err)
-		http.Error(w, "Не удалось загрузить данные записи журнала", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить данные записи журнала", err)
 		return
 	}
 
 	drivers, err := h.service.GetDrivers(ctx)
 	if err != nil {
-		log.Printf("Ошибка получения водителей: %v", err)
-		http.Error(w, "Не удалось загрузить список водителей", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
 		return
 	}
 
 	routes, err := h.service.GetRoutes(ctx)
 	if err != nil {
-		log.Printf("Ошибка получения маршрутов: %v", err)
-		http.Error(w, "Не удалось загрузить список маршрутов", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось загрузить список маршрутов", err)
 		return
 	}
 
@@ -915,7 +824,7 @@ func (h *AutoParkHandler) EditJournalEntryPage(w http.ResponseWriter, r *http.Re
 	for _, driver := range drivers {
 		driverAutos, err := h.service.GetAutosByDriverID(ctx, driver.ID)
 		if err != nil {
-			log.Printf("Ошибка получения авто для водителя %d: %v", driver.ID, err)
+			h.logger.ErrorContext(ctx, "Ошибка получения автомобилей водителя", "driver_id", driver.ID, "error", err)
 			continue
 		}
 		driversAutos[driver.ID] = driverAutos
@@ -923,15 +832,13 @@ func (h *AutoParkHandler) EditJournalEntryPage(w http.ResponseWriter, r *http.Re
 
 	layoutBytes, err := os.ReadFile("./ui/template/layout.html")
 	if err != nil {
-		log.Printf("Ошибка чтения layout: %v", err)
-		http.Error(w, "Ошибка чтения шаблона", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка чтения шаблона", err)
 		return
 	}
 
 	editJournalBytes, err := os.ReadFile("./ui/template/journal_table/edit_journal.html")
 	if err != nil {
-		log.Printf("Ошибка чтения edit_journal: %v", err)
-		http.Error(w, "Ошибка чтения шаблона", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка чтения шаблона", err)
 		return
 	}
 
@@ -946,20 +853,13 @@ func (h *AutoParkHandler) EditJournalEntryPage(w http.ResponseWriter, r *http.Re
 
 	tmpl, err = tmpl.Parse(string(layoutBytes))
 	if err != nil {
-		log.Printf("Ошибка парсинга layout: %v", err)
-		http.Error(w, "Ошибка парсинга шаблона layout", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка парсинга шаблона layout", err)
 		return
 	}
 
 	tmpl, err = tmpl.Parse(string(editJournalBytes))
 	if err != nil {
-		log.Printf("Ошибка парсинга edit_journal: %v", err)
-		http.Error(w, "Ошибка парсинга шаблона edit_journal", http.StatusInternalServerError)
-		return
-	}
-	userName, err := h.getUserName(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Ошибка парсинга шаблона edit_journal", err)
 		return
 	}
 
@@ -978,13 +878,12 @@ func (h *AutoParkHandler) EditJournalEntryPage(w http.ResponseWriter, r *http.Re
 		Drivers:      drivers,
 		DriversAutos: driversAutos,
 		Routes:       routes,
-		Username:     userName,
+		Username:     currentUser(r).Username,
 	}
 
 	err = tmpl.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		log.Printf("Ошибка выполнения шаблона: %v", err)
-		http.Error(w, "Ошибка отображения страницы: "+err.Error(), http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
@@ -1008,10 +907,23 @@ func (h *AutoParkHandler) UpdateJournalEntry(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = h.service.CompleteJournalEntry(r.Context(), id, timeIn)
+	var arrival models.TripReturn
+	if arrival.Odometer, err = parseOptionalFormInt(r.Form.Get("odometer_in"), "пробега"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if arrival.Fuel, err = parseOptionalFormFloat(r.Form.Get("fuel_in"), "остатка топлива"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if arrival.FuelAdded, err = parseOptionalFormFloat(r.Form.Get("fuel_added"), "заправки"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.CompleteJournalEntry(r.Context(), id, timeIn, arrival)
 	if err != nil {
-		log.Printf("Ошибка обновления записи журнала: %v", err)
-		http.Error(w, "Не удалось обновить запись", http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось обновить запись", err)
 		return
 	}
 
@@ -1029,7 +941,10 @@ func (h *AutoParkHandler) CompleteJournalEntry(w http.ResponseWriter, r *http.Re
 	}
 
 	var requestBody struct {
-		TimeIn string `json:"timeIn"`
+		TimeIn    string   `json:"timeIn"`
+		Odometer  *int     `json:"odometerIn"`
+		Fuel      *float64 `json:"fuelIn"`
+		FuelAdded *float64 `json:"fuelAdded"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
@@ -1037,8 +952,13 @@ func (h *AutoParkHandler) CompleteJournalEntry(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if err := h.service.CompleteJournalEntry(r.Context(), journalID, requestBody.TimeIn); err != nil {
-		http.Error(w, "Не удалось завершить рейс: "+err.Error(), http.StatusInternalServerError)
+	arrival := models.TripReturn{
+		Odometer:  requestBody.Odometer,
+		Fuel:      requestBody.Fuel,
+		FuelAdded: requestBody.FuelAdded,
+	}
+	if err := h.service.CompleteJournalEntry(r.Context(), journalID, requestBody.TimeIn, arrival); err != nil {
+		h.serviceError(w, r, "Не удалось завершить рейс", err)
 		return
 	}
 
@@ -1060,7 +980,7 @@ func (h *AutoParkHandler) DeleteJournalEntry(w http.ResponseWriter, r *http.Requ
 
 	err = h.service.DeleteJournalEntry(r.Context(), journalID)
 	if err != nil {
-		http.Error(w, "Не удалось удалить запись журнала: "+err.Error(), http.StatusInternalServerError)
+		h.serviceError(w, r, "Не удалось удалить запись журнала", err)
 		return
 	}
 
@@ -1071,42 +991,59 @@ func (h *AutoParkHandler) DeleteJournalEntry(w http.ResponseWriter, r *http.Requ
 func (h *AutoParkHandler) StatisticsPage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	routesVehicleCount, err := h.service.GetRoutesVehicleCount(ctx)
+	filter, err := parseJournalFilter(r)
 	if err != nil {
-		log.Printf("Error getting routes vehicle count: %v", err)
-		http.Error(w, "Не удалось получить статистику", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	userName, err := h.getUserName(r)
+	query := r.URL.Query()
+	durations, err := h.service.GetRouteDurationReport(ctx, filter, query.Get("interval"))
+	if err != nil {
+		h.serviceError(w, r, "Не удалось построить отчет", err)
+		return
+	}
+
+	routesVehicleCount, err := h.service.GetRoutesVehicleCount(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		h.serviceError(w, r, "Не удалось получить статистику", err)
 		return
 	}
 
+	user := currentUser(r)
 	data := struct {
 		Title              string
 		RoutesVehicleCount []models.RouteVehicleCount
+		Durations          *models.RouteDurationReport
+		Trend              routeTrendChart
+		LateTolerance      int
+		From               string
+		To                 string
+		UserRole           string
 		Username           string
 	}{
 		Title:              "Статистика маршрутов",
 		RoutesVehicleCount: routesVehicleCount,
-		Username:           userName,
+		Durations:          durations,
+		Trend:              newRouteTrendChart(durations.Trend),
+		LateTolerance:      services.LateToleranceMinutes,
+		From:               query.Get("from"),
+		To:                 query.Get("to"),
+		UserRole:           user.Role,
+		Username:           user.Username,
 	}
 
 	tmpl, err := template.ParseFiles(
 		"./ui/template/layout.html", "./ui/template/statistics.html",
 	)
 	if err != nil {
-		log.Printf("Error parsing template: %v", err)
-		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
 		return
 	}
 
 	w.Header().Del("Content-Type")
 
 	if err := tmpl.Execute(w, data); err != nil {
-		log.Printf("Error executing template: %v", err)
-		http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
 		return
 	}
 }
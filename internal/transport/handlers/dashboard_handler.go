@@ -3,33 +3,42 @@ package handlers
 import (
 	"html/template"
 	"net/http"
-)
-
-// Отображение главной рабочей страницы
-func DashboardPage(w http.ResponseWriter, r *http.Request) {
-	session, _ := store.Get(r, "session-name")
-
-	userID, ok := session.Values["user_id"].(int)
-	username, _ := session.Values["username"].(string)
+	"time"
 
-	if !ok || userID == 0 {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
-		return
-	}
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+)
 
+// Отображение главной рабочей страницы с предупреждениями об истекающих документах
+func (h *AutoParkHandler) DashboardPage(w http.ResponseWriter, r *http.Request) {
 	tmpl, err := template.ParseFiles(
 		"./ui/template/layout.html", "./ui/template/dashboard.html",
 	)
 	if err != nil {
-		http.Error(w, "Error loading page", http.StatusInternalServerError)
+		h.serviceError(w, r, "Error loading page", err)
 		return
 	}
 
+	// Главная страница открывается и без виджета, если документы не загрузились
+	documents, err := h.service.GetExpiringDocuments(r.Context(), time.Now())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Ошибка получения истекающих документов", "error", err)
+	}
+
+	user := currentUser(r)
 	tmpl.Execute(w, struct {
-		Title    string
-		Username string
+		Title              string
+		ExpiringDocuments  []models.ExpiringDocument
+		DocumentTitles     map[string]string
+		ExpiringWithinDays int
+		UserRole           string
+		Username           string
 	}{
-		Title:    "Главная",
-		Username: username,
+		Title:              "Главная",
+		ExpiringDocuments:  documents,
+		DocumentTitles:     documentTitles,
+		ExpiringWithinDays: services.ExpiringDocumentsDays,
+		UserRole:           user.Role,
+		Username:           user.Username,
 	})
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Названия документов для страниц
+var documentTitles = map[string]string{
+	models.DocumentLicense: "Водительское удостоверение",
+	models.DocumentMedical: "Медицинская справка",
+}
+
+// Необязательная дата: пустая строка — nil, время суток отбрасывается
+func parseOptionalDate(value, name string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := parseFilterDate(value)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная дата %s: %s", name, value)
+	}
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return &date, nil
+}
+
+// Документы водителя из полей формы или запроса API
+func parseDriverDocuments(phone, licenseNumber string, categories []string, issuedAt, expiresAt, medicalExpiresAt string) (models.DriverDocuments, error) {
+	docs := models.DriverDocuments{
+		Phone:             phone,
+		LicenseNumber:     licenseNumber,
+		LicenseCategories: categories,
+	}
+	var err error
+	if docs.LicenseIssuedAt, err = parseOptionalDate(issuedAt, "выдачи удостоверения"); err != nil {
+		return docs, err
+	}
+	if docs.LicenseExpiresAt, err = parseOptionalDate(expiresAt, "окончания действия удостоверения"); err != nil {
+		return docs, err
+	}
+	if docs.MedicalExpiresAt, err = parseOptionalDate(medicalExpiresAt, "окончания действия медицинской справки"); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+func formDriverDocuments(r *http.Request) (models.DriverDocuments, error) {
+	return parseDriverDocuments(
+		r.FormValue("phone"), r.FormValue("license_number"), r.Form["license_categories"],
+		r.FormValue("license_issued_at"), r.FormValue("license_expires_at"), r.FormValue("medical_expires_at"),
+	)
+}
+
+// Истекшие и истекающие документы водителей
+func (h *APIHandler) ExpiringDocuments(w http.ResponseWriter, r *http.Request) {
+	documents, err := h.service.GetExpiringDocuments(r.Context(), time.Now())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, documents)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"AutoParkWeb/internal/export"
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// Рейтинг водителей за период
+func (h *AutoParkHandler) DriverReportPage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	report, err := h.service.GetDriverReport(r.Context(), filter, query.Get("rank_by"))
+	if err != nil {
+		h.serviceError(w, r, "Не удалось построить отчет", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/driver_report.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title         string
+		Report        *models.DriverReport
+		LateTolerance int
+		From          string
+		To            string
+		UserRole      string
+		Username      string
+	}{
+		Title:         "Отчет по водителям",
+		Report:        report,
+		LateTolerance: services.LateToleranceMinutes,
+		From:          query.Get("from"),
+		To:            query.Get("to"),
+		UserRole:      user.Role,
+		Username:      user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
+
+// Показатели и рейсы одного водителя за период
+func (h *AutoParkHandler) DriverReportDetailPage(w http.ResponseWriter, r *http.Request) {
+	driverID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID водителя", http.StatusBadRequest)
+		return
+	}
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	detail, err := h.service.GetDriverReportDetail(r.Context(), driverID, filter)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось построить отчет", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/driver_report_detail.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	query := r.URL.Query()
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title    string
+		Detail   *models.DriverReportDetail
+		From     string
+		To       string
+		UserRole string
+		Username string
+	}{
+		Title:    "Отчет по водителю " + detail.Driver.DriverName,
+		Detail:   detail,
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		UserRole: user.Role,
+		Username: user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
+
+// Выгрузка отчета по водителям в Excel; с driver_id — показатели и рейсы одного водителя
+func (h *AutoParkHandler) DownloadDriverReport(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var drivers []models.DriverPerformance
+	var trips []models.DriverTrip
+	fileName := "drivers.xlsx"
+	if filter.DriverID != 0 {
+		detail, err := h.service.GetDriverReportDetail(r.Context(), filter.DriverID, filter)
+		if err != nil {
+			h.serviceError(w, r, "Не удалось построить отчет", err)
+			return
+		}
+		drivers, trips = []models.DriverPerformance{detail.Driver}, detail.Trips
+		fileName = "driver_" + strconv.Itoa(filter.DriverID) + ".xlsx"
+	} else {
+		report, err := h.service.GetDriverReport(r.Context(), filter, r.URL.Query().Get("rank_by"))
+		if err != nil {
+			h.serviceError(w, r, "Не удалось построить отчет", err)
+			return
+		}
+		drivers = report.Drivers
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteDriverReportXLSX(&buf, drivers, trips); err != nil {
+		h.serviceError(w, r, "Ошибка при формировании файла выгрузки", err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileName)
+	w.Header().Set("Content-Type", export.FormatXLSX.ContentType())
+	buf.WriteTo(w)
+}
+
+// Рейтинг водителей; фильтры те же, что у журнала, rank_by — поле ранжирования
+func (h *APIHandler) DriverReport(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	report, err := h.service.GetDriverReport(r.Context(), filter, r.URL.Query().Get("rank_by"))
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, report)
+}
+
+func (h *APIHandler) DriverReportDetail(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	detail, err := h.service.GetDriverReportDetail(r.Context(), id, filter)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, detail)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"AutoParkWeb/internal/services"
+)
+
+// Сколько ждать ответа базы данных при проверке готовности
+const readinessTimeout = 2 * time.Second
+
+// Liveness: процесс жив и обрабатывает запросы, зависимости не проверяются
+func Healthz(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, logger, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// Readiness: хранилище отвечает на ping, иначе 503, чтобы оркестратор
+// не направлял трафик на экземпляр без базы данных
+func Readyz(service *services.AutoParkService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		if err := service.Ready(ctx); err != nil {
+			logger.WarnContext(r.Context(), "Readiness check failed", "error", err)
+			writeJSON(w, r, logger, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "error": err.Error()})
+			return
+		}
+		writeJSON(w, r, logger, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
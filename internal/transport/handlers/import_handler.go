@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+
+	"AutoParkWeb/internal/importer"
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+)
+
+const maxImportFileSize = 10 << 20
+
+// Предел тела запроса: при подтверждении файл приходит в base64 (+1/3),
+// плюс 1 МБ на остальные поля формы
+const maxImportRequestSize = maxImportFileSize*4/3 + 1<<20
+
+// Файл импорта из поля file формы или, при подтверждении импорта после
+// предварительной проверки, из скрытых полей file_name и file_data (base64)
+func readImportUpload(w http.ResponseWriter, r *http.Request) (string, []byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportRequestSize)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", nil, errors.New("размер файла превышает 10 МБ")
+		}
+		return "", nil, errors.New("не удалось прочитать форму")
+	}
+
+	if encoded := r.FormValue("file_data"); encoded != "" {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", nil, errors.New("поврежденные данные файла")
+		}
+		return r.FormValue("file_name"), data, nil
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", nil, errors.New("файл не выбран")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize+1))
+	if err != nil {
+		return "", nil, errors.New("не удалось прочитать файл")
+	}
+	if len(data) > maxImportFileSize {
+		return "", nil, errors.New("размер файла превышает 10 МБ")
+	}
+	return header.Filename, data, nil
+}
+
+// Разбор файла и проверка или импорт его строк
+func importFile(r *http.Request, service *services.AutoParkService, fileName string, data []byte, dryRun bool) (*models.ImportResult, error) {
+	kind, err := importer.ParseKind(r.FormValue("kind"))
+	if err != nil {
+		return nil, err
+	}
+	batch, err := importer.Parse(fileName, data, kind)
+	if err != nil {
+		return nil, err
+	}
+	return service.ImportData(r.Context(), batch, dryRun)
+}
+
+// Страница импорта справочников из файла
+func (h *AutoParkHandler) ImportPage(w http.ResponseWriter, r *http.Request) {
+	h.renderImportPage(w, r, importPageData{})
+}
+
+// Предварительная проверка (action=preview) или импорт (action=import) файла
+func (h *AutoParkHandler) Import(w http.ResponseWriter, r *http.Request) {
+	fileName, data, err := readImportUpload(w, r)
+	if err != nil {
+		h.renderImportPage(w, r, importPageData{FormError: err.Error()})
+		return
+	}
+
+	page := importPageData{Kind: r.FormValue("kind")}
+	page.Result, err = importFile(r, h.service, fileName, data, r.FormValue("action") != "import")
+	if err != nil {
+		var fileErr *importer.FileError
+		var validationErr *services.ValidationError
+		switch {
+		case errors.As(err, &fileErr):
+			page.FormError = fileErr.Message
+		case errors.As(err, &validationErr):
+			page.FormError = validationErr.Message
+		default:
+			h.logger.ErrorContext(r.Context(), "Ошибка импорта", "file", fileName, "error", err)
+			page.FormError = "Не удалось выполнить импорт: " + err.Error()
+		}
+		h.renderImportPage(w, r, page)
+		return
+	}
+
+	if !page.Result.Committed && page.Result.ErrorCount == 0 {
+		// Файл возвращается в форму, чтобы подтвердить импорт без повторной загрузки
+		page.FileName = fileName
+		page.FileData = base64.StdEncoding.EncodeToString(data)
+	}
+	h.renderImportPage(w, r, page)
+}
+
+type importPageData struct {
+	Result    *models.ImportResult
+	FormError string
+	Kind      string
+	FileName  string
+	FileData  string
+}
+
+func (h *AutoParkHandler) renderImportPage(w http.ResponseWriter, r *http.Request, data importPageData) {
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/import.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		importPageData
+		Title    string
+		UserRole string
+		Username string
+	}{
+		importPageData: data,
+		Title:          "Импорт справочников",
+		UserRole:       user.Role,
+		Username:       user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
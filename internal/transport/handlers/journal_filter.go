@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Разбор параметров фильтрации журнала из строки запроса.
+// Общий для HTML-страницы, выгрузки и JSON API.
+func parseJournalFilter(r *http.Request) (models.JournalFilter, error) {
+	query := r.URL.Query()
+	var filter models.JournalFilter
+
+	if value := query.Get("from"); value != "" {
+		from, err := parseFilterDate(value)
+		if err != nil {
+			return filter, fmt.Errorf("некорректная дата from: %s", value)
+		}
+		filter.TimeOutFrom = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := parseFilterDate(value)
+		if err != nil {
+			return filter, fmt.Errorf("некорректная дата to: %s", value)
+		}
+		// Дата окончания включается в интервал целиком
+		if len(value) == len("2006-01-02") {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.TimeOutTo = &to
+	}
+
+	var err error
+	if filter.DriverID, err = parseOptionalInt(query, "driver_id"); err != nil {
+		return filter, err
+	}
+	if filter.RouteID, err = parseOptionalInt(query, "route_id"); err != nil {
+		return filter, err
+	}
+	if filter.Page, err = parseOptionalInt(query, "page"); err != nil {
+		return filter, err
+	}
+	if filter.PageSize, err = parseOptionalInt(query, "page_size"); err != nil {
+		return filter, err
+	}
+
+	filter.AutoNumber = query.Get("auto_number")
+	filter.Status = query.Get("status")
+	filter.SortBy = query.Get("sort")
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, fmt.Errorf("некорректное направление сортировки: %s", query.Get("order"))
+	}
+
+	return filter, nil
+}
+
+// Дата из <input type="date"> или момент времени в формате RFC 3339
+func parseFilterDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02T15:04", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseOptionalInt(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("некорректное значение %s: %s", name, value)
+	}
+	return n, nil
+}
+
+// Строка запроса с теми же фильтрами, но другой страницей.
+// Тип template.URL нужен, чтобы шаблон не экранировал «=» и «&».
+func pageQuery(query url.Values, page int) template.URL {
+	values := cloneValues(query)
+	values.Set("page", strconv.Itoa(page))
+	return template.URL(values.Encode())
+}
+
+// Строка запроса с фильтрами без параметров постраничного вывода и формата выгрузки
+func journalFilterQuery(query url.Values) template.URL {
+	values := cloneValues(query)
+	values.Del("page")
+	values.Del("page_size")
+	values.Del("format")
+	return template.URL(values.Encode())
+}
+
+func cloneValues(query url.Values) url.Values {
+	values := make(url.Values, len(query))
+	for key, value := range query {
+		if len(value) > 0 && value[0] != "" {
+			values[key] = append([]string(nil), value...)
+		}
+	}
+	return values
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"AutoParkWeb/internal/models"
+)
+
+func TestParseJournalFilter(t *testing.T) {
+	date := func(value string) *time.Time {
+		parsed, err := time.Parse("2006-01-02T15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &parsed
+	}
+
+	for _, tc := range []struct {
+		query   string
+		want    models.JournalFilter
+		wantErr string
+	}{
+		{"", models.JournalFilter{}, ""},
+		{
+			// Дата окончания без времени включается целиком
+			"from=2024-03-01&to=2024-03-31&driver_id=2&route_id=3&auto_number=А123&status=completed",
+			models.JournalFilter{TimeOutFrom: date("2024-03-01T00:00"), TimeOutTo: date("2024-04-01T00:00"),
+				DriverID: 2, RouteID: 3, AutoNumber: "А123", Status: models.JournalStatusCompleted},
+			"",
+		},
+		{"from=2024-03-01T08:30&to=2024-03-01T18:00", models.JournalFilter{TimeOutFrom: date("2024-03-01T08:30"), TimeOutTo: date("2024-03-01T18:00")}, ""},
+		{"sort=driver&order=desc&page=3&page_size=20", models.JournalFilter{SortBy: models.JournalSortDriver, SortDesc: true, Page: 3, PageSize: 20}, ""},
+		{"sort=route&order=asc", models.JournalFilter{SortBy: models.JournalSortRoute}, ""},
+		{"from=01.03.2024", models.JournalFilter{}, "некорректная дата from"},
+		{"to=tomorrow", models.JournalFilter{}, "некорректная дата to"},
+		{"driver_id=abc", models.JournalFilter{}, "driver_id"},
+		{"page=-1", models.JournalFilter{}, "page"},
+		{"page_size=ten", models.JournalFilter{}, "page_size"},
+		{"order=up", models.JournalFilter{}, "направление сортировки"},
+	} {
+		filter, err := parseJournalFilter(httptest.NewRequest(http.MethodGet, "/journal?"+tc.query, nil))
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%q: error %v, want %q", tc.query, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.query, err)
+			continue
+		}
+		if !equalTimes(filter.TimeOutFrom, tc.want.TimeOutFrom) || !equalTimes(filter.TimeOutTo, tc.want.TimeOutTo) {
+			t.Errorf("%q: interval %v - %v, want %v - %v", tc.query, filter.TimeOutFrom, filter.TimeOutTo, tc.want.TimeOutFrom, tc.want.TimeOutTo)
+		}
+		filter.TimeOutFrom, filter.TimeOutTo = nil, nil
+		tc.want.TimeOutFrom, tc.want.TimeOutTo = nil, nil
+		if filter != tc.want {
+			t.Errorf("%q: filter %+v, want %+v", tc.query, filter, tc.want)
+		}
+	}
+}
+
+// Номер страницы подставляется в строку запроса, остальные фильтры сохраняются
+func TestPageQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/journal?status=completed&page=2&page_size=20&format=pdf&route_id=", nil)
+	if got, want := string(pageQuery(req.URL.Query(), 3)), "format=pdf&page=3&page_size=20&status=completed"; got != want {
+		t.Errorf("pageQuery = %q, want %q", got, want)
+	}
+	if got, want := string(journalFilterQuery(req.URL.Query())), "status=completed"; got != want {
+		t.Errorf("journalFilterQuery = %q, want %q", got, want)
+	}
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -1,26 +1,15 @@
 package handlers
 
 import (
-	"github.com/gorilla/sessions"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
 
+	"AutoParkWeb/internal/logging"
 	"AutoParkWeb/internal/services"
 )
 
-var store = sessions.NewCookieStore([]byte("your-secret-key"))
-
-func init() {
-	store.Options = &sessions.Options{
-		Path:     "/",
-		MaxAge:   3600, // Время жизни сессии = 1 час
-		HttpOnly: true,
-		Secure:   true,
-	}
-}
-
-func LoginPage(service *services.AutoParkService) http.HandlerFunc {
+func LoginPage(service *services.AutoParkService, sessions SessionManager, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
 			username := r.FormValue("username")
@@ -28,16 +17,17 @@ func LoginPage(service *services.AutoParkService) http.HandlerFunc {
 
 			user, err := service.AuthenticateUser(username, password)
 			if err != nil {
+				logger.WarnContext(r.Context(), "Неудачная попытка входа", "username", username, "remote_addr", clientAddr(r))
 				http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 				return
 			}
 
-			session, _ := store.Get(r, "session-name")
-
-			session.Values["user_id"] = user.ID
-			session.Values["username"] = user.Username
-			session.Values["user_role"] = user.Role
-			session.Save(r, w)
+			if err := sessions.Login(w, r, user); err != nil {
+				logger.ErrorContext(r.Context(), "Ошибка создания сессии", "error", err)
+				http.Error(w, "Не удалось выполнить вход", http.StatusInternalServerError)
+				return
+			}
+			logging.SetUser(r.Context(), user.Username)
 
 			http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 			return
@@ -46,12 +36,25 @@ func LoginPage(service *services.AutoParkService) http.HandlerFunc {
 		http.ServeFile(w, r, "ui/template/login.html")
 	}
 }
-func RegisterPage(service *services.AutoParkService) http.HandlerFunc {
+
+// Выход: сессия удаляется на сервере, если она там хранится, и cookie сбрасывается
+func Logout(sessions SessionManager, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := sessions.Logout(w, r); err != nil {
+			logger.ErrorContext(r.Context(), "Ошибка завершения сессии", "error", err)
+			http.Error(w, "Не удалось выполнить выход", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+	}
+}
+
+func RegisterPage(service *services.AutoParkService, sessions SessionManager, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			tmpl, err := template.ParseFiles("ui/template/register.html")
 			if err != nil {
-				log.Printf("Ошибка парсинга шаблона: %v", err)
+				logger.ErrorContext(r.Context(), "Ошибка парсинга шаблона", "error", err)
 				http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
 				return
 			}
@@ -82,15 +85,16 @@ func RegisterPage(service *services.AutoParkService) http.HandlerFunc {
 
 			user, err := service.AuthenticateUser(username, password)
 			if err != nil {
+				logger.ErrorContext(r.Context(), "Ошибка аутентификации после регистрации", "username", username, "error", err)
 				http.Error(w, "Ошибка аутентификации", http.StatusInternalServerError)
 				return
 			}
 
-			session, _ := store.Get(r, "session-name")
-			session.Values["user_id"] = user.ID
-			session.Values["username"] = user.Username
-			session.Values["user_role"] = user.Role
-			session.Save(r, w)
+			if err := sessions.Login(w, r, user); err != nil {
+				logger.ErrorContext(r.Context(), "Ошибка создания сессии", "error", err)
+				http.Error(w, "Не удалось выполнить вход", http.StatusInternalServerError)
+				return
+			}
 
 			http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 		}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Виды работ, которые предлагаются в формах; можно указать и любой другой
+var maintenanceTypes = []string{"Замена масла", "Техосмотр", "Замена шин", "ТО-1", "ТО-2", "Ремонт"}
+
+// Необязательное целое число из поля формы: пустое поле — nil
+func parseOptionalFormInt(value, name string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("некорректное значение %s: %s", name, value)
+	}
+	return &n, nil
+}
+
+// Необязательное дробное число из поля формы; допускается запятая как разделитель
+func parseOptionalFormFloat(value, name string) (*float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("некорректное значение %s: %s", name, value)
+	}
+	return &n, nil
+}
+
+func parseCost(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	if value == "" {
+		return 0, nil
+	}
+	cost, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная стоимость: %s", value)
+	}
+	return cost, nil
+}
+
+// Страница технического обслуживания: сроки по регламентам и история работ
+func (h *AutoParkHandler) MaintenancePage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	autoID, err := parseOptionalInt(r.URL.Query(), "auto_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := h.service.GetServiceStatuses(ctx, time.Now())
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить сроки обслуживания", err)
+		return
+	}
+	records, err := h.service.GetMaintenanceRecords(ctx, autoID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить историю обслуживания", err)
+		return
+	}
+	cars, err := h.service.GetCars(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список автомобилей", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/maintenance.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title    string
+		Statuses []models.ServiceStatus
+		Records  []models.MaintenanceRecord
+		Cars     []models.Auto
+		AutoID   int
+		Types    []string
+		Today    string
+		UserRole string
+		Username string
+	}{
+		Title:    "Техническое обслуживание",
+		Statuses: statuses,
+		Records:  records,
+		Cars:     cars,
+		AutoID:   autoID,
+		Types:    maintenanceTypes,
+		Today:    time.Now().Format("2006-01-02"),
+		UserRole: user.Role,
+		Username: user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
+
+// Добавление записи об обслуживании из формы
+func (h *AutoParkHandler) AddMaintenanceRecord(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+
+	record := models.MaintenanceRecord{
+		Type:  r.Form.Get("type"),
+		Notes: strings.TrimSpace(r.Form.Get("notes")),
+	}
+	record.AutoID, _ = strconv.Atoi(r.Form.Get("auto_id"))
+
+	var err error
+	if value := r.Form.Get("performed_at"); value != "" {
+		if record.PerformedAt, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Некорректная дата обслуживания", http.StatusBadRequest)
+			return
+		}
+	}
+	if record.Odometer, err = parseOptionalFormInt(r.Form.Get("odometer"), "пробега"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if record.Cost, err = parseCost(r.Form.Get("cost")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.AddMaintenanceRecord(r.Context(), record); err != nil {
+		h.serviceError(w, r, "Не удалось добавить запись", err)
+		return
+	}
+
+	http.Redirect(w, r, "/maintenance", http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) DeleteMaintenanceRecord(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID записи", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteMaintenanceRecord(r.Context(), recordID); err != nil {
+		h.serviceError(w, r, "Не удалось удалить запись", err)
+		return
+	}
+
+	http.Redirect(w, r, "/maintenance", http.StatusSeeOther)
+}
+
+// Добавление регламента обслуживания из формы
+func (h *AutoParkHandler) AddMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+
+	schedule := models.MaintenanceSchedule{
+		Type:      r.Form.Get("type"),
+		Mandatory: r.Form.Get("mandatory") != "",
+	}
+	schedule.AutoID, _ = strconv.Atoi(r.Form.Get("auto_id"))
+
+	var err error
+	if schedule.IntervalDays, err = parseOptionalFormInt(r.Form.Get("interval_days"), "периодичности в днях"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if schedule.IntervalKm, err = parseOptionalFormInt(r.Form.Get("interval_km"), "периодичности в километрах"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.AddMaintenanceSchedule(r.Context(), schedule); err != nil {
+		h.serviceError(w, r, "Не удалось добавить регламент", err)
+		return
+	}
+
+	http.Redirect(w, r, "/maintenance", http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) DeleteMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID регламента", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteMaintenanceSchedule(r.Context(), scheduleID); err != nil {
+		h.serviceError(w, r, "Не удалось удалить регламент", err)
+		return
+	}
+
+	http.Redirect(w, r, "/maintenance", http.StatusSeeOther)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Отчет о пробеге и расходе топлива за период
+func (h *AutoParkHandler) MileagePage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetMileageReport(r.Context(), filter)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось построить отчет", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/mileage.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	query := r.URL.Query()
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title    string
+		Report   *models.MileageReport
+		From     string
+		To       string
+		UserRole string
+		Username string
+	}{
+		Title:    "Пробег и расход топлива",
+		Report:   report,
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		UserRole: user.Role,
+		Username: user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
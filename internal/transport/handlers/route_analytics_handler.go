@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Данные графика тренда: общая шкала интервалов и по линии на маршрут;
+// nil в Values — в интервале не было рейсов по маршруту
+type routeTrendChart struct {
+	Labels []string           `json:"labels"`
+	Series []routeTrendSeries `json:"series"`
+}
+
+type routeTrendSeries struct {
+	Name   string     `json:"name"`
+	Values []*float64 `json:"values"`
+}
+
+func newRouteTrendChart(points []models.RouteTrendPoint) routeTrendChart {
+	chart := routeTrendChart{Labels: []string{}, Series: []routeTrendSeries{}}
+	periods := map[string]int{}
+	for _, point := range points {
+		label := point.PeriodStart.Format("02.01.2006")
+		if _, ok := periods[label]; !ok {
+			periods[label] = len(chart.Labels)
+			chart.Labels = append(chart.Labels, label)
+		}
+	}
+
+	series := map[int]int{}
+	for _, point := range points {
+		index, ok := series[point.RouteID]
+		if !ok {
+			index = len(chart.Series)
+			series[point.RouteID] = index
+			chart.Series = append(chart.Series, routeTrendSeries{
+				Name: point.RouteName, Values: make([]*float64, len(chart.Labels)),
+			})
+		}
+		average := point.AverageTime
+		chart.Series[index].Values[periods[point.PeriodStart.Format("02.01.2006")]] = &average
+	}
+	return chart
+}
+
+// Длительность рейсов по маршрутам; фильтры те же, что у журнала, interval=day|week|month
+func (h *APIHandler) RouteDurations(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJournalFilter(r)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	report, err := h.service.GetRouteDurationReport(r.Context(), filter, r.URL.Query().Get("interval"))
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, report)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Необязательные координаты из пары полей формы: оба пустые — nil
+func parseFormGeoPoint(lat, lon, name string) (*models.GeoPoint, error) {
+	latValue, err := parseOptionalFormFloat(lat, "широты")
+	if err != nil {
+		return nil, err
+	}
+	lonValue, err := parseOptionalFormFloat(lon, "долготы")
+	if err != nil {
+		return nil, err
+	}
+	if latValue == nil && lonValue == nil {
+		return nil, nil
+	}
+	if latValue == nil || lonValue == nil {
+		return nil, fmt.Errorf("укажите и широту, и долготу точки %q", name)
+	}
+	return &models.GeoPoint{Lat: *latValue, Lon: *lonValue}, nil
+}
+
+// Маршрут из формы редактирования. Остановки передаются повторяющимися полями
+// stop_name, stop_lat и stop_lon в порядке следования; строки без названия пропускаются.
+func parseRouteForm(r *http.Request) (models.Route, error) {
+	route := models.Route{
+		StartPoint: strings.TrimSpace(r.FormValue("start_point")),
+		EndPoint:   strings.TrimSpace(r.FormValue("end_point")),
+	}
+	var err error
+	if route.DistanceKm, err = parseOptionalFormFloat(r.FormValue("distance_km"), "протяженности"); err != nil {
+		return route, err
+	}
+	if route.PlannedMinutes, err = parseOptionalFormInt(r.FormValue("planned_minutes"), "плановой длительности"); err != nil {
+		return route, err
+	}
+	if route.StartCoords, err = parseFormGeoPoint(r.FormValue("start_lat"), r.FormValue("start_lon"), route.StartPoint); err != nil {
+		return route, err
+	}
+	if route.EndCoords, err = parseFormGeoPoint(r.FormValue("end_lat"), r.FormValue("end_lon"), route.EndPoint); err != nil {
+		return route, err
+	}
+
+	names, lats, lons := r.Form["stop_name"], r.Form["stop_lat"], r.Form["stop_lon"]
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var lat, lon string
+		if i < len(lats) {
+			lat = lats[i]
+		}
+		if i < len(lons) {
+			lon = lons[i]
+		}
+		coords, err := parseFormGeoPoint(lat, lon, name)
+		if err != nil {
+			return route, err
+		}
+		route.Stops = append(route.Stops, models.RouteStop{Name: name, Coords: coords})
+	}
+	return route, nil
+}
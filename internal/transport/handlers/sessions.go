@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+	"github.com/gorilla/sessions"
+)
+
+const sessionCookieName = "session-name"
+
+// Сессии веб-интерфейса. Хранятся либо целиком в подписанной cookie, либо на
+// сервере, и тогда в cookie лежит только токен сессии.
+type SessionManager interface {
+	Login(w http.ResponseWriter, r *http.Request, user *models.User) error
+	User(r *http.Request) (*models.User, bool)
+	Logout(w http.ResponseWriter, r *http.Request) error
+	// Можно ли просматривать и отзывать сессии на сервере
+	ServerSide() bool
+}
+
+// Хранилище cookie, подписанных ключами keys. Ключи передаются gorilla/sessions
+// парами «ключ подписи, ключ шифрования»: подпись новым ключом, проверка любым.
+func newCookieStore(keys [][]byte, secure bool) *sessions.CookieStore {
+	pairs := make([][]byte, 0, len(keys)*2)
+	for _, key := range keys {
+		pairs = append(pairs, key, nil)
+	}
+	store := sessions.NewCookieStore(pairs...)
+	store.Options = &sessions.Options{
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	// Срок действия и cookie в браузере, и подписи: иначе перехваченная cookie
+	// проходит проверку еще 30 дней после окончания сессии
+	store.MaxAge(int(services.UserSessionTTL.Seconds()))
+	return store
+}
+
+// Сведения о пользователе хранятся в подписанной cookie; отозвать такую сессию
+// до истечения срока можно только сменой ключей
+type cookieSessions struct {
+	store *sessions.CookieStore
+}
+
+func NewCookieSessions(keys [][]byte, secure bool) SessionManager {
+	return &cookieSessions{store: newCookieStore(keys, secure)}
+}
+
+func (s *cookieSessions) Login(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	// Cookie, подписанная выведенным из ротации ключом, заменяется новой
+	session, _ := s.store.Get(r, sessionCookieName)
+	session.Values["user_id"] = user.ID
+	session.Values["username"] = user.Username
+	session.Values["user_role"] = user.Role
+	return session.Save(r, w)
+}
+
+func (s *cookieSessions) User(r *http.Request) (*models.User, bool) {
+	session, err := s.store.Get(r, sessionCookieName)
+	if err != nil {
+		return nil, false
+	}
+
+	userID, ok := session.Values["user_id"].(int)
+	if !ok || userID == 0 {
+		return nil, false
+	}
+	username, _ := session.Values["username"].(string)
+	role, _ := session.Values["user_role"].(string)
+
+	return &models.User{ID: userID, Username: username, Role: role}, true
+}
+
+func (s *cookieSessions) Logout(w http.ResponseWriter, r *http.Request) error {
+	return expireSession(s.store, w, r)
+}
+
+func (s *cookieSessions) ServerSide() bool {
+	return false
+}
+
+// Сессии в базе данных: пользователь и его роль читаются при каждом запросе,
+// поэтому удаление сессии на сервере сразу завершает ее
+type serverSessions struct {
+	store   *sessions.CookieStore
+	service *services.AutoParkService
+}
+
+func NewServerSessions(service *services.AutoParkService, keys [][]byte, secure bool) SessionManager {
+	return &serverSessions{store: newCookieStore(keys, secure), service: service}
+}
+
+func (s *serverSessions) Login(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	session, _ := s.store.Get(r, sessionCookieName)
+	// Прежняя сессия этого браузера не переиспользуется
+	if token, ok := session.Values["token"].(string); ok {
+		if err := s.service.EndUserSession(r.Context(), token); err != nil {
+			return err
+		}
+	}
+
+	token, err := s.service.CreateUserSession(r.Context(), user.ID, r.UserAgent(), clientAddr(r))
+	if err != nil {
+		return err
+	}
+	session.Values = map[interface{}]interface{}{"token": token}
+	return session.Save(r, w)
+}
+
+func (s *serverSessions) User(r *http.Request) (*models.User, bool) {
+	session, err := s.store.Get(r, sessionCookieName)
+	if err != nil {
+		return nil, false
+	}
+	token, ok := session.Values["token"].(string)
+	if !ok {
+		return nil, false
+	}
+	user, err := s.service.AuthenticateUserSession(r.Context(), token)
+	if err != nil {
+		return nil, false
+	}
+	return user, true
+}
+
+func (s *serverSessions) Logout(w http.ResponseWriter, r *http.Request) error {
+	session, _ := s.store.Get(r, sessionCookieName)
+	if token, ok := session.Values["token"].(string); ok {
+		if err := s.service.EndUserSession(r.Context(), token); err != nil {
+			return err
+		}
+	}
+	return expireSession(s.store, w, r)
+}
+
+func (s *serverSessions) ServerSide() bool {
+	return true
+}
+
+// Удаление cookie сессии в браузере
+func expireSession(store *sessions.CookieStore, w http.ResponseWriter, r *http.Request) error {
+	session, _ := store.Get(r, sessionCookieName)
+	session.Values = map[interface{}]interface{}{}
+	session.Options.MaxAge = -1
+	return session.Save(r, w)
+}
+
+// Адрес клиента без порта
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// Пользователь, чьи сессии видны текущему: администратор видит всех (0)
+func sessionsOwner(r *http.Request) int {
+	user := currentUser(r)
+	if user.Role == models.RoleAdmin {
+		return 0
+	}
+	return user.ID
+}
+
+// Действующие сессии веб-интерфейса; при хранении сессий в cookie список недоступен
+func SessionsPage(service *services.AutoParkService, sessions SessionManager, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var list []models.UserSession
+		if sessions.ServerSide() {
+			var err error
+			list, err = service.GetUserSessions(r.Context(), sessionsOwner(r))
+			if err != nil {
+				logger.ErrorContext(r.Context(), "Ошибка получения сессий", "error", err)
+				http.Error(w, "Не удалось загрузить список сессий", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		tmpl, err := template.ParseFiles(
+			"./ui/template/layout.html", "./ui/template/sessions.html",
+		)
+		if err != nil {
+			http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
+			return
+		}
+
+		user := currentUser(r)
+		err = tmpl.Execute(w, struct {
+			Title      string
+			Sessions   []models.UserSession
+			ServerSide bool
+			Now        time.Time
+			UserRole   string
+			Username   string
+		}{
+			Title:      "Активные сессии",
+			Sessions:   list,
+			ServerSide: sessions.ServerSide(),
+			Now:        time.Now(),
+			UserRole:   user.Role,
+			Username:   user.Username,
+		})
+		if err != nil {
+			http.Error(w, "Ошибка отображения страницы", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// Отзыв сессии: пользователь может завершить свои сессии, администратор — любые
+func RevokeSession(service *services.AutoParkService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Некорректный ID сессии", http.StatusBadRequest)
+			return
+		}
+
+		if err := service.RevokeUserSession(r.Context(), sessionsOwner(r), sessionID); err != nil {
+			http.Error(w, "Не удалось отозвать сессию: "+err.Error(), statusForError(err))
+			return
+		}
+
+		http.Redirect(w, r, "/sessions", http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AutoParkWeb/internal/models"
+)
+
+// День недели для флажков формы расписания
+type weekdayOption struct {
+	Value int
+	Title string
+}
+
+var weekdayOptions = []weekdayOption{
+	{1, "Пн"}, {2, "Вт"}, {3, "Ср"}, {4, "Чт"}, {5, "Пт"}, {6, "Сб"}, {7, "Вс"},
+}
+
+// Исключение с названием расписания для страницы; пустое — праздничный день
+type timetableExceptionRow struct {
+	models.TimetableException
+	Timetable string
+}
+
+// Расписание из полей формы или запроса API
+func parseTimetable(routeID, autoID, driverID int, departureTime string, duration *int, weekdays []string, validFrom, validTo string) (models.Timetable, error) {
+	timetable := models.Timetable{RouteID: routeID, AutoID: autoID, DriverID: driverID,
+		DepartureTime: departureTime, DurationMinutes: duration}
+	for _, value := range weekdays {
+		day, err := strconv.Atoi(value)
+		if err != nil {
+			return timetable, fmt.Errorf("некорректный день недели: %s", value)
+		}
+		timetable.Weekdays = append(timetable.Weekdays, day)
+	}
+	from, err := parseOptionalDate(validFrom, "начала действия")
+	if err != nil {
+		return timetable, err
+	}
+	if from != nil {
+		timetable.ValidFrom = *from
+	}
+	if timetable.ValidTo, err = parseOptionalDate(validTo, "окончания действия"); err != nil {
+		return timetable, err
+	}
+	return timetable, nil
+}
+
+// Страница расписаний маршрутов и исключений (праздничных дней)
+func (h *AutoParkHandler) TimetablesPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	routeID, err := parseOptionalInt(r.URL.Query(), "route_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timetables, err := h.service.GetTimetables(ctx, routeID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить расписания", err)
+		return
+	}
+	exceptions, err := h.service.GetTimetableExceptions(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить исключения", err)
+		return
+	}
+	routes, err := h.service.GetRoutes(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список маршрутов", err)
+		return
+	}
+	cars, err := h.service.GetCars(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список автомобилей", err)
+		return
+	}
+	drivers, err := h.service.GetDrivers(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
+		return
+	}
+
+	// Названия расписаний для списка исключений
+	all, err := h.service.GetTimetables(ctx, 0)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить расписания", err)
+		return
+	}
+	titles := map[int]string{}
+	for _, timetable := range all {
+		titles[timetable.ID] = timetable.RouteName + ", " + timetable.DepartureTime
+	}
+	exceptionRows := make([]timetableExceptionRow, 0, len(exceptions))
+	for _, exception := range exceptions {
+		row := timetableExceptionRow{TimetableException: exception}
+		if exception.TimetableID != nil {
+			row.Timetable = titles[*exception.TimetableID]
+		}
+		exceptionRows = append(exceptionRows, row)
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/timetables.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title         string
+		Timetables    []models.Timetable
+		AllTimetables []models.Timetable
+		Exceptions    []timetableExceptionRow
+		Routes        []models.Route
+		Cars          []models.Auto
+		Drivers       []models.AutoPersonal
+		Weekdays      []weekdayOption
+		RouteID       int
+		Today         string
+		UserRole      string
+		Username      string
+	}{
+		Title:         "Расписания маршрутов",
+		Timetables:    timetables,
+		AllTimetables: all,
+		Exceptions:    exceptionRows,
+		Routes:        routes,
+		Cars:          cars,
+		Drivers:       drivers,
+		Weekdays:      weekdayOptions,
+		RouteID:       routeID,
+		Today:         time.Now().Format("2006-01-02"),
+		UserRole:      user.Role,
+		Username:      user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
+
+func (h *AutoParkHandler) AddTimetable(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+	routeID, _ := strconv.Atoi(r.FormValue("route_id"))
+	autoID, _ := strconv.Atoi(r.FormValue("auto_id"))
+	driverID, _ := strconv.Atoi(r.FormValue("driver_id"))
+	duration, err := parseOptionalFormInt(r.FormValue("duration_minutes"), "длительности рейса")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	timetable, err := parseTimetable(routeID, autoID, driverID, r.FormValue("departure_time"), duration,
+		r.Form["weekdays"], r.FormValue("valid_from"), r.FormValue("valid_to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.AddTimetable(r.Context(), timetable); err != nil {
+		h.serviceError(w, r, "Не удалось добавить расписание", err)
+		return
+	}
+	http.Redirect(w, r, "/timetables", http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) DeleteTimetable(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID расписания", http.StatusBadRequest)
+		return
+	}
+	if err := h.service.DeleteTimetable(r.Context(), id); err != nil {
+		h.serviceError(w, r, "Не удалось удалить расписание", err)
+		return
+	}
+	http.Redirect(w, r, "/timetables", http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) AddTimetableException(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+	exception := models.TimetableException{Reason: r.FormValue("reason")}
+	if timetableID, _ := strconv.Atoi(r.FormValue("timetable_id")); timetableID > 0 {
+		exception.TimetableID = &timetableID
+	}
+	date, err := parseOptionalDate(r.FormValue("date"), "исключения")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if date != nil {
+		exception.Date = *date
+	}
+
+	if _, err := h.service.AddTimetableException(r.Context(), exception); err != nil {
+		h.serviceError(w, r, "Не удалось добавить исключение", err)
+		return
+	}
+	http.Redirect(w, r, "/timetables", http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) DeleteTimetableException(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID исключения", http.StatusBadRequest)
+		return
+	}
+	if err := h.service.DeleteTimetableException(r.Context(), id); err != nil {
+		h.serviceError(w, r, "Не удалось удалить исключение", err)
+		return
+	}
+	http.Redirect(w, r, "/timetables", http.StatusSeeOther)
+}
+
+// Внеочередное формирование планов, не дожидаясь фонового запуска
+func (h *AutoParkHandler) GenerateTimetablePlans(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.service.GenerateTimetablePlans(r.Context()); err != nil {
+		h.serviceError(w, r, "Не удалось сформировать планы", err)
+		return
+	}
+	http.Redirect(w, r, "/plans", http.StatusSeeOther)
+}
+
+type timetableRequest struct {
+	RouteID         int    `json:"route_id"`
+	AutoID          int    `json:"auto_id"`
+	DriverID        int    `json:"driver_id"`
+	DepartureTime   string `json:"departure_time"`
+	DurationMinutes *int   `json:"duration_minutes"`
+	Weekdays        []int  `json:"weekdays"`
+	ValidFrom       string `json:"valid_from"`
+	ValidTo         string `json:"valid_to"`
+}
+
+type timetableExceptionRequest struct {
+	TimetableID *int   `json:"timetable_id"`
+	Date        string `json:"date"`
+	Reason      string `json:"reason"`
+}
+
+// Расписания; route_id ограничивает выборку одним маршрутом
+func (h *APIHandler) ListTimetables(w http.ResponseWriter, r *http.Request) {
+	routeID, err := parseOptionalInt(r.URL.Query(), "route_id")
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	timetables, err := h.service.GetTimetables(r.Context(), routeID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, timetables)
+}
+
+func (h *APIHandler) GetTimetable(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	h.respondTimetable(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) CreateTimetable(w http.ResponseWriter, r *http.Request) {
+	var req timetableRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	timetable, err := parseTimetable(req.RouteID, req.AutoID, req.DriverID, req.DepartureTime, req.DurationMinutes,
+		nil, req.ValidFrom, req.ValidTo)
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	timetable.Weekdays = req.Weekdays
+
+	id, err := h.service.AddTimetable(r.Context(), timetable)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondTimetable(w, r, id, http.StatusCreated)
+}
+
+func (h *APIHandler) DeleteTimetable(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteTimetable(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) ListTimetableExceptions(w http.ResponseWriter, r *http.Request) {
+	exceptions, err := h.service.GetTimetableExceptions(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, exceptions)
+}
+
+// Исключение из расписания; без timetable_id — праздничный день для всех расписаний
+func (h *APIHandler) CreateTimetableException(w http.ResponseWriter, r *http.Request) {
+	var req timetableExceptionRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	date, err := parseOptionalDate(req.Date, "date")
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	exception := models.TimetableException{TimetableID: req.TimetableID, Reason: req.Reason}
+	if date != nil {
+		exception.Date = *date
+	}
+
+	id, err := h.service.AddTimetableException(r.Context(), exception)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	exception.ID = id
+	h.writeJSON(w, r, http.StatusCreated, exception)
+}
+
+func (h *APIHandler) DeleteTimetableException(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteTimetableException(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Внеочередное формирование планов; в ответе — число созданных планов
+func (h *APIHandler) GenerateTimetablePlans(w http.ResponseWriter, r *http.Request) {
+	created, err := h.service.GenerateTimetablePlans(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, map[string]int{"created": created})
+}
+
+func (h *APIHandler) respondTimetable(w http.ResponseWriter, r *http.Request, id, status int) {
+	timetable, err := h.service.GetTimetableByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, status, timetable)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// Страница токенов доступа к API текущего пользователя
+func (h *AutoParkHandler) TokensPage(w http.ResponseWriter, r *http.Request) {
+	h.renderTokensPage(w, r, "", "")
+}
+
+// Выпуск нового токена, значение показывается пользователю один раз
+func (h *AutoParkHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt *time.Time
+	if value := r.Form.Get("expires_at"); value != "" {
+		day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			http.Error(w, "Некорректная дата окончания действия", http.StatusBadRequest)
+			return
+		}
+		// Токен действует до конца выбранного дня
+		end := day.AddDate(0, 0, 1)
+		expiresAt = &end
+	}
+
+	token, _, err := h.service.CreateAPIToken(r.Context(), currentUser(r).ID, r.Form.Get("name"), expiresAt)
+	if err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			h.renderTokensPage(w, r, "", validationErr.Message)
+			return
+		}
+		h.serviceError(w, r, "Не удалось создать токен", err)
+		return
+	}
+
+	h.renderTokensPage(w, r, token, "")
+}
+
+// Отзыв токена
+func (h *AutoParkHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID токена", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RevokeAPIToken(r.Context(), currentUser(r).ID, tokenID); err != nil {
+		h.serviceError(w, r, "Не удалось отозвать токен", err)
+		return
+	}
+
+	http.Redirect(w, r, "/tokens", http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) renderTokensPage(w http.ResponseWriter, r *http.Request, newToken, formError string) {
+	user := currentUser(r)
+
+	tokens, err := h.service.GetAPITokens(r.Context(), user.ID)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список токенов", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/tokens.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	err = tmpl.Execute(w, struct {
+		Title     string
+		Tokens    []models.APIToken
+		NewToken  string
+		FormError string
+		Now       time.Time
+		UserRole  string
+		Username  string
+	}{
+		Title:     "Токены доступа к API",
+		Tokens:    tokens,
+		NewToken:  newToken,
+		FormError: formError,
+		Now:       time.Now(),
+		UserRole:  user.Role,
+		Username:  user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
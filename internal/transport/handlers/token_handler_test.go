@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"AutoParkWeb/internal/auth"
+	"AutoParkWeb/internal/database/memory"
+	"AutoParkWeb/internal/models"
+	"AutoParkWeb/internal/services"
+	"github.com/gorilla/mux"
+)
+
+func testUser(t *testing.T, service *services.AutoParkService, username string) *models.User {
+	t.Helper()
+	if err := service.RegisterUser(username, "secret123"); err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	user, err := service.AuthenticateUser(username, "secret123")
+	if err != nil {
+		t.Fatalf("AuthenticateUser: %v", err)
+	}
+	return user
+}
+
+// Отзыв чужого, несуществующего или уже отозванного токена — 404, а не ошибка сервера
+func TestRevokeToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := services.NewAutoParkService(memory.New(), nil, logger)
+	owner := testUser(t, service, "owner")
+	other := testUser(t, service, "other")
+	_, token, err := service.CreateAPIToken(context.Background(), owner.ID, "ci", nil)
+	if err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/tokens/{id}/revoke", NewAutoParkHandler(service, nil, logger).RevokeToken)
+
+	tokenID := fmt.Sprint(token.ID)
+	for _, tc := range []struct {
+		name   string
+		user   *models.User
+		id     string
+		status int
+	}{
+		{"invalid id", owner, "abc", http.StatusBadRequest},
+		{"missing token", owner, "999", http.StatusNotFound},
+		{"token of another user", other, tokenID, http.StatusNotFound},
+		{"own token", owner, tokenID, http.StatusSeeOther},
+		{"already revoked", owner, tokenID, http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/tokens/"+tc.id+"/revoke", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), tc.user))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Errorf("%s: status %d, want %d (%s)", tc.name, rec.Code, tc.status, rec.Body.String())
+		}
+	}
+}
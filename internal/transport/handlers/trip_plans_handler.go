@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"AutoParkWeb/internal/models"
+)
+
+// Фильтр планов из строки запроса: from, to (дата окончания включается целиком),
+// auto_id и driver_id
+func parseTripPlanFilter(query url.Values) (models.TripPlanFilter, error) {
+	var filter models.TripPlanFilter
+	if value := query.Get("from"); value != "" {
+		from, err := parseFilterDate(value)
+		if err != nil {
+			return filter, fmt.Errorf("некорректная дата from: %s", value)
+		}
+		filter.From = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := parseFilterDate(value)
+		if err != nil {
+			return filter, fmt.Errorf("некорректная дата to: %s", value)
+		}
+		if len(value) == len("2006-01-02") {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	var err error
+	if filter.AutoID, err = parseOptionalInt(query, "auto_id"); err != nil {
+		return filter, err
+	}
+	if filter.DriverID, err = parseOptionalInt(query, "driver_id"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// День календаря планирования
+type planDay struct {
+	Date  time.Time
+	Plans []models.TripPlan
+}
+
+// Календарь планирования рейсов: неделя с понедельника или один день
+func (h *AutoParkHandler) TripPlansPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	view := query.Get("view")
+	if view != "day" {
+		view = "week"
+	}
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := query.Get("date"); value != "" {
+		parsed, err := parseOptionalDate(value, "date")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		date = *parsed
+	}
+
+	start, days := date, 1
+	if view == "week" {
+		start = date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+		days = 7
+	}
+	end := start.AddDate(0, 0, days)
+
+	plans, err := h.service.GetTripPlans(ctx, models.TripPlanFilter{From: &start, To: &end})
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить планы рейсов", err)
+		return
+	}
+	calendar := make([]planDay, days)
+	for i := range calendar {
+		calendar[i].Date = start.AddDate(0, 0, i)
+	}
+	for _, plan := range plans {
+		day := int(plan.PlannedOut.Sub(start).Hours() / 24)
+		if day >= 0 && day < days {
+			calendar[day].Plans = append(calendar[day].Plans, plan)
+		}
+	}
+
+	cars, err := h.service.GetCars(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список автомобилей", err)
+		return
+	}
+	drivers, err := h.service.GetDrivers(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список водителей", err)
+		return
+	}
+	routes, err := h.service.GetRoutes(ctx)
+	if err != nil {
+		h.serviceError(w, r, "Не удалось загрузить список маршрутов", err)
+		return
+	}
+
+	tmpl, err := template.ParseFiles(
+		"./ui/template/layout.html", "./ui/template/plans.html",
+	)
+	if err != nil {
+		h.serviceError(w, r, "Ошибка загрузки шаблона", err)
+		return
+	}
+
+	user := currentUser(r)
+	err = tmpl.Execute(w, struct {
+		Title    string
+		View     string
+		Date     string
+		Prev     string
+		Next     string
+		Days     []planDay
+		Cars     []models.Auto
+		Drivers  []models.AutoPersonal
+		Routes   []models.Route
+		Today    string
+		UserRole string
+		Username string
+	}{
+		Title:    "Планирование рейсов",
+		View:     view,
+		Date:     date.Format("2006-01-02"),
+		Prev:     start.AddDate(0, 0, -days).Format("2006-01-02"),
+		Next:     end.Format("2006-01-02"),
+		Days:     calendar,
+		Cars:     cars,
+		Drivers:  drivers,
+		Routes:   routes,
+		Today:    now.Format("2006-01-02"),
+		UserRole: user.Role,
+		Username: user.Username,
+	})
+	if err != nil {
+		h.serviceError(w, r, "Ошибка отображения страницы", err)
+		return
+	}
+}
+
+// Возврат на календарь, с которого отправлена форма
+func redirectToPlans(w http.ResponseWriter, r *http.Request) {
+	target := "/plans"
+	if date := r.FormValue("date"); date != "" {
+		target += "?" + url.Values{"view": {r.FormValue("view")}, "date": {date}}.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func (h *AutoParkHandler) AddTripPlan(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+	autoID, _ := strconv.Atoi(r.FormValue("auto_id"))
+	driverID, _ := strconv.Atoi(r.FormValue("driver_id"))
+	routeID, _ := strconv.Atoi(r.FormValue("route_id"))
+
+	_, err := h.service.AddTripPlan(r.Context(), autoID, driverID, routeID,
+		r.FormValue("planned_out"), r.FormValue("planned_in"), r.FormValue("notes"))
+	if err != nil {
+		h.serviceError(w, r, "Не удалось запланировать рейс", err)
+		return
+	}
+	redirectToPlans(w, r)
+}
+
+// Отправка запланированного рейса в один клик; показания при отправлении необязательны
+func (h *AutoParkHandler) DispatchTripPlan(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID плана", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		return
+	}
+	var departure models.TripDeparture
+	if departure.Odometer, err = parseOptionalFormInt(r.Form.Get("odometer_out"), "пробега"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if departure.Fuel, err = parseOptionalFormFloat(r.Form.Get("fuel_out"), "остатка топлива"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.DispatchTripPlan(r.Context(), id, departure); err != nil {
+		h.serviceError(w, r, "Не удалось отправить рейс", err)
+		return
+	}
+	redirectToPlans(w, r)
+}
+
+func (h *AutoParkHandler) DeleteTripPlan(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Некорректный ID плана", http.StatusBadRequest)
+		return
+	}
+	if err := h.service.DeleteTripPlan(r.Context(), id); err != nil {
+		h.serviceError(w, r, "Не удалось отменить план", err)
+		return
+	}
+	redirectToPlans(w, r)
+}
+
+type tripPlanRequest struct {
+	AutoID     int    `json:"auto_id"`
+	DriverID   int    `json:"driver_id"`
+	RouteID    int    `json:"route_id"`
+	PlannedOut string `json:"planned_out"`
+	PlannedIn  string `json:"planned_in"`
+	Notes      string `json:"notes"`
+}
+
+// Планы по фильтру: from, to, auto_id, driver_id
+func (h *APIHandler) ListTripPlans(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTripPlanFilter(r.URL.Query())
+	if err != nil {
+		h.writeJSONError(w, r, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	plans, err := h.service.GetTripPlans(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, plans)
+}
+
+func (h *APIHandler) GetTripPlan(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	h.respondTripPlan(w, r, id, http.StatusOK)
+}
+
+func (h *APIHandler) CreateTripPlan(w http.ResponseWriter, r *http.Request) {
+	var req tripPlanRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	id, err := h.service.AddTripPlan(r.Context(), req.AutoID, req.DriverID, req.RouteID, req.PlannedOut, req.PlannedIn, req.Notes)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.respondTripPlan(w, r, id, http.StatusCreated)
+}
+
+func (h *APIHandler) DeleteTripPlan(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteTripPlan(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Отправка плана в рейс; тело с odometer_out и fuel_out можно не передавать.
+// В ответе — созданная запись журнала.
+func (h *APIHandler) DispatchTripPlan(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var departure models.TripDeparture
+	if r.ContentLength != 0 {
+		if !h.decodeJSON(w, r, &departure) {
+			return
+		}
+	}
+
+	entryID, err := h.service.DispatchTripPlan(r.Context(), id, departure)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	entry, err := h.service.GetJournalEntryByID(r.Context(), entryID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, http.StatusCreated, entry)
+}
+
+func (h *APIHandler) respondTripPlan(w http.ResponseWriter, r *http.Request, id, status int) {
+	plan, err := h.service.GetTripPlanByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, status, plan)
+}
@@ -2,8 +2,14 @@ package transport
 
 import (
 	"github.com/gorilla/mux"
+	"log/slog"
 	"net/http"
 
+	"AutoParkWeb/internal/config"
+	"AutoParkWeb/internal/export"
+	"AutoParkWeb/internal/logging"
+	"AutoParkWeb/internal/metrics"
+	"AutoParkWeb/internal/models"
 	"AutoParkWeb/internal/services"
 	"AutoParkWeb/internal/transport/handlers"
 )
@@ -20,13 +26,39 @@ func MethodOverride(next http.Handler) http.Handler {
 	})
 }
 
-func SetupRoutes(service *services.AutoParkService) *mux.Router {
+func SetupRoutes(service *services.AutoParkService, cfg *config.Config, registry *metrics.Registry, logger *slog.Logger) *mux.Router {
 	router := mux.NewRouter()
 
 	router.Use(MethodOverride)
+	// Идентификатор запроса и журнал доступа; проверки и метрики опрашиваются
+	// часто, поэтому пишутся только на уровне debug
+	router.Use(logging.Middleware(logger, "/healthz", "/readyz", "/metrics"))
+	router.Use(registry.HTTP.Middleware)
 
 	// Создаем HTTP обработчики
-	handler := handlers.NewAutoParkHandler(service)
+	handler := handlers.NewAutoParkHandler(service, export.NewJournalExporter(cfg.PDFFontPath, logger), logger)
+
+	// Сессии веб-интерфейса: cookie подписываются ключами из конфигурации,
+	// Secure выставляется в production
+	var sessions handlers.SessionManager
+	if cfg.SessionStore == config.SessionStoreServer {
+		sessions = handlers.NewServerSessions(service, cfg.SessionKeys, cfg.Production())
+	} else {
+		sessions = handlers.NewCookieSessions(cfg.SessionKeys, cfg.Production())
+	}
+
+	// Требования к роли задаются при регистрации маршрута
+	authMiddleware := handlers.NewAuthMiddleware(service, sessions, logger)
+	authenticated := authMiddleware.RequireRole()
+	adminOnly := authMiddleware.RequireRole(models.RoleAdmin)
+	user := func(h http.HandlerFunc) http.Handler { return authenticated(h) }
+	admin := func(h http.HandlerFunc) http.Handler { return adminOnly(h) }
+
+	// Проверки живости и готовности для оркестратора, без аутентификации
+	router.HandleFunc("/healthz", handlers.Healthz(logger)).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", handlers.Readyz(service, logger)).Methods(http.MethodGet)
+	// Метрики для Prometheus
+	router.Handle("/metrics", registry.Handler()).Methods(http.MethodGet)
 
 	// Обслуживание статических файлов из ui/static
 	router.PathPrefix("/static/").Handler(http.StripPrefix("/static", http.FileServer(http.Dir("./ui/static/"))))
@@ -35,50 +67,194 @@ func SetupRoutes(service *services.AutoParkService) *mux.Router {
 	router.HandleFunc("/", handlers.HomeHandler).Methods(http.MethodGet)
 
 	// Маршрут для страницы логина
-	router.HandleFunc("/login", handlers.LoginPage(service)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/login", handlers.LoginPage(service, sessions, logger)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/logout", handlers.Logout(sessions, logger)).Methods(http.MethodPost)
 	// Маршрут для регистрации
-	router.HandleFunc("/register", handlers.RegisterPage(service)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/register", handlers.RegisterPage(service, sessions, logger)).Methods(http.MethodGet, http.MethodPost)
+
+	// Просмотр и отзыв сессий веб-интерфейса
+	router.Handle("/sessions", user(handlers.SessionsPage(service, sessions, logger))).Methods(http.MethodGet)
+	router.Handle("/sessions/{id:[0-9]+}/revoke", user(handlers.RevokeSession(service))).Methods(http.MethodPost)
 
 	// Маршрут для рабочей страницы
-	router.HandleFunc("/dashboard", handlers.DashboardPage).Methods(http.MethodGet)
+	router.Handle("/dashboard", user(handler.DashboardPage)).Methods(http.MethodGet)
 
 	// Маршруты для работы с водителями
-	router.HandleFunc("/drivers", handler.GetDrivers).Methods(http.MethodGet)
-	router.HandleFunc("/drivers/new", handler.AddDriverPage).Methods(http.MethodGet)
-	router.HandleFunc("/drivers", handler.AddDriver).Methods(http.MethodPost)
-	router.HandleFunc("/drivers/{id}/edit", handler.EditDriverPage).Methods(http.MethodGet)
-	router.HandleFunc("/drivers/{id}", handler.UpdateDriver).Methods(http.MethodPost)
-	router.HandleFunc("/drivers/{id}/delete", handler.DeleteDriver).Methods(http.MethodPost)
+	router.Handle("/drivers", user(handler.GetDrivers)).Methods(http.MethodGet)
+	router.Handle("/drivers/new", admin(handler.AddDriverPage)).Methods(http.MethodGet)
+	router.Handle("/drivers", admin(handler.AddDriver)).Methods(http.MethodPost)
+	router.Handle("/drivers/{id}/edit", admin(handler.EditDriverPage)).Methods(http.MethodGet)
+	router.Handle("/drivers/{id}", admin(handler.UpdateDriver)).Methods(http.MethodPost)
+	router.Handle("/drivers/{id}/delete", admin(handler.ArchiveDriver)).Methods(http.MethodPost)
+	router.Handle("/drivers/{id}/restore", admin(handler.RestoreDriver)).Methods(http.MethodPost)
 
 	// Маршруты для работы с автомобилями
-	router.HandleFunc("/autos", handler.GetCars).Methods(http.MethodGet)
-	router.HandleFunc("/autos/new", handler.AddCarPage).Methods(http.MethodGet)
-	router.HandleFunc("/autos", handler.AddCar).Methods(http.MethodPost)
-	router.HandleFunc("/autos/{id}/edit", handler.EditCarPage).Methods(http.MethodGet)
-	router.HandleFunc("/autos/{id}", handler.UpdateCar).Methods(http.MethodPost)
-	router.HandleFunc("/autos/{id}/delete", handler.DeleteCar).Methods(http.MethodPost)
+	router.Handle("/autos", user(handler.GetCars)).Methods(http.MethodGet)
+	router.Handle("/autos/new", admin(handler.AddCarPage)).Methods(http.MethodGet)
+	router.Handle("/autos", admin(handler.AddCar)).Methods(http.MethodPost)
+	router.Handle("/autos/{id}/edit", admin(handler.EditCarPage)).Methods(http.MethodGet)
+	router.Handle("/autos/{id}", admin(handler.UpdateCar)).Methods(http.MethodPost)
+	router.Handle("/autos/{id}/delete", admin(handler.ArchiveCar)).Methods(http.MethodPost)
+	router.Handle("/autos/{id}/restore", admin(handler.RestoreCar)).Methods(http.MethodPost)
+
+	// Документы автомобилей и их сканы
+	router.Handle("/autos/{id}/documents", user(handler.AutoDocumentsPage)).Methods(http.MethodGet)
+	router.Handle("/autos/{id}/documents", admin(handler.AddAutoDocument)).Methods(http.MethodPost)
+	router.Handle("/autos/documents/{id}/delete", admin(handler.DeleteAutoDocument)).Methods(http.MethodPost)
+	router.Handle("/autos/documents/{id}/files", admin(handler.UploadAutoDocumentFile)).Methods(http.MethodPost)
+	router.Handle("/autos/documents/files/{id}", user(handler.DownloadAutoDocumentFile)).Methods(http.MethodGet)
+	router.Handle("/autos/documents/files/{id}/delete", admin(handler.DeleteAutoDocumentFile)).Methods(http.MethodPost)
+
+	// Закрепление водителей за автомобилями
+	router.Handle("/assignments", user(handler.AssignmentsPage)).Methods(http.MethodGet)
+	router.Handle("/assignments", admin(handler.AddAssignment)).Methods(http.MethodPost)
+	router.Handle("/assignments/{id}/end", admin(handler.EndAssignment)).Methods(http.MethodPost)
+	router.Handle("/assignments/{id}/delete", admin(handler.DeleteAssignment)).Methods(http.MethodPost)
+
+	// Планирование рейсов
+	router.Handle("/plans", user(handler.TripPlansPage)).Methods(http.MethodGet)
+	router.Handle("/plans", admin(handler.AddTripPlan)).Methods(http.MethodPost)
+	router.Handle("/plans/{id}/dispatch", admin(handler.DispatchTripPlan)).Methods(http.MethodPost)
+	router.Handle("/plans/{id}/delete", admin(handler.DeleteTripPlan)).Methods(http.MethodPost)
+
+	// Расписания маршрутов
+	router.Handle("/timetables", user(handler.TimetablesPage)).Methods(http.MethodGet)
+	router.Handle("/timetables", admin(handler.AddTimetable)).Methods(http.MethodPost)
+	router.Handle("/timetables/{id}/delete", admin(handler.DeleteTimetable)).Methods(http.MethodPost)
+	router.Handle("/timetables/exceptions", admin(handler.AddTimetableException)).Methods(http.MethodPost)
+	router.Handle("/timetables/exceptions/{id}/delete", admin(handler.DeleteTimetableException)).Methods(http.MethodPost)
+	router.Handle("/timetables/generate", admin(handler.GenerateTimetablePlans)).Methods(http.MethodPost)
 
 	// Маршруты для работы с маршрутами
-	router.HandleFunc("/routes", handler.GetRoutes).Methods(http.MethodGet)
-	router.HandleFunc("/routes/new", handler.AddRoutePage).Methods(http.MethodGet)
-	router.HandleFunc("/routes", handler.AddRoute).Methods(http.MethodPost)
-	router.HandleFunc("/routes/{id}/edit", handler.EditRoutePage).Methods(http.MethodGet)
-	router.HandleFunc("/routes/{id}", handler.UpdateRoute).Methods(http.MethodPost)
-	router.HandleFunc("/routes/{id}/delete", handler.DeleteRoute).Methods(http.MethodPost)
+	router.Handle("/routes", user(handler.GetRoutes)).Methods(http.MethodGet)
+	router.Handle("/routes/new", admin(handler.AddRoutePage)).Methods(http.MethodGet)
+	router.Handle("/routes", admin(handler.AddRoute)).Methods(http.MethodPost)
+	router.Handle("/routes/{id}/edit", admin(handler.EditRoutePage)).Methods(http.MethodGet)
+	router.Handle("/routes/{id}", admin(handler.UpdateRoute)).Methods(http.MethodPost)
+	router.Handle("/routes/{id}/delete", admin(handler.ArchiveRoute)).Methods(http.MethodPost)
+	router.Handle("/routes/{id}/restore", admin(handler.RestoreRoute)).Methods(http.MethodPost)
+
+	// Архив водителей, автомобилей и маршрутов
+	router.Handle("/archive", admin(handler.ArchivePage)).Methods(http.MethodGet)
 
 	// Маршруты для работы с журналом
-	router.HandleFunc("/download", handler.DownloadJournal).Methods(http.MethodGet)
-	router.HandleFunc("/journal", handler.GetAllJournalEntries).Methods(http.MethodGet)
-	router.HandleFunc("/journal/new", handler.AddJournalEntryPage).Methods(http.MethodGet)
-	router.HandleFunc("/journal", handler.AddJournalEntry).Methods(http.MethodPost)
-	router.HandleFunc("/journal/{id}/edit", handler.EditJournalEntryPage).Methods(http.MethodGet)
-	router.HandleFunc("/journal/{id}/edit", handler.EditJournalEntryPage).Methods(http.MethodGet)
-	router.HandleFunc("/journal/{id}/complete", handler.CompleteJournalEntry).Methods(http.MethodPost)
-	router.HandleFunc("/journal/{id}/delete", handler.DeleteJournalEntry).Methods(http.MethodPost)
-	router.HandleFunc("/journal/{id}/update", handler.UpdateJournalEntry).Methods(http.MethodPost)
+	router.Handle("/download", admin(handler.DownloadJournal)).Methods(http.MethodGet)
+	router.Handle("/journal", user(handler.GetAllJournalEntries)).Methods(http.MethodGet)
+	router.Handle("/journal/new", admin(handler.AddJournalEntryPage)).Methods(http.MethodGet)
+	router.Handle("/journal", admin(handler.AddJournalEntry)).Methods(http.MethodPost)
+	router.Handle("/journal/{id}/edit", admin(handler.EditJournalEntryPage)).Methods(http.MethodGet)
+	router.Handle("/journal/{id}/complete", admin(handler.CompleteJournalEntry)).Methods(http.MethodPost)
+	router.Handle("/journal/{id}/delete", admin(handler.DeleteJournalEntry)).Methods(http.MethodPost)
+	router.Handle("/journal/{id}/update", admin(handler.UpdateJournalEntry)).Methods(http.MethodPost)
+
+	// Техническое обслуживание автомобилей
+	router.Handle("/maintenance", user(handler.MaintenancePage)).Methods(http.MethodGet)
+	router.Handle("/maintenance/records", admin(handler.AddMaintenanceRecord)).Methods(http.MethodPost)
+	router.Handle("/maintenance/records/{id}/delete", admin(handler.DeleteMaintenanceRecord)).Methods(http.MethodPost)
+	router.Handle("/maintenance/schedules", admin(handler.AddMaintenanceSchedule)).Methods(http.MethodPost)
+	router.Handle("/maintenance/schedules/{id}/delete", admin(handler.DeleteMaintenanceSchedule)).Methods(http.MethodPost)
+
+	// Импорт справочников из файла
+	router.Handle("/import", admin(handler.ImportPage)).Methods(http.MethodGet)
+	router.Handle("/import", admin(handler.Import)).Methods(http.MethodPost)
+
+	// Журнал аудита изменений
+	router.Handle("/audit", admin(handler.AuditPage)).Methods(http.MethodGet)
 
 	// Процедуры для аналитики
-	router.HandleFunc("/statistics", handler.StatisticsPage).Methods(http.MethodGet)
+	router.Handle("/statistics", user(handler.StatisticsPage)).Methods(http.MethodGet)
+	router.Handle("/statistics/mileage", user(handler.MileagePage)).Methods(http.MethodGet)
+	router.Handle("/statistics/drivers", user(handler.DriverReportPage)).Methods(http.MethodGet)
+	router.Handle("/statistics/drivers/export", user(handler.DownloadDriverReport)).Methods(http.MethodGet)
+	router.Handle("/statistics/drivers/{id:[0-9]+}", user(handler.DriverReportDetailPage)).Methods(http.MethodGet)
+
+	// Токены доступа к API
+	router.Handle("/tokens", user(handler.TokensPage)).Methods(http.MethodGet)
+	router.Handle("/tokens", user(handler.CreateToken)).Methods(http.MethodPost)
+	router.Handle("/tokens/{id}/revoke", user(handler.RevokeToken)).Methods(http.MethodPost)
+
+	// JSON API для скриптов и мобильного приложения
+	apiHandler := handlers.NewAPIHandler(service, logger)
+	api := router.PathPrefix("/api/v1").Subrouter()
+
+	api.Handle("/drivers", user(apiHandler.ListDrivers)).Methods(http.MethodGet)
+	api.Handle("/drivers", admin(apiHandler.CreateDriver)).Methods(http.MethodPost)
+	api.Handle("/drivers/{id:[0-9]+}", user(apiHandler.GetDriver)).Methods(http.MethodGet)
+	api.Handle("/drivers/{id:[0-9]+}", admin(apiHandler.UpdateDriver)).Methods(http.MethodPut)
+	api.Handle("/drivers/{id:[0-9]+}", admin(apiHandler.DeleteDriver)).Methods(http.MethodDelete)
+	api.Handle("/drivers/{id:[0-9]+}/restore", admin(apiHandler.RestoreDriver)).Methods(http.MethodPost)
+	api.Handle("/drivers/documents/expiring", user(apiHandler.ExpiringDocuments)).Methods(http.MethodGet)
+
+	api.Handle("/autos", user(apiHandler.ListAutos)).Methods(http.MethodGet)
+	api.Handle("/autos", admin(apiHandler.CreateAuto)).Methods(http.MethodPost)
+	api.Handle("/autos/{id:[0-9]+}", user(apiHandler.GetAuto)).Methods(http.MethodGet)
+	api.Handle("/autos/{id:[0-9]+}", admin(apiHandler.UpdateAuto)).Methods(http.MethodPut)
+	api.Handle("/autos/{id:[0-9]+}", admin(apiHandler.DeleteAuto)).Methods(http.MethodDelete)
+	api.Handle("/autos/{id:[0-9]+}/restore", admin(apiHandler.RestoreAuto)).Methods(http.MethodPost)
+	api.Handle("/autos/{id:[0-9]+}/documents", user(apiHandler.ListAutoDocuments)).Methods(http.MethodGet)
+	api.Handle("/autos/{id:[0-9]+}/documents", admin(apiHandler.CreateAutoDocument)).Methods(http.MethodPost)
+	api.Handle("/autos/documents/{id:[0-9]+}", user(apiHandler.GetAutoDocument)).Methods(http.MethodGet)
+	api.Handle("/autos/documents/{id:[0-9]+}", admin(apiHandler.DeleteAutoDocument)).Methods(http.MethodDelete)
+	api.Handle("/autos/documents/{id:[0-9]+}/files", admin(apiHandler.UploadAutoDocumentFile)).Methods(http.MethodPost)
+	api.Handle("/autos/documents/files/{id:[0-9]+}", user(apiHandler.DownloadAutoDocumentFile)).Methods(http.MethodGet)
+	api.Handle("/autos/documents/files/{id:[0-9]+}", admin(apiHandler.DeleteAutoDocumentFile)).Methods(http.MethodDelete)
+
+	api.Handle("/assignments", user(apiHandler.ListAssignments)).Methods(http.MethodGet)
+	api.Handle("/assignments", admin(apiHandler.CreateAssignment)).Methods(http.MethodPost)
+	api.Handle("/assignments/{id:[0-9]+}", user(apiHandler.GetAssignment)).Methods(http.MethodGet)
+	api.Handle("/assignments/{id:[0-9]+}", admin(apiHandler.UpdateAssignment)).Methods(http.MethodPut)
+	api.Handle("/assignments/{id:[0-9]+}", admin(apiHandler.DeleteAssignment)).Methods(http.MethodDelete)
+
+	api.Handle("/plans", user(apiHandler.ListTripPlans)).Methods(http.MethodGet)
+	api.Handle("/plans", admin(apiHandler.CreateTripPlan)).Methods(http.MethodPost)
+	api.Handle("/plans/{id:[0-9]+}", user(apiHandler.GetTripPlan)).Methods(http.MethodGet)
+	api.Handle("/plans/{id:[0-9]+}", admin(apiHandler.DeleteTripPlan)).Methods(http.MethodDelete)
+	api.Handle("/plans/{id:[0-9]+}/dispatch", admin(apiHandler.DispatchTripPlan)).Methods(http.MethodPost)
+
+	api.Handle("/timetables", user(apiHandler.ListTimetables)).Methods(http.MethodGet)
+	api.Handle("/timetables", admin(apiHandler.CreateTimetable)).Methods(http.MethodPost)
+	api.Handle("/timetables/{id:[0-9]+}", user(apiHandler.GetTimetable)).Methods(http.MethodGet)
+	api.Handle("/timetables/{id:[0-9]+}", admin(apiHandler.DeleteTimetable)).Methods(http.MethodDelete)
+	api.Handle("/timetables/exceptions", user(apiHandler.ListTimetableExceptions)).Methods(http.MethodGet)
+	api.Handle("/timetables/exceptions", admin(apiHandler.CreateTimetableException)).Methods(http.MethodPost)
+	api.Handle("/timetables/exceptions/{id:[0-9]+}", admin(apiHandler.DeleteTimetableException)).Methods(http.MethodDelete)
+	api.Handle("/timetables/generate", admin(apiHandler.GenerateTimetablePlans)).Methods(http.MethodPost)
+
+	api.Handle("/routes", user(apiHandler.ListRoutes)).Methods(http.MethodGet)
+	api.Handle("/routes", admin(apiHandler.CreateRoute)).Methods(http.MethodPost)
+	api.Handle("/routes/{id:[0-9]+}", user(apiHandler.GetRoute)).Methods(http.MethodGet)
+	api.Handle("/routes/{id:[0-9]+}", admin(apiHandler.UpdateRoute)).Methods(http.MethodPut)
+	api.Handle("/routes/{id:[0-9]+}", admin(apiHandler.DeleteRoute)).Methods(http.MethodDelete)
+	api.Handle("/routes/{id:[0-9]+}/restore", admin(apiHandler.RestoreRoute)).Methods(http.MethodPost)
+
+	api.Handle("/archive", admin(apiHandler.ListArchive)).Methods(http.MethodGet)
+
+	api.Handle("/journal", user(apiHandler.ListJournal)).Methods(http.MethodGet)
+	api.Handle("/journal", admin(apiHandler.CreateJournalEntry)).Methods(http.MethodPost)
+	api.Handle("/journal/{id:[0-9]+}", user(apiHandler.GetJournalEntry)).Methods(http.MethodGet)
+	api.Handle("/journal/{id:[0-9]+}/complete", admin(apiHandler.CompleteJournalEntry)).Methods(http.MethodPost)
+	api.Handle("/journal/{id:[0-9]+}", admin(apiHandler.DeleteJournalEntry)).Methods(http.MethodDelete)
+
+	api.Handle("/maintenance/records", user(apiHandler.ListMaintenanceRecords)).Methods(http.MethodGet)
+	api.Handle("/maintenance/records", admin(apiHandler.CreateMaintenanceRecord)).Methods(http.MethodPost)
+	api.Handle("/maintenance/records/{id:[0-9]+}", admin(apiHandler.DeleteMaintenanceRecord)).Methods(http.MethodDelete)
+	api.Handle("/maintenance/schedules", user(apiHandler.ListMaintenanceSchedules)).Methods(http.MethodGet)
+	api.Handle("/maintenance/schedules", admin(apiHandler.CreateMaintenanceSchedule)).Methods(http.MethodPost)
+	api.Handle("/maintenance/schedules/{id:[0-9]+}", admin(apiHandler.DeleteMaintenanceSchedule)).Methods(http.MethodDelete)
+	api.Handle("/maintenance/status", user(apiHandler.MaintenanceStatus)).Methods(http.MethodGet)
+
+	api.Handle("/import", admin(apiHandler.Import)).Methods(http.MethodPost)
+	api.Handle("/audit", admin(apiHandler.ListAudit)).Methods(http.MethodGet)
+
+	api.Handle("/statistics", user(apiHandler.Statistics)).Methods(http.MethodGet)
+	api.Handle("/statistics/mileage", user(apiHandler.Mileage)).Methods(http.MethodGet)
+	api.Handle("/statistics/routes", user(apiHandler.RouteDurations)).Methods(http.MethodGet)
+	api.Handle("/statistics/drivers", user(apiHandler.DriverReport)).Methods(http.MethodGet)
+	api.Handle("/statistics/drivers/{id:[0-9]+}", user(apiHandler.DriverReportDetail)).Methods(http.MethodGet)
+
+	api.Handle("/tokens", user(apiHandler.ListTokens)).Methods(http.MethodGet)
+	api.Handle("/tokens", user(apiHandler.CreateToken)).Methods(http.MethodPost)
+	api.Handle("/tokens/{id:[0-9]+}", user(apiHandler.RevokeToken)).Methods(http.MethodDelete)
 
 	return router
 }
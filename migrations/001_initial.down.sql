@@ -0,0 +1,21 @@
+DROP FUNCTION IF EXISTS GET_ROUTES_VEHICLE_COUNT();
+
+DROP TRIGGER IF EXISTS TRIGGER_UNDELETE ON AUTO_PERSONAL;
+DROP FUNCTION IF EXISTS UNDELETE();
+
+DROP TRIGGER IF EXISTS PREVENT_INVALID_ARRIVAL_TIME ON journal;
+DROP FUNCTION IF EXISTS CHECK_ARRIVAL_TIME();
+
+DROP TRIGGER IF EXISTS PREVENT_AUTO_SENDING ON JOURNAL;
+DROP FUNCTION IF EXISTS TIME_IN_CHECK();
+
+DROP TRIGGER IF EXISTS prevent_driver_double_booking ON journal;
+DROP FUNCTION IF EXISTS check_driver_availability();
+
+DROP VIEW IF EXISTS journal_view;
+
+DROP TABLE IF EXISTS journal;
+DROP TABLE IF EXISTS routes;
+DROP TABLE IF EXISTS auto;
+DROP TABLE IF EXISTS auto_personal;
+DROP TABLE IF EXISTS users;
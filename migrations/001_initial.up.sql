@@ -1,5 +1,5 @@
 --таблица пользователей
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     username VARCHAR(50) NOT NULL UNIQUE,
     password_hash TEXT NOT NULL,
@@ -86,6 +86,7 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS prevent_driver_double_booking ON journal;
 CREATE TRIGGER prevent_driver_double_booking
     BEFORE INSERT ON journal
     FOR EACH ROW
@@ -109,6 +110,7 @@ END;
 $$
     LANGUAGE PLPGSQL;
 
+DROP TRIGGER IF EXISTS PREVENT_AUTO_SENDING ON JOURNAL;
 CREATE TRIGGER PREVENT_AUTO_SENDING
     BEFORE INSERT ON JOURNAL
     FOR EACH ROW
@@ -126,6 +128,7 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS PREVENT_INVALID_ARRIVAL_TIME ON journal;
 CREATE TRIGGER PREVENT_INVALID_ARRIVAL_TIME
     BEFORE INSERT OR UPDATE ON journal
     FOR EACH ROW
@@ -148,6 +151,7 @@ END;
 $$
     LANGUAGE PLPGSQL;
 
+DROP TRIGGER IF EXISTS TRIGGER_UNDELETE ON AUTO_PERSONAL;
 CREATE TRIGGER TRIGGER_UNDELETE
     BEFORE DELETE ON AUTO_PERSONAL
     FOR EACH ROW
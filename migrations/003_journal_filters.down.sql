@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS idx_journal_route_id;
+DROP INDEX IF EXISTS idx_journal_auto_id;
+DROP INDEX IF EXISTS idx_journal_time_out;
+
+DROP VIEW IF EXISTS journal_view;
+CREATE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON a.personal_id = p.id;
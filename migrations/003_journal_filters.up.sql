@@ -0,0 +1,22 @@
+-- Идентификаторы в представлении журнала для фильтрации по водителю, автомобилю и маршруту
+CREATE OR REPLACE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name,
+    j.auto_id,
+    j.route_id,
+    a.personal_id AS driver_id
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON a.personal_id = p.id;
+
+CREATE INDEX IF NOT EXISTS idx_journal_time_out ON journal (time_out);
+CREATE INDEX IF NOT EXISTS idx_journal_auto_id ON journal (auto_id);
+CREATE INDEX IF NOT EXISTS idx_journal_route_id ON journal (route_id);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS AUDIT_JOURNAL ON journal;
+DROP TRIGGER IF EXISTS AUDIT_ROUTES ON routes;
+DROP TRIGGER IF EXISTS AUDIT_AUTO ON auto;
+DROP TRIGGER IF EXISTS AUDIT_AUTO_PERSONAL ON auto_personal;
+DROP FUNCTION IF EXISTS AUDIT_CHANGES();
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS AUDIT_LOG_APPEND_ONLY();
@@ -0,0 +1,82 @@
+-- Журнал аудита: кто, когда и что изменил в справочниках и журнале рейсов.
+-- Записи добавляет триггер в той же транзакции, что и само изменение.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER,
+    username VARCHAR(50),
+    action VARCHAR(10) CHECK (action IN ('create', 'update', 'delete')) NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    before_data JSONB,
+    after_data JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+-- Триггер: запись изменения строки в журнал аудита.
+-- Пользователь берется из параметров транзакции app.user_id и app.username,
+-- которые приложение устанавливает через set_config(..., true).
+CREATE OR REPLACE FUNCTION AUDIT_CHANGES()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    actor_id INT := NULLIF(current_setting('app.user_id', true), '')::INT;
+    actor_name TEXT := NULLIF(current_setting('app.username', true), '');
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO audit_log (user_id, username, action, entity_type, entity_id, after_data)
+        VALUES (actor_id, actor_name, 'create', TG_ARGV[0], NEW.id, to_jsonb(NEW));
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO audit_log (user_id, username, action, entity_type, entity_id, before_data, after_data)
+        VALUES (actor_id, actor_name, 'update', TG_ARGV[0], NEW.id, to_jsonb(OLD), to_jsonb(NEW));
+    ELSE
+        INSERT INTO audit_log (user_id, username, action, entity_type, entity_id, before_data)
+        VALUES (actor_id, actor_name, 'delete', TG_ARGV[0], OLD.id, to_jsonb(OLD));
+    END IF;
+    RETURN NULL;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS AUDIT_AUTO_PERSONAL ON auto_personal;
+CREATE TRIGGER AUDIT_AUTO_PERSONAL
+    AFTER INSERT OR UPDATE OR DELETE ON auto_personal
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('driver');
+
+DROP TRIGGER IF EXISTS AUDIT_AUTO ON auto;
+CREATE TRIGGER AUDIT_AUTO
+    AFTER INSERT OR UPDATE OR DELETE ON auto
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('auto');
+
+DROP TRIGGER IF EXISTS AUDIT_ROUTES ON routes;
+CREATE TRIGGER AUDIT_ROUTES
+    AFTER INSERT OR UPDATE OR DELETE ON routes
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('route');
+
+DROP TRIGGER IF EXISTS AUDIT_JOURNAL ON journal;
+CREATE TRIGGER AUDIT_JOURNAL
+    AFTER INSERT OR UPDATE OR DELETE ON journal
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('journal');
+
+-- Триггер: журнал аудита только дополняется
+CREATE OR REPLACE FUNCTION AUDIT_LOG_APPEND_ONLY()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'Записи журнала аудита нельзя изменять или удалять';
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS PREVENT_AUDIT_LOG_CHANGES ON audit_log;
+CREATE TRIGGER PREVENT_AUDIT_LOG_CHANGES
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_LOG_APPEND_ONLY();
@@ -0,0 +1,20 @@
+DROP TRIGGER IF EXISTS PREVENT_ARCHIVED_DRIVER ON auto;
+DROP FUNCTION IF EXISTS CHECK_AUTO_DRIVER_NOT_ARCHIVED();
+DROP TRIGGER IF EXISTS PREVENT_ARCHIVED_JOURNAL ON journal;
+DROP FUNCTION IF EXISTS CHECK_JOURNAL_NOT_ARCHIVED();
+
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS fk_journal_auto;
+ALTER TABLE journal ADD CONSTRAINT fk_journal_auto
+    FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE CASCADE;
+
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS fk_journal_routes;
+ALTER TABLE journal ADD CONSTRAINT fk_journal_routes
+    FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE CASCADE;
+
+ALTER TABLE auto DROP CONSTRAINT IF EXISTS fk_auto_personal;
+ALTER TABLE auto ADD CONSTRAINT fk_auto_personal
+    FOREIGN KEY (personal_id) REFERENCES auto_personal(id) ON DELETE CASCADE;
+
+ALTER TABLE routes DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE auto DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE auto_personal DROP COLUMN IF EXISTS archived_at;
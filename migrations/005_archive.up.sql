@@ -0,0 +1,60 @@
+-- Архивирование водителей, автомобилей и маршрутов вместо удаления
+ALTER TABLE auto_personal ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE auto ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+-- История рейсов больше не удаляется каскадно вместе со справочниками
+ALTER TABLE auto DROP CONSTRAINT IF EXISTS fk_auto_personal;
+ALTER TABLE auto ADD CONSTRAINT fk_auto_personal
+    FOREIGN KEY (personal_id) REFERENCES auto_personal(id) ON DELETE RESTRICT;
+
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS fk_journal_routes;
+ALTER TABLE journal ADD CONSTRAINT fk_journal_routes
+    FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE RESTRICT;
+
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS fk_journal_auto;
+ALTER TABLE journal ADD CONSTRAINT fk_journal_auto
+    FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE RESTRICT;
+
+-- Триггер: в рейс нельзя отправить архивный автомобиль или по архивному маршруту
+CREATE OR REPLACE FUNCTION CHECK_JOURNAL_NOT_ARCHIVED()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF EXISTS (SELECT 1 FROM auto WHERE id = NEW.auto_id AND archived_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'Автомобиль % находится в архиве', NEW.auto_id;
+    END IF;
+    IF EXISTS (SELECT 1 FROM routes WHERE id = NEW.route_id AND archived_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'Маршрут % находится в архиве', NEW.route_id;
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS PREVENT_ARCHIVED_JOURNAL ON journal;
+CREATE TRIGGER PREVENT_ARCHIVED_JOURNAL
+    BEFORE INSERT ON journal
+    FOR EACH ROW
+EXECUTE FUNCTION CHECK_JOURNAL_NOT_ARCHIVED();
+
+-- Триггер: автомобиль нельзя закрепить за архивным водителем
+CREATE OR REPLACE FUNCTION CHECK_AUTO_DRIVER_NOT_ARCHIVED()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.archived_at IS NULL AND EXISTS (
+        SELECT 1 FROM auto_personal WHERE id = NEW.personal_id AND archived_at IS NOT NULL
+    ) THEN
+        RAISE EXCEPTION 'Водитель с ID % находится в архиве', NEW.personal_id;
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS PREVENT_ARCHIVED_DRIVER ON auto;
+CREATE TRIGGER PREVENT_ARCHIVED_DRIVER
+    BEFORE INSERT OR UPDATE ON auto
+    FOR EACH ROW
+EXECUTE FUNCTION CHECK_AUTO_DRIVER_NOT_ARCHIVED();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS maintenance_schedules;
+DROP TABLE IF EXISTS maintenance;
@@ -0,0 +1,43 @@
+-- Записи о техническом обслуживании и ремонте автомобилей
+CREATE TABLE IF NOT EXISTS maintenance (
+    id SERIAL PRIMARY KEY,
+    auto_id INTEGER NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    performed_at DATE NOT NULL,
+    odometer INTEGER CHECK (odometer >= 0),
+    cost NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (cost >= 0),
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_maintenance_auto FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_auto_type ON maintenance (auto_id, type, performed_at);
+
+DROP TRIGGER IF EXISTS AUDIT_MAINTENANCE ON maintenance;
+CREATE TRIGGER AUDIT_MAINTENANCE
+    AFTER INSERT OR UPDATE OR DELETE ON maintenance
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('maintenance');
+
+-- Регламент обслуживания: периодичность по времени и/или по пробегу
+CREATE TABLE IF NOT EXISTS maintenance_schedules (
+    id SERIAL PRIMARY KEY,
+    auto_id INTEGER NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    interval_days INTEGER CHECK (interval_days > 0),
+    interval_km INTEGER CHECK (interval_km > 0),
+    mandatory BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Пробег автомобиля на момент создания регламента: от него отсчитывается
+    -- первое обслуживание по пробегу, пока работ этого вида еще не было
+    start_odometer INTEGER CHECK (start_odometer >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_maintenance_schedules_auto FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE RESTRICT,
+    CONSTRAINT maintenance_schedules_interval CHECK (interval_days IS NOT NULL OR interval_km IS NOT NULL),
+    CONSTRAINT maintenance_schedules_auto_type UNIQUE (auto_id, type)
+);
+
+DROP TRIGGER IF EXISTS AUDIT_MAINTENANCE_SCHEDULES ON maintenance_schedules;
+CREATE TRIGGER AUDIT_MAINTENANCE_SCHEDULES
+    AFTER INSERT OR UPDATE OR DELETE ON maintenance_schedules
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('maintenance_schedule');
@@ -0,0 +1,28 @@
+DROP VIEW IF EXISTS journal_view;
+CREATE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name,
+    j.auto_id,
+    j.route_id,
+    a.personal_id AS driver_id
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON a.personal_id = p.id;
+
+DROP TRIGGER IF EXISTS PREVENT_ODOMETER_ROLLBACK ON journal;
+DROP FUNCTION IF EXISTS CHECK_ODOMETER_MONOTONIC();
+
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS journal_odometer_order;
+ALTER TABLE journal DROP COLUMN IF EXISTS fuel_added;
+ALTER TABLE journal DROP COLUMN IF EXISTS fuel_in;
+ALTER TABLE journal DROP COLUMN IF EXISTS fuel_out;
+ALTER TABLE journal DROP COLUMN IF EXISTS odometer_in;
+ALTER TABLE journal DROP COLUMN IF EXISTS odometer_out;
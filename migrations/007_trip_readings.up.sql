@@ -0,0 +1,72 @@
+-- Показания одометра и топлива при отправлении и возвращении из рейса.
+-- fuel_out и fuel_in — остаток в баке, fuel_added — заправлено в рейсе.
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS odometer_out INTEGER CHECK (odometer_out >= 0);
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS odometer_in INTEGER CHECK (odometer_in >= 0);
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS fuel_out NUMERIC(8, 2) CHECK (fuel_out >= 0);
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS fuel_in NUMERIC(8, 2) CHECK (fuel_in >= 0);
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS fuel_added NUMERIC(8, 2) CHECK (fuel_added >= 0);
+
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS journal_odometer_order;
+ALTER TABLE journal ADD CONSTRAINT journal_odometer_order CHECK (odometer_in >= odometer_out);
+
+-- Триггер: показания одометра автомобиля не убывают от рейса к рейсу
+CREATE OR REPLACE FUNCTION CHECK_ODOMETER_MONOTONIC()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    prev_reading INT;
+    next_reading INT;
+BEGIN
+    IF NEW.odometer_out IS NULL AND NEW.odometer_in IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT MAX(GREATEST(odometer_out, odometer_in)) INTO prev_reading
+    FROM journal
+    WHERE auto_id = NEW.auto_id AND id <> NEW.id AND time_out < NEW.time_out;
+
+    SELECT MIN(LEAST(odometer_out, odometer_in)) INTO next_reading
+    FROM journal
+    WHERE auto_id = NEW.auto_id AND id <> NEW.id AND time_out > NEW.time_out;
+
+    IF prev_reading IS NOT NULL AND LEAST(NEW.odometer_out, NEW.odometer_in) < prev_reading THEN
+        RAISE EXCEPTION 'Показания одометра % меньше показаний предыдущего рейса автомобиля: %',
+            LEAST(NEW.odometer_out, NEW.odometer_in), prev_reading;
+    END IF;
+    IF next_reading IS NOT NULL AND GREATEST(NEW.odometer_out, NEW.odometer_in) > next_reading THEN
+        RAISE EXCEPTION 'Показания одометра % больше показаний следующего рейса автомобиля: %',
+            GREATEST(NEW.odometer_out, NEW.odometer_in), next_reading;
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS PREVENT_ODOMETER_ROLLBACK ON journal;
+CREATE TRIGGER PREVENT_ODOMETER_ROLLBACK
+    BEFORE INSERT OR UPDATE OF odometer_out, odometer_in, time_out ON journal
+    FOR EACH ROW
+EXECUTE FUNCTION CHECK_ODOMETER_MONOTONIC();
+
+CREATE OR REPLACE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name,
+    j.auto_id,
+    j.route_id,
+    a.personal_id AS driver_id,
+    j.odometer_out,
+    j.odometer_in,
+    j.fuel_out::FLOAT8 AS fuel_out,
+    j.fuel_in::FLOAT8 AS fuel_in,
+    j.fuel_added::FLOAT8 AS fuel_added
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON a.personal_id = p.id;
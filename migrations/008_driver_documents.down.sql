@@ -0,0 +1,11 @@
+ALTER TABLE auto DROP COLUMN IF EXISTS category;
+
+DROP INDEX IF EXISTS idx_auto_personal_license_number;
+ALTER TABLE auto_personal DROP CONSTRAINT IF EXISTS auto_personal_license_dates;
+
+ALTER TABLE auto_personal DROP COLUMN IF EXISTS medical_expires_at;
+ALTER TABLE auto_personal DROP COLUMN IF EXISTS license_expires_at;
+ALTER TABLE auto_personal DROP COLUMN IF EXISTS license_issued_at;
+ALTER TABLE auto_personal DROP COLUMN IF EXISTS license_categories;
+ALTER TABLE auto_personal DROP COLUMN IF EXISTS license_number;
+ALTER TABLE auto_personal DROP COLUMN IF EXISTS phone;
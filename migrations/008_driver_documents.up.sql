@@ -0,0 +1,17 @@
+-- Документы водителя: телефон, водительское удостоверение и медицинская справка
+ALTER TABLE auto_personal ADD COLUMN IF NOT EXISTS phone VARCHAR(20);
+ALTER TABLE auto_personal ADD COLUMN IF NOT EXISTS license_number VARCHAR(20);
+ALTER TABLE auto_personal ADD COLUMN IF NOT EXISTS license_categories TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE auto_personal ADD COLUMN IF NOT EXISTS license_issued_at DATE;
+ALTER TABLE auto_personal ADD COLUMN IF NOT EXISTS license_expires_at DATE;
+ALTER TABLE auto_personal ADD COLUMN IF NOT EXISTS medical_expires_at DATE;
+
+ALTER TABLE auto_personal DROP CONSTRAINT IF EXISTS auto_personal_license_dates;
+ALTER TABLE auto_personal ADD CONSTRAINT auto_personal_license_dates
+    CHECK (license_issued_at IS NULL OR license_expires_at IS NULL OR license_issued_at < license_expires_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auto_personal_license_number
+    ON auto_personal (license_number) WHERE license_number IS NOT NULL;
+
+-- Категория прав, необходимая для управления автомобилем
+ALTER TABLE auto ADD COLUMN IF NOT EXISTS category VARCHAR(3) NOT NULL DEFAULT 'B';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auto_document_files;
+DROP TABLE IF EXISTS auto_documents;
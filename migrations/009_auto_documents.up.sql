@@ -0,0 +1,41 @@
+-- Документы автомобиля: страховой полис, свидетельство о регистрации, техосмотр
+CREATE TABLE IF NOT EXISTS auto_documents (
+    id SERIAL PRIMARY KEY,
+    auto_id INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('insurance', 'registration', 'inspection')),
+    number VARCHAR(50) NOT NULL DEFAULT '',
+    issued_at DATE,
+    expires_at DATE,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_auto_documents_auto FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE RESTRICT,
+    CONSTRAINT auto_documents_dates CHECK (issued_at IS NULL OR expires_at IS NULL OR issued_at < expires_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_documents_auto_type ON auto_documents (auto_id, type, expires_at);
+
+DROP TRIGGER IF EXISTS AUDIT_AUTO_DOCUMENTS ON auto_documents;
+CREATE TRIGGER AUDIT_AUTO_DOCUMENTS
+    AFTER INSERT OR UPDATE OR DELETE ON auto_documents
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('auto_document');
+
+-- Сканы документов; сами файлы хранятся на диске под именем stored_name
+CREATE TABLE IF NOT EXISTS auto_document_files (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    stored_name VARCHAR(100) NOT NULL UNIQUE,
+    uploaded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_auto_document_files_document FOREIGN KEY (document_id) REFERENCES auto_documents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_document_files_document ON auto_document_files (document_id);
+
+DROP TRIGGER IF EXISTS AUDIT_AUTO_DOCUMENT_FILES ON auto_document_files;
+CREATE TRIGGER AUDIT_AUTO_DOCUMENT_FILES
+    AFTER INSERT OR UPDATE OR DELETE ON auto_document_files
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('auto_document_file');
@@ -0,0 +1,75 @@
+CREATE OR REPLACE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name,
+    j.auto_id,
+    j.route_id,
+    a.personal_id AS driver_id,
+    j.odometer_out,
+    j.odometer_in,
+    j.fuel_out::FLOAT8 AS fuel_out,
+    j.fuel_in::FLOAT8 AS fuel_in,
+    j.fuel_added::FLOAT8 AS fuel_added
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON a.personal_id = p.id;
+
+DROP TRIGGER IF EXISTS PREVENT_UNASSIGNED_DRIVER ON journal;
+DROP FUNCTION IF EXISTS CHECK_JOURNAL_ASSIGNMENT();
+
+CREATE OR REPLACE FUNCTION CHECK_JOURNAL_NOT_ARCHIVED()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF EXISTS (SELECT 1 FROM auto WHERE id = NEW.auto_id AND archived_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'Автомобиль % находится в архиве', NEW.auto_id;
+    END IF;
+    IF EXISTS (SELECT 1 FROM routes WHERE id = NEW.route_id AND archived_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'Маршрут % находится в архиве', NEW.route_id;
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+CREATE OR REPLACE FUNCTION check_driver_availability()
+    RETURNS TRIGGER AS $$
+DECLARE
+    active_count INT;
+    driver_id INT;
+BEGIN
+    SELECT personal_id INTO driver_id
+    FROM auto
+    WHERE id = NEW.auto_id;
+
+    SELECT COUNT(*) INTO active_count
+    FROM journal j
+    WHERE j.auto_id IN (
+        SELECT id FROM auto WHERE personal_id = driver_id
+    )
+      AND j.time_in IS NULL;
+
+    IF active_count > 0 THEN
+        RAISE EXCEPTION 'Водитель с ID % не может быть отправлен в рейс, пока не вернется с предыдущего маршрута.', driver_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_journal_personal_id;
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS fk_journal_personal;
+ALTER TABLE journal DROP COLUMN IF EXISTS personal_id;
+
+DROP TRIGGER IF EXISTS ASSIGN_AUTO_PRIMARY_DRIVER ON auto;
+DROP FUNCTION IF EXISTS ASSIGN_PRIMARY_DRIVER();
+
+DROP TABLE IF EXISTS auto_assignments;
+DROP FUNCTION IF EXISTS CHECK_AUTO_ASSIGNMENT();
@@ -0,0 +1,202 @@
+-- Закрепление водителей за автомобилями с периодом действия. Водитель может
+-- работать на нескольких автомобилях, а автомобиль — с несколькими водителями.
+-- valid_to — последний день действия включительно, NULL — бессрочно.
+CREATE TABLE IF NOT EXISTS auto_assignments (
+    id SERIAL PRIMARY KEY,
+    auto_id INTEGER NOT NULL,
+    personal_id INTEGER NOT NULL,
+    valid_from DATE NOT NULL DEFAULT CURRENT_DATE,
+    valid_to DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_auto_assignments_auto FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_auto_assignments_personal FOREIGN KEY (personal_id) REFERENCES auto_personal(id) ON DELETE RESTRICT,
+    CONSTRAINT auto_assignments_period CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_assignments_auto ON auto_assignments (auto_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_auto_assignments_personal ON auto_assignments (personal_id, valid_from);
+
+-- Текущие закрепления переносятся из auto.personal_id с даты первого рейса автомобиля
+INSERT INTO auto_assignments (auto_id, personal_id, valid_from)
+SELECT a.id, a.personal_id, COALESCE((SELECT MIN(j.time_out)::DATE FROM journal j WHERE j.auto_id = a.id), CURRENT_DATE)
+FROM auto a;
+
+-- Триггер: периоды закрепления одного водителя за одним автомобилем не пересекаются,
+-- новое закрепление нельзя создать для архивного водителя или автомобиля
+CREATE OR REPLACE FUNCTION CHECK_AUTO_ASSIGNMENT()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM auto_assignments
+        WHERE auto_id = NEW.auto_id
+          AND personal_id = NEW.personal_id
+          AND id <> NEW.id
+          AND valid_from <= COALESCE(NEW.valid_to, 'infinity'::DATE)
+          AND NEW.valid_from <= COALESCE(valid_to, 'infinity'::DATE)
+    ) THEN
+        RAISE EXCEPTION 'Водитель с ID % уже закреплен за автомобилем % в этот период', NEW.personal_id, NEW.auto_id;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        IF EXISTS (SELECT 1 FROM auto_personal WHERE id = NEW.personal_id AND archived_at IS NOT NULL) THEN
+            RAISE EXCEPTION 'Водитель с ID % находится в архиве', NEW.personal_id;
+        END IF;
+        IF EXISTS (SELECT 1 FROM auto WHERE id = NEW.auto_id AND archived_at IS NOT NULL) THEN
+            RAISE EXCEPTION 'Автомобиль % находится в архиве', NEW.auto_id;
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS PREVENT_ASSIGNMENT_OVERLAP ON auto_assignments;
+CREATE TRIGGER PREVENT_ASSIGNMENT_OVERLAP
+    BEFORE INSERT OR UPDATE ON auto_assignments
+    FOR EACH ROW
+EXECUTE FUNCTION CHECK_AUTO_ASSIGNMENT();
+
+DROP TRIGGER IF EXISTS AUDIT_AUTO_ASSIGNMENTS ON auto_assignments;
+CREATE TRIGGER AUDIT_AUTO_ASSIGNMENTS
+    AFTER INSERT OR UPDATE OR DELETE ON auto_assignments
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('assignment');
+
+-- Триггер: основной водитель автомобиля (auto.personal_id) закрепляется за ним
+-- с текущей даты, если у него еще нет действующего или будущего закрепления.
+-- При смене основного водителя действующее закрепление прежнего завершается
+-- текущей датой: рейсы, начатые им сегодня, остаются в периоде закрепления.
+CREATE OR REPLACE FUNCTION ASSIGN_PRIMARY_DRIVER()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.personal_id IS DISTINCT FROM NEW.personal_id THEN
+        UPDATE auto_assignments
+        SET valid_to = CURRENT_DATE
+        WHERE auto_id = NEW.id
+          AND personal_id = OLD.personal_id
+          AND valid_from <= CURRENT_DATE
+          AND (valid_to IS NULL OR valid_to > CURRENT_DATE);
+    END IF;
+
+    IF NEW.archived_at IS NULL AND NOT EXISTS (
+        SELECT 1
+        FROM auto_assignments
+        WHERE auto_id = NEW.id
+          AND personal_id = NEW.personal_id
+          AND (valid_to IS NULL OR valid_to >= CURRENT_DATE)
+    ) THEN
+        INSERT INTO auto_assignments (auto_id, personal_id, valid_from)
+        VALUES (NEW.id, NEW.personal_id, CURRENT_DATE);
+    END IF;
+    RETURN NULL;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS ASSIGN_AUTO_PRIMARY_DRIVER ON auto;
+CREATE TRIGGER ASSIGN_AUTO_PRIMARY_DRIVER
+    AFTER INSERT OR UPDATE OF personal_id ON auto
+    FOR EACH ROW
+EXECUTE FUNCTION ASSIGN_PRIMARY_DRIVER();
+
+-- Фактический водитель рейса; для прошлых рейсов — основной водитель автомобиля
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS personal_id INTEGER;
+UPDATE journal j SET personal_id = a.personal_id FROM auto a WHERE a.id = j.auto_id AND j.personal_id IS NULL;
+ALTER TABLE journal ALTER COLUMN personal_id SET NOT NULL;
+
+ALTER TABLE journal DROP CONSTRAINT IF EXISTS fk_journal_personal;
+ALTER TABLE journal ADD CONSTRAINT fk_journal_personal
+    FOREIGN KEY (personal_id) REFERENCES auto_personal(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_journal_personal_id ON journal (personal_id);
+
+-- Триггер: запрет отправки в рейс водителя, который еще не вернулся,
+-- теперь по фактическому водителю рейса, а не по закрепленному за автомобилем
+CREATE OR REPLACE FUNCTION check_driver_availability()
+    RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM journal j
+        WHERE j.personal_id = NEW.personal_id
+          AND j.time_in IS NULL
+    ) THEN
+        RAISE EXCEPTION 'Водитель с ID % не может быть отправлен в рейс, пока не вернется с предыдущего маршрута.', NEW.personal_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Триггер: в рейс нельзя отправить архивный автомобиль, архивного водителя
+-- или по архивному маршруту
+CREATE OR REPLACE FUNCTION CHECK_JOURNAL_NOT_ARCHIVED()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF EXISTS (SELECT 1 FROM auto WHERE id = NEW.auto_id AND archived_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'Автомобиль % находится в архиве', NEW.auto_id;
+    END IF;
+    IF EXISTS (SELECT 1 FROM auto_personal WHERE id = NEW.personal_id AND archived_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'Водитель с ID % находится в архиве', NEW.personal_id;
+    END IF;
+    IF EXISTS (SELECT 1 FROM routes WHERE id = NEW.route_id AND archived_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'Маршрут % находится в архиве', NEW.route_id;
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+-- Триггер: водитель рейса должен быть закреплен за автомобилем на дату отправления
+CREATE OR REPLACE FUNCTION CHECK_JOURNAL_ASSIGNMENT()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM auto_assignments
+        WHERE auto_id = NEW.auto_id
+          AND personal_id = NEW.personal_id
+          AND valid_from <= NEW.time_out::DATE
+          AND (valid_to IS NULL OR valid_to >= NEW.time_out::DATE)
+    ) THEN
+        RAISE EXCEPTION 'Водитель с ID % не закреплен за автомобилем % на %',
+            NEW.personal_id, NEW.auto_id, to_char(NEW.time_out, 'DD.MM.YYYY');
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE PLPGSQL;
+
+DROP TRIGGER IF EXISTS PREVENT_UNASSIGNED_DRIVER ON journal;
+CREATE TRIGGER PREVENT_UNASSIGNED_DRIVER
+    BEFORE INSERT ON journal
+    FOR EACH ROW
+EXECUTE FUNCTION CHECK_JOURNAL_ASSIGNMENT();
+
+CREATE OR REPLACE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name,
+    j.auto_id,
+    j.route_id,
+    j.personal_id AS driver_id,
+    j.odometer_out,
+    j.odometer_in,
+    j.fuel_out::FLOAT8 AS fuel_out,
+    j.fuel_in::FLOAT8 AS fuel_in,
+    j.fuel_added::FLOAT8 AS fuel_added
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON j.personal_id = p.id;
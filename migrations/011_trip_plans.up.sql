@@ -0,0 +1,26 @@
+-- Планирование рейсов: водитель, автомобиль, маршрут и планируемое время.
+-- При отправке в рейс план связывается с созданной записью журнала.
+CREATE TABLE IF NOT EXISTS trip_plans (
+    id SERIAL PRIMARY KEY,
+    auto_id INTEGER NOT NULL,
+    personal_id INTEGER NOT NULL,
+    route_id INTEGER NOT NULL,
+    planned_out TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    planned_in TIMESTAMP WITHOUT TIME ZONE,
+    notes TEXT NOT NULL DEFAULT '',
+    journal_id INTEGER UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_trip_plans_auto FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_trip_plans_personal FOREIGN KEY (personal_id) REFERENCES auto_personal(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_trip_plans_route FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_trip_plans_journal FOREIGN KEY (journal_id) REFERENCES journal(id) ON DELETE SET NULL,
+    CONSTRAINT trip_plans_period CHECK (planned_in IS NULL OR planned_in > planned_out)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_plans_planned_out ON trip_plans (planned_out);
+
+DROP TRIGGER IF EXISTS AUDIT_TRIP_PLANS ON trip_plans;
+CREATE TRIGGER AUDIT_TRIP_PLANS
+    AFTER INSERT OR UPDATE OR DELETE ON trip_plans
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('trip_plan');
@@ -0,0 +1,6 @@
+ALTER TABLE trip_plans DROP CONSTRAINT IF EXISTS trip_plans_timetable_unique;
+ALTER TABLE trip_plans DROP CONSTRAINT IF EXISTS fk_trip_plans_timetable;
+ALTER TABLE trip_plans DROP COLUMN IF EXISTS timetable_id;
+
+DROP TABLE IF EXISTS timetable_exceptions;
+DROP TABLE IF EXISTS route_timetables;
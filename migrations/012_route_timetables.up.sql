@@ -0,0 +1,56 @@
+-- Расписания маршрутов: рейсы по дням недели (1 — понедельник, 7 — воскресенье)
+-- в заданное время. Планы рейсов формируются по расписанию на несколько дней вперед,
+-- generated_until — последний день, на который они уже сформированы.
+CREATE TABLE IF NOT EXISTS route_timetables (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER NOT NULL,
+    auto_id INTEGER NOT NULL,
+    personal_id INTEGER NOT NULL,
+    departure_time TIME NOT NULL,
+    duration_minutes INTEGER,
+    weekdays INTEGER[] NOT NULL,
+    valid_from DATE NOT NULL DEFAULT CURRENT_DATE,
+    valid_to DATE,
+    generated_until DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_route_timetables_route FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_route_timetables_auto FOREIGN KEY (auto_id) REFERENCES auto(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_route_timetables_personal FOREIGN KEY (personal_id) REFERENCES auto_personal(id) ON DELETE RESTRICT,
+    CONSTRAINT route_timetables_duration CHECK (duration_minutes IS NULL OR duration_minutes BETWEEN 1 AND 1440),
+    CONSTRAINT route_timetables_weekdays CHECK (cardinality(weekdays) > 0 AND weekdays <@ ARRAY[1, 2, 3, 4, 5, 6, 7]),
+    CONSTRAINT route_timetables_period CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_timetables_route ON route_timetables (route_id);
+
+DROP TRIGGER IF EXISTS AUDIT_ROUTE_TIMETABLES ON route_timetables;
+CREATE TRIGGER AUDIT_ROUTE_TIMETABLES
+    AFTER INSERT OR UPDATE OR DELETE ON route_timetables
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('timetable');
+
+-- Дни, в которые рейсы по расписанию не выполняются; timetable_id IS NULL — праздник для всех расписаний
+CREATE TABLE IF NOT EXISTS timetable_exceptions (
+    id SERIAL PRIMARY KEY,
+    timetable_id INTEGER,
+    exception_date DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    CONSTRAINT fk_timetable_exceptions_timetable FOREIGN KEY (timetable_id) REFERENCES route_timetables(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_timetable_exceptions_unique
+    ON timetable_exceptions (COALESCE(timetable_id, 0), exception_date);
+
+DROP TRIGGER IF EXISTS AUDIT_TIMETABLE_EXCEPTIONS ON timetable_exceptions;
+CREATE TRIGGER AUDIT_TIMETABLE_EXCEPTIONS
+    AFTER INSERT OR UPDATE OR DELETE ON timetable_exceptions
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('timetable_exception');
+
+-- Планы, сформированные по расписанию; повторное формирование не создает дубликатов
+ALTER TABLE trip_plans ADD COLUMN IF NOT EXISTS timetable_id INTEGER;
+ALTER TABLE trip_plans DROP CONSTRAINT IF EXISTS fk_trip_plans_timetable;
+ALTER TABLE trip_plans ADD CONSTRAINT fk_trip_plans_timetable
+    FOREIGN KEY (timetable_id) REFERENCES route_timetables(id) ON DELETE SET NULL;
+ALTER TABLE trip_plans DROP CONSTRAINT IF EXISTS trip_plans_timetable_unique;
+ALTER TABLE trip_plans ADD CONSTRAINT trip_plans_timetable_unique UNIQUE (timetable_id, planned_out);
@@ -0,0 +1,36 @@
+DROP VIEW IF EXISTS journal_view;
+CREATE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name,
+    j.auto_id,
+    j.route_id,
+    j.personal_id AS driver_id,
+    j.odometer_out,
+    j.odometer_in,
+    j.fuel_out::FLOAT8 AS fuel_out,
+    j.fuel_in::FLOAT8 AS fuel_in,
+    j.fuel_added::FLOAT8 AS fuel_added
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON j.personal_id = p.id;
+
+DROP TABLE IF EXISTS route_stops;
+
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_end_coords;
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_start_coords;
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_planned_minutes_positive;
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_distance_positive;
+ALTER TABLE routes DROP COLUMN IF EXISTS end_lon;
+ALTER TABLE routes DROP COLUMN IF EXISTS end_lat;
+ALTER TABLE routes DROP COLUMN IF EXISTS start_lon;
+ALTER TABLE routes DROP COLUMN IF EXISTS start_lat;
+ALTER TABLE routes DROP COLUMN IF EXISTS planned_minutes;
+ALTER TABLE routes DROP COLUMN IF EXISTS distance_km;
@@ -0,0 +1,69 @@
+-- Протяженность, плановая длительность и координаты маршрута
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS distance_km NUMERIC(8, 1);
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS planned_minutes INTEGER;
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS start_lat DOUBLE PRECISION;
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS start_lon DOUBLE PRECISION;
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS end_lat DOUBLE PRECISION;
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS end_lon DOUBLE PRECISION;
+
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_distance_positive;
+ALTER TABLE routes ADD CONSTRAINT routes_distance_positive CHECK (distance_km IS NULL OR distance_km > 0);
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_planned_minutes_positive;
+ALTER TABLE routes ADD CONSTRAINT routes_planned_minutes_positive CHECK (planned_minutes IS NULL OR planned_minutes > 0);
+-- Координаты точки задаются обе или ни одной
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_start_coords;
+ALTER TABLE routes ADD CONSTRAINT routes_start_coords CHECK (
+    (start_lat IS NULL) = (start_lon IS NULL)
+    AND (start_lat IS NULL OR (start_lat BETWEEN -90 AND 90 AND start_lon BETWEEN -180 AND 180)));
+ALTER TABLE routes DROP CONSTRAINT IF EXISTS routes_end_coords;
+ALTER TABLE routes ADD CONSTRAINT routes_end_coords CHECK (
+    (end_lat IS NULL) = (end_lon IS NULL)
+    AND (end_lat IS NULL OR (end_lat BETWEEN -90 AND 90 AND end_lon BETWEEN -180 AND 180)));
+
+-- Промежуточные остановки маршрута в порядке следования
+CREATE TABLE IF NOT EXISTS route_stops (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    lat DOUBLE PRECISION,
+    lon DOUBLE PRECISION,
+    CONSTRAINT fk_route_stops_route FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE CASCADE,
+    CONSTRAINT route_stops_position_unique UNIQUE (route_id, position),
+    CONSTRAINT route_stops_position_positive CHECK (position > 0),
+    CONSTRAINT route_stops_coords CHECK (
+        (lat IS NULL) = (lon IS NULL)
+        AND (lat IS NULL OR (lat BETWEEN -90 AND 90 AND lon BETWEEN -180 AND 180)))
+);
+
+DROP TRIGGER IF EXISTS AUDIT_ROUTE_STOPS ON route_stops;
+CREATE TRIGGER AUDIT_ROUTE_STOPS
+    AFTER INSERT OR UPDATE OR DELETE ON route_stops
+    FOR EACH ROW
+EXECUTE FUNCTION AUDIT_CHANGES('route_stop');
+
+-- Плановые показатели маршрута в журнале для сравнения с фактическими
+CREATE OR REPLACE VIEW journal_view AS
+SELECT
+    j.id AS journal_id,
+    j.time_out,
+    j.time_in,
+    r.start_point,
+    r.end_point,
+    a.num AS auto_number,
+    a.mark AS auto_mark,
+    p.first_name || ' ' || p.last_name AS driver_name,
+    j.auto_id,
+    j.route_id,
+    j.personal_id AS driver_id,
+    j.odometer_out,
+    j.odometer_in,
+    j.fuel_out::FLOAT8 AS fuel_out,
+    j.fuel_in::FLOAT8 AS fuel_in,
+    j.fuel_added::FLOAT8 AS fuel_added,
+    r.distance_km::FLOAT8 AS planned_distance_km,
+    r.planned_minutes
+FROM journal j
+         INNER JOIN routes r ON j.route_id = r.id
+         INNER JOIN auto a ON j.auto_id = a.id
+         INNER JOIN auto_personal p ON j.personal_id = p.id;
@@ -0,0 +1,8 @@
+// Пакет встраивает SQL-миграции в бинарный файл приложения.
+// Файлы именуются как NNN_название.up.sql и NNN_название.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
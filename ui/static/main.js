@@ -22,7 +22,7 @@ deleteForms.forEach(input => {
         const urlParts = new URL(fullUrl).pathname.split('/').filter(part => part);
         const entityType = urlParts[0]; // первый сегмент пути
 
-        const confirmMessage = `Вы уверены, что хотите удалить ${getEntityName(entityType)}?`;
+        const confirmMessage = `Перенести ${getEntityName(entityType)} в архив? Записи журнала сохранятся, восстановить можно на странице «Архив».`;
 
         if (confirm(confirmMessage)) {
             const formData = new FormData(form);
@@ -42,12 +42,12 @@ deleteForms.forEach(input => {
                     }
                 } else {
                     return response.text().then(errorText => {
-                        throw new Error(errorText || 'Ошибка при удалении');
+                        throw new Error(errorText || 'Ошибка при переносе в архив');
                     });
                 }
             }).catch(error => {
                 console.error('Ошибка:', error);
-                alert(error.message || 'Ошибка при удалении');
+                alert(error.message || 'Ошибка при переносе в архив');
             });
         }
     });